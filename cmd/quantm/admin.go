@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+
+	"go.breu.io/quantm/cmd/quantm/config"
+	"go.breu.io/quantm/internal/core/repos/admin"
+	"go.breu.io/quantm/internal/db"
+	"go.breu.io/quantm/internal/durable"
+)
+
+// run_admin runs the admin command on the workflows of an org or a repo.
+//
+//	quantm --admin list --org {org} [--all]
+//	quantm --admin inspect --repo {repo} [--branch {branch} | --trunk]
+//	quantm --admin terminate --repo {repo} [--branch {branch} | --trunk] [--reason {reason}]
+//	quantm --admin restart --repo {repo} [--branch {branch} | --trunk] [--reason {reason}]
+//	quantm --admin sync --repo {repo}
+func run_admin(ctx context.Context, conf *config.Config) error {
+	conf.SetupLogger()
+
+	pg := db.Get(db.WithConfig(conf.DB))
+	if err := pg.Start(ctx); err != nil {
+		return err
+	}
+
+	defer func() { _ = pg.Stop(ctx) }()
+
+	durable.Get(durable.WithConfig(conf.Durable))
+
+	opts := conf.Admin
+
+	if opts.Command == "list" {
+		org, err := uuid.Parse(opts.Org)
+		if err != nil {
+			return fmt.Errorf("invalid --org: %w", err)
+		}
+
+		found, err := admin.List(ctx, org, opts.All)
+		if err != nil {
+			return err
+		}
+
+		return print_workflows(found)
+	}
+
+	repo, err := uuid.Parse(opts.Repo)
+	if err != nil {
+		return fmt.Errorf("invalid --repo: %w", err)
+	}
+
+	kind := admin_kind(opts)
+
+	switch opts.Command {
+	case "inspect":
+		w, err := admin.Find(ctx, repo, kind, opts.Branch)
+		if err != nil {
+			return err
+		}
+
+		state, err := admin.Inspect(ctx, w)
+		if err != nil {
+			return err
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(map[string]any{"workflow": w, "state": state})
+	case "terminate":
+		w, err := admin.Find(ctx, repo, kind, opts.Branch)
+		if err != nil {
+			return err
+		}
+
+		if err := admin.Terminate(ctx, w, opts.Reason); err != nil {
+			return err
+		}
+
+		slog.Info("admin: terminated", "id", w.ID, "run_id", w.RunID)
+	case "restart":
+		run, err := admin.Restart(ctx, repo, kind, opts.Branch, opts.Reason)
+		if err != nil {
+			return err
+		}
+
+		slog.Info("admin: restarted", "repo", repo, "kind", kind, "branch", opts.Branch, "run_id", run)
+	case "sync":
+		if err := admin.Sync(ctx, repo, opts.Reason); err != nil {
+			return err
+		}
+
+		slog.Info("admin: triggers sync requested", "repo", repo)
+	default:
+		return fmt.Errorf("invalid admin command %q, expected one of list, inspect, terminate, restart or sync", opts.Command)
+	}
+
+	return nil
+}
+
+// admin_kind returns the kind of the workflow selected by the options, the repo workflow by default.
+func admin_kind(opts *config.Admin) admin.Kind {
+	switch {
+	case opts.Trunk:
+		return admin.KindTrunk
+	case opts.Branch != "":
+		return admin.KindBranch
+	default:
+		return admin.KindRepo
+	}
+}
+
+// print_workflows writes the workflows as a table to stdout.
+func print_workflows(found []*admin.Workflow) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(w, "KIND\tREPO\tBRANCH\tSTATUS\tSTARTED\tID\tRUN ID")
+
+	for _, wf := range found {
+		_, _ = fmt.Fprintf(
+			w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			wf.Kind, wf.Repo.Name, wf.Branch, wf.Status, wf.Started.Format(time.RFC3339), wf.ID, wf.RunID,
+		)
+	}
+
+	return w.Flush()
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/knadh/koanf/v2"
 	flag "github.com/spf13/pflag"
 
+	"go.breu.io/quantm/cmd/quantm/workers"
 	"go.breu.io/quantm/internal/db"
 	"go.breu.io/quantm/internal/durable"
 	"go.breu.io/quantm/internal/hooks/github"
@@ -27,12 +28,40 @@ type (
 		Nomad   *nomad.Config   `koanf:"NOMAD" json:"nomad"`     // Configuration for Nomad.
 		Github  *github.Config  `koanf:"GITHUB" json:"github"`   // Configuration for the github.
 		Slack   *slack.Config   `koanf:"SLACK" json:"slack"`     // Configuration for the slack.
+		Workers *workers.Config `koanf:"WORKERS" json:"workers"` // Configuration for the queue workers.
 
 		Secret  string `koanf:"SECRET" json:"secret"`   // Secret key for JWE.
 		Debug   bool   `koanf:"DEBUG" json:"debug"`     // Flag to enable debug mode.
 		Migrate bool   `koanf:"MIGRATE" json:"migrate"` // Flag to enable database migration.
 
 		Mode Mode `koanf:"MODE" json:"mode"`
+
+		Replay *Replay `koanf:"-" json:"-"` // Options for the replay mode, set from the command line.
+		Admin  *Admin  `koanf:"-" json:"-"` // Options for the admin mode, set from the command line.
+	}
+
+	// Replay holds the command line options of the replay mode.
+	Replay struct {
+		Repo   string  // Repo is the ID of the repo to replay.
+		From   string  // From is the start of the time range, as RFC3339.
+		To     string  // To is the end of the time range, as RFC3339. Defaults to now.
+		File   string  // File is the NDJSON export to read the events from, instead of clickhouse.
+		DryRun bool    // DryRun logs the events without signaling them.
+		Rate   float64 // Rate is the number of events signaled per second.
+
+		// Include opts in to replaying the pull request and merge queue events, given as their scopes.
+		Include []string
+	}
+
+	// Admin holds the command line options of the admin mode.
+	Admin struct {
+		Command string // Command is one of list, inspect, terminate, restart or sync, given as the first argument.
+		Org     string // Org is the ID of the org to list the workflows of.
+		Repo    string // Repo is the ID of the repo of the workflow, shared with the replay mode.
+		Branch  string // Branch selects the branch workflow of the repo.
+		Trunk   bool   // Trunk selects the trunk workflow of the repo.
+		Reason  string // Reason is recorded on terminate and restart.
+		All     bool   // All lists the closed workflows as well.
 	}
 )
 
@@ -41,6 +70,8 @@ const (
 	ModeWebhook Mode = "webhook"
 	ModeGRPC    Mode = "grpc"
 	ModeWorkers Mode = "queues"
+	ModeReplay  Mode = "replay"
+	ModeAdmin   Mode = "admin"
 	ModeDefault Mode = "default"
 )
 
@@ -51,6 +82,7 @@ func (c *Config) Load() {
 	c.Pulse = &pulse.DefaultConfig
 	c.Github = &github.Config{}
 	c.Slack = &slack.Config{}
+	c.Workers = &workers.DefaultConfig
 
 	k := koanf.New("__")
 
@@ -85,6 +117,8 @@ func (c *Config) Parse() {
 		"webhook": ModeWebhook,
 		"grpc":    ModeGRPC,
 		"queues":  ModeWorkers,
+		"replay":  ModeReplay,
+		"admin":   ModeAdmin,
 	}
 
 	flag.BoolVarP(&help, "help", "h", false, "show help message")
@@ -94,10 +128,33 @@ func (c *Config) Parse() {
 		"webhook": flag.BoolP("webhook", "w", false, "start webhook server"),
 		"grpc":    flag.BoolP("grpc", "g", false, "start gRPC server (nomad)"),
 		"queues":  flag.BoolP("queues", "q", false, "start queues worker"),
+		"replay":  flag.BoolP("replay", "r", false, "replay the stored events of a repo to its workflow"),
+		"admin":   flag.BoolP("admin", "a", false, "list, inspect, terminate, restart or sync the workflows of an org"),
 	}
 
+	c.Replay = &Replay{}
+
+	flag.StringVar(&c.Replay.Repo, "repo", "", "replay, admin: id of the repo")
+	flag.StringVar(&c.Replay.From, "from", "", "replay: start of the time range (RFC3339)")
+	flag.StringVar(&c.Replay.To, "to", "", "replay: end of the time range (RFC3339), defaults to now")
+	flag.StringVar(&c.Replay.File, "file", "", "replay: read the events from an NDJSON export instead of clickhouse")
+	flag.BoolVar(&c.Replay.DryRun, "dry-run", false, "replay: log the events without signaling them")
+	flag.Float64Var(&c.Replay.Rate, "rate", 10, "replay: events signaled per second, 0 for no limit")
+	flag.StringSliceVar(&c.Replay.Include, "include", nil, "replay: also replay the pr and merge_queue events, e.g. --include pr")
+
+	c.Admin = &Admin{}
+
+	flag.StringVar(&c.Admin.Org, "org", "", "admin: id of the org to list the workflows of")
+	flag.StringVar(&c.Admin.Branch, "branch", "", "admin: select the branch workflow of the repo")
+	flag.BoolVar(&c.Admin.Trunk, "trunk", false, "admin: select the trunk workflow of the repo")
+	flag.StringVar(&c.Admin.Reason, "reason", "terminated by admin", "admin: reason recorded on terminate and restart")
+	flag.BoolVar(&c.Admin.All, "all", false, "admin: list the closed workflows as well")
+
 	flag.Parse()
 
+	c.Admin.Command = flag.Arg(0)
+	c.Admin.Repo = c.Replay.Repo
+
 	if help {
 		flag.Usage()
 		os.Exit(0)
@@ -23,16 +23,19 @@ type (
 )
 
 const (
-	ServiceGithub     = "github"
-	ServiceSlack      = "slack"
-	ServiceKernel     = "kernel"
-	ServiceDB         = "db"
-	ServicePulse      = "pulse"
-	ServiceDurable    = "durable"
-	ServiceWebhook    = "webhook"
-	ServiceNomad      = "nomad"
-	ServiceCoreQueue  = "core_queue"
-	ServiceHooksQueue = "hooks_queue"
+	ServiceGithub      = "github"
+	ServiceSlack       = "slack"
+	ServiceKernel      = "kernel"
+	ServiceDB          = "db"
+	ServicePulse       = "pulse"
+	ServicePulseBatch  = "pulse_batch"
+	ServicePulseExport = "pulse_export"
+	ServiceDurable     = "durable"
+	ServiceWebhook     = "webhook"
+	ServiceNomad       = "nomad"
+	ServiceCoreQueue   = "core_queue"
+	ServiceHooksQueue  = "hooks_queue"
+	ServiceGitQueue    = "git_queue"
 )
 
 // Setup configures the application based on the provided config.
@@ -148,6 +151,32 @@ func (c *Config) SetupPulse() error {
 	return nil
 }
 
+// SetupQueues registers the workers of the queues selected by the workers config, and adds the queues to the app.
+func (c *Config) SetupQueues(app *graceful.Graceful) error {
+	if err := c.Workers.Validate(); err != nil {
+		return err
+	}
+
+	deps := []string{ServiceKernel, ServiceDB, ServiceDurable, ServicePulse, ServicePulseBatch, ServicePulseExport}
+
+	if c.Workers.Has(durable.QueueCore) {
+		workers.Core()
+		app.Add(ServiceCoreQueue, durable.OnCore(), deps...)
+	}
+
+	if c.Workers.Has(durable.QueueHooks) {
+		workers.Hooks()
+		app.Add(ServiceHooksQueue, durable.OnHooks(), deps...)
+	}
+
+	if c.Workers.Has(durable.QueueGit) {
+		workers.Git(c.Workers)
+		app.Add(ServiceGitQueue, durable.OnGit(), deps...)
+	}
+
+	return nil
+}
+
 // migrate configures the application for database migrations.
 func (c *Config) migrate(app *graceful.Graceful) error {
 	c.SetupLogger()
@@ -165,7 +194,12 @@ func (c *Config) webhook(app *graceful.Graceful) error {
 		return err
 	}
 
-	app.Add(ServiceWebhook, NewWebhookServer(), ServiceDurable)
+	webhook, err := NewWebhookServer(c.Durable)
+	if err != nil {
+		return err
+	}
+
+	app.Add(ServiceWebhook, webhook, ServiceDurable)
 
 	return nil
 }
@@ -187,13 +221,10 @@ func (c *Config) workers(app *graceful.Graceful) error {
 		return err
 	}
 
-	workers.Core()
-	workers.Hooks()
+	app.Add(ServicePulseBatch, pulse.BatchWriter(), ServicePulse, ServiceDB)
+	app.Add(ServicePulseExport, pulse.Exporter(), ServicePulse, ServiceDB)
 
-	app.Add(ServiceCoreQueue, durable.OnCore(), ServiceKernel, ServiceDB, ServiceDurable, ServicePulse)
-	app.Add(ServiceHooksQueue, durable.OnHooks(), ServiceKernel, ServiceDB, ServiceDurable, ServicePulse)
-
-	return nil
+	return c.SetupQueues(app)
 }
 
 // all configures the application with all services and modes.
@@ -202,13 +233,15 @@ func (c *Config) all(app *graceful.Graceful) error {
 		return err
 	}
 
-	workers.Core()
-	workers.Hooks()
+	webhook, err := NewWebhookServer(c.Durable)
+	if err != nil {
+		return err
+	}
 
-	app.Add(ServiceWebhook, NewWebhookServer(), ServiceKernel, ServiceDB, ServiceDurable, ServicePulse)
+	app.Add(ServicePulseBatch, pulse.BatchWriter(), ServicePulse, ServiceDB)
+	app.Add(ServicePulseExport, pulse.Exporter(), ServicePulse, ServiceDB)
+	app.Add(ServiceWebhook, webhook, ServiceKernel, ServiceDB, ServiceDurable, ServicePulse)
 	app.Add(ServiceNomad, nomad.New(nomad.WithConfig(c.Nomad)), ServiceKernel, ServiceDB, ServiceDurable, ServicePulse)
-	app.Add(ServiceCoreQueue, durable.OnCore(), ServiceKernel, ServiceDB, ServiceDurable, ServicePulse)
-	app.Add(ServiceHooksQueue, durable.OnHooks(), ServiceKernel, ServiceDB, ServiceDurable, ServicePulse)
 
-	return nil
+	return c.SetupQueues(app)
 }
@@ -5,10 +5,15 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 
+	"go.breu.io/quantm/internal/auth"
+	"go.breu.io/quantm/internal/durable"
+	"go.breu.io/quantm/internal/durable/codec"
 	"go.breu.io/quantm/internal/hooks/github"
+	"go.breu.io/quantm/internal/hooks/slack"
 )
 
 type (
@@ -34,14 +39,61 @@ func (w *WebhookService) Stop(ctx context.Context) error {
 	return w.Shutdown(ctx)
 }
 
-func NewWebhookServer() *WebhookService {
+// NewWebhookServer creates the webhook server. If the durable layer encrypts the payloads, the server also serves the
+// codec server for the Temporal UI under /codec.
+func NewWebhookServer(conf *durable.Config) (*WebhookService, error) {
 	webhook := echo.New()
 	webhook.HideBanner = true
 	webhook.HidePort = true
 
 	github := &github.Webhook{}
 
+	slack := &slack.Webhook{}
+
 	webhook.POST("/webhooks/github", github.Handler)
+	webhook.POST("/webhooks/slack/interactions", slack.Interactions)
+	webhook.POST("/webhooks/slack/commands", slack.Commands)
+
+	codecs, err := conf.Codecs()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(codecs) > 0 {
+		handler := codec.Handler(
+			authorize_codec,
+			codecs,
+			codec.WithOrigins(origins(conf.CodecOrigins)...),
+			codec.WithNamespace(conf.Namespace),
+		)
+
+		webhook.Match([]string{http.MethodPost, http.MethodOptions}, "/codec/*", echo.WrapHandler(handler))
+	}
+
+	return &WebhookService{webhook}, nil
+}
+
+// authorize_codec allows the requests to the codec server carrying a valid bearer token of a user.
+func authorize_codec(r *http.Request) error {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return errors.New("missing bearer token")
+	}
+
+	_, err := auth.DecodeJWE(auth.Secret(), token)
+
+	return err
+}
+
+// origins splits the comma separated origins.
+func origins(value string) []string {
+	result := make([]string, 0)
+
+	for _, origin := range strings.Split(value, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			result = append(result, origin)
+		}
+	}
 
-	return &WebhookService{webhook}
+	return result
 }
@@ -2,16 +2,25 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
+	"slices"
 	"syscall"
+	"time"
 
+	"github.com/google/uuid"
 	"go.breu.io/graceful"
 
 	"go.breu.io/quantm/cmd/quantm/config"
 	"go.breu.io/quantm/internal/db"
 	"go.breu.io/quantm/internal/db/migrations"
+	"go.breu.io/quantm/internal/durable"
+	"go.breu.io/quantm/internal/events"
+	"go.breu.io/quantm/internal/hooks/github/replay"
+	"go.breu.io/quantm/internal/pulse"
 )
 
 func main() {
@@ -23,7 +32,7 @@ func main() {
 
 	// - run migrations and exit if mode is migrate
 	if conf.Mode == config.ModeMigrate {
-		if err := migrations.Run(ctx, db.Get(db.WithConfig(conf.DB))); err != nil {
+		if err := migrate(ctx, conf); err != nil {
 			slog.Error("unable to run migrations", "error", err.Error())
 			os.Exit(1)
 		}
@@ -31,6 +40,26 @@ func main() {
 		os.Exit(0)
 	}
 
+	// - replay the events of a repo and exit if mode is replay
+	if conf.Mode == config.ModeReplay {
+		if err := run_replay(ctx, conf); err != nil {
+			slog.Error("unable to replay events", "error", err.Error())
+			os.Exit(1)
+		}
+
+		os.Exit(0)
+	}
+
+	// - run the admin command and exit if mode is admin
+	if conf.Mode == config.ModeAdmin {
+		if err := run_admin(ctx, conf); err != nil {
+			slog.Error("unable to run admin command", "error", err.Error())
+			os.Exit(1)
+		}
+
+		os.Exit(0)
+	}
+
 	// - run the app based on mode, exit 1 on error else wait for signal
 
 	quit := make(chan os.Signal, 1)
@@ -61,3 +90,136 @@ func main() {
 
 	os.Exit(0)
 }
+
+// migrate runs the postgres migrations, followed by the clickhouse migrations for the events tables of every org.
+func migrate(ctx context.Context, conf *config.Config) error {
+	pg := db.Get(db.WithConfig(conf.DB))
+	if err := pg.Start(ctx); err != nil {
+		return err
+	}
+
+	defer func() { _ = pg.Stop(ctx) }()
+
+	if err := migrations.Run(ctx, pg); err != nil {
+		return err
+	}
+
+	ch := pulse.Get(pulse.WithConfig(conf.Pulse))
+	if err := ch.Start(ctx); err != nil {
+		return err
+	}
+
+	defer func() { _ = ch.Stop(ctx) }()
+
+	return pulse.MigrateAll(ctx)
+}
+
+// run_replay reads the events of the repo in the time range, from clickhouse or the NDJSON export, and signals them to the
+// repo workflow.
+func run_replay(ctx context.Context, conf *config.Config) error {
+	conf.SetupLogger()
+
+	opts, err := replay_options(conf.Replay)
+	if err != nil {
+		return err
+	}
+
+	pg := db.Get(db.WithConfig(conf.DB))
+	if err := pg.Start(ctx); err != nil {
+		return err
+	}
+
+	defer func() { _ = pg.Stop(ctx) }()
+
+	repo, err := db.Queries().GetRepoByID(ctx, opts.Repo)
+	if err != nil {
+		return err
+	}
+
+	opts.Org = repo.OrgID
+
+	var records []pulse.Record
+
+	if conf.Replay.File != "" {
+		file, err := os.Open(conf.Replay.File)
+		if err != nil {
+			return err
+		}
+
+		defer file.Close()
+
+		if records, err = replay.FromFile(file, opts); err != nil {
+			return err
+		}
+	} else {
+		ch := pulse.Get(pulse.WithConfig(conf.Pulse))
+		if err := ch.Start(ctx); err != nil {
+			return err
+		}
+
+		defer func() { _ = ch.Stop(ctx) }()
+
+		if records, err = replay.FromClickhouse(ctx, opts); err != nil {
+			return err
+		}
+	}
+
+	signal := replay.Signal(nil)
+
+	if !opts.DryRun {
+		durable.Get(durable.WithConfig(conf.Durable))
+
+		if signal, err = replay.Signaler(ctx, opts.Repo); err != nil {
+			return err
+		}
+	}
+
+	summary, err := replay.Run(ctx, records, opts, signal)
+	if err != nil {
+		return err
+	}
+
+	slog.Info(
+		"replay: done",
+		"read", summary.Read, "replayed", summary.Replayed, "skipped", summary.Skipped, "failed", summary.Failed, "dry_run", opts.DryRun,
+	)
+
+	return nil
+}
+
+// replay_options parses the command line options of the replay mode.
+func replay_options(r *config.Replay) (*replay.Options, error) {
+	opts := &replay.Options{DryRun: r.DryRun, Rate: r.Rate, End: time.Now()}
+
+	repo, err := uuid.Parse(r.Repo)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --repo: %w", err)
+	}
+
+	opts.Repo = repo
+
+	if opts.Start, err = time.Parse(time.RFC3339, r.From); err != nil {
+		return nil, fmt.Errorf("invalid --from: %w", err)
+	}
+
+	if r.To != "" {
+		if opts.End, err = time.Parse(time.RFC3339, r.To); err != nil {
+			return nil, fmt.Errorf("invalid --to: %w", err)
+		}
+	}
+
+	if !opts.Start.Before(opts.End) {
+		return nil, errors.New("--from must be before --to")
+	}
+
+	for _, include := range r.Include {
+		scope := events.Scope(include)
+		if !slices.Contains(replay.OptInScopes, scope) {
+			return nil, fmt.Errorf("invalid --include: %s, must be one of %v", include, replay.OptInScopes)
+		}
+
+		opts.Include = append(opts.Include, scope)
+	}
+
+	return opts, nil
+}
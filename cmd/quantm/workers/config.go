@@ -0,0 +1,74 @@
+package workers
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.breu.io/quantm/internal/durable"
+)
+
+type (
+	// Config selects the queues a process works on, and sizes the git workers.
+	//
+	// By default, a process works on all the queues. To scale the git workers apart, run the git workers with
+	// WORKERS__QUEUES=git, and the rest with WORKERS__QUEUES=core,hooks.
+	Config struct {
+		Queues          string `json:"queues" koanf:"QUEUES"`                         // Comma separated queues, of core, hooks and git.
+		GitSessions     int    `json:"git_sessions" koanf:"GIT_SESSIONS"`             // Max concurrent git sessions per worker.
+		GitMinFreeSpace int    `json:"git_min_free_space" koanf:"GIT_MIN_FREE_SPACE"` // Min free MiB to accept a git session.
+	}
+)
+
+var (
+	// DefaultConfig works on all the queues.
+	DefaultConfig = Config{
+		Queues:          strings.Join([]string{durable.QueueCore, durable.QueueHooks, durable.QueueGit}, ","),
+		GitSessions:     4,
+		GitMinFreeSpace: 1024,
+	}
+)
+
+// Validate returns an error if a queue is unknown or the git workers are misconfigured.
+func (c *Config) Validate() error {
+	known := []string{durable.QueueCore, durable.QueueHooks, durable.QueueGit}
+
+	for _, queue := range c.queues() {
+		if !slices.Contains(known, queue) {
+			return fmt.Errorf("workers: unknown queue %q, expected one of %s", queue, strings.Join(known, ", "))
+		}
+	}
+
+	if c.Has(durable.QueueGit) && c.GitSessions < 1 {
+		return fmt.Errorf("workers: git sessions must be at least 1, got %d", c.GitSessions)
+	}
+
+	if c.GitMinFreeSpace < 0 {
+		return fmt.Errorf("workers: git min free space must not be negative, got %d", c.GitMinFreeSpace)
+	}
+
+	return nil
+}
+
+// Has returns true if the process works on the queue.
+func (c *Config) Has(queue string) bool {
+	return slices.Contains(c.queues(), queue)
+}
+
+// MinFreeSpace returns the free space in bytes a git worker requires to accept a session.
+func (c *Config) MinFreeSpace() uint64 {
+	return uint64(c.GitMinFreeSpace) << 20 // nolint: gosec
+}
+
+// queues returns the selected queues.
+func (c *Config) queues() []string {
+	result := make([]string, 0)
+
+	for _, queue := range strings.Split(c.Queues, ",") {
+		if queue = strings.TrimSpace(queue); queue != "" {
+			result = append(result, queue)
+		}
+	}
+
+	return result
+}
@@ -14,6 +14,7 @@ import (
 func Core() {
 	q := durable.OnCore()
 
+	// Sessions are kept on the core queue for the branches recorded before the git queue, see Git.
 	q.CreateWorker(
 		queues.WithWorkerOptionEnableSessionWorker(true),
 	)
@@ -36,5 +37,8 @@ func Core() {
 
 		// Register notify activities
 		q.RegisterActivity(repos.NewNotifyActivities())
+
+		// Register provider activities
+		q.RegisterActivity(repos.NewProviderActivities())
 	}
 }
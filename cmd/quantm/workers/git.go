@@ -0,0 +1,26 @@
+package workers
+
+import (
+	"go.breu.io/durex/queues"
+
+	"go.breu.io/quantm/internal/core/repos"
+	"go.breu.io/quantm/internal/durable"
+)
+
+// Git registers the git activities at the git queue. The branch workflows on the core queue run their clone, diff and
+// rebase in a session on this queue, so git workers can be scaled apart from the core and hooks workers.
+//
+// Each worker runs up to conf.GitSessions sessions at once, and gives up a session if its clones disk has less than
+// conf.GitMinFreeSpace free.
+func Git(conf *Config) {
+	q := durable.OnGit()
+
+	q.CreateWorker(
+		queues.WithWorkerOptionEnableSessionWorker(true),
+		queues.WithWorkerOptionMaxConcurrentSessionExecutionSize(conf.GitSessions),
+	)
+
+	if q != nil {
+		q.RegisterActivity(repos.NewGitActivities(conf.MinFreeSpace()))
+	}
+}
@@ -8,6 +8,7 @@ import (
 var (
 	Secret    = config.Secret
 	SetSecret = config.SetSecret
+	DecodeJWE = config.DecodeJWE
 )
 
 var (
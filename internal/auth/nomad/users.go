@@ -75,8 +75,8 @@ func (s *UserService) CreateUser(
 			return nil, erratic.NewDatabaseError(erratic.AuthModule).Wrap(err)
 		}
 
-		// Create Tables in Clickhouse
-		err = pulse.CreateEventsTable(ctx, slug)
+		// Create the events table of the org in the event sink.
+		err = pulse.CreateEventsTable(ctx, org.ID, slug)
 		if err != nil {
 			return nil, erratic.NewDatabaseError(erratic.AuthModule).Wrap(err)
 		}
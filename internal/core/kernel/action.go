@@ -0,0 +1,84 @@
+package kernel
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+type (
+	// ChatActionKind is the kind of action a user can take on an interactive notification.
+	ChatActionKind string
+
+	// ChatAction is the provider neutral action taken by a user on an interactive notification. The chat hook decodes
+	// it from the interaction and hands it over to the repo workflow, which routes it to the branch or the trunk.
+	ChatAction struct {
+		Kind   ChatActionKind `json:"kind"`
+		RepoID uuid.UUID      `json:"repo_id"`
+		Branch string         `json:"branch"`
+		Number int64          `json:"number,omitempty"` // Number is the pull request number, required to enqueue.
+		User   string         `json:"user,omitempty"`   // User is the chat platform ID of the user who took the action.
+	}
+)
+
+const (
+	ChatActionRebase  ChatActionKind = "rebase"  // ChatActionRebase rebases the branch on the default branch right away.
+	ChatActionEnqueue ChatActionKind = "enqueue" // ChatActionEnqueue adds the pull request to the merge queue.
+	ChatActionSnooze  ChatActionKind = "snooze"  // ChatActionSnooze snoozes the stale branch reminder.
+	ChatActionDismiss ChatActionKind = "dismiss" // ChatActionDismiss dismisses the notification.
+)
+
+var (
+	labels = map[ChatActionKind]string{
+		ChatActionRebase:  "Rebase now",
+		ChatActionEnqueue: "Add to merge queue",
+		ChatActionSnooze:  "Snooze 3 days",
+		ChatActionDismiss: "Dismiss",
+	}
+
+	styles = map[ChatActionKind]string{
+		ChatActionRebase:  "primary",
+		ChatActionEnqueue: "primary",
+	}
+)
+
+// String returns the string representation of the ChatActionKind.
+func (k ChatActionKind) String() string { return string(k) }
+
+// Valid returns true if the kind is a known chat action.
+func (k ChatActionKind) Valid() bool {
+	_, ok := labels[k]
+	return ok
+}
+
+// AddChatAction appends an interactive action to the notification. The action is encoded in the value of the
+// notification action, so that it can be decoded with ParseChatAction when the user interacts with it.
+func (n *Notification) AddChatAction(action *ChatAction) *Notification {
+	value, _ := json.Marshal(action)
+
+	return n.AddAction(action.Kind.String(), labels[action.Kind], string(value), styles[action.Kind])
+}
+
+// NewChatAction creates a new chat action against the branch of the repo.
+func NewChatAction(kind ChatActionKind, repo uuid.UUID, branch string) *ChatAction {
+	return &ChatAction{Kind: kind, RepoID: repo, Branch: branch}
+}
+
+// ParseChatAction decodes the chat action from the ID and the value of the notification action.
+func ParseChatAction(id, value string) (*ChatAction, error) {
+	action := &ChatAction{}
+	if err := json.Unmarshal([]byte(value), action); err != nil {
+		return nil, err
+	}
+
+	if !action.Kind.Valid() || action.Kind.String() != id {
+		return nil, fmt.Errorf("kernel: invalid chat action %q", id)
+	}
+
+	if action.RepoID == uuid.Nil {
+		return nil, fmt.Errorf("kernel: chat action %q has no repo", id)
+	}
+
+	return action, nil
+}
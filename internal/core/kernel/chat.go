@@ -9,12 +9,17 @@ import (
 
 type (
 	Chat interface {
-		// NotifyLinesExceed sends a message indicating a line exceed message.
+		// Notify renders the notification for the chat platform and sends it to the resolved recipient.
+		//
+		// This method must not be called from the workflow.
+		Notify(ctx context.Context, notification *Notification) error
+
+		// NotifyLinesExceed sends a message indicating a line exceed message. It is a thin wrapper over Notify.
 		//
 		// This method must not be called from the workflow.
 		NotifyLinesExceed(ctx context.Context, event *events.Event[eventsv1.ChatHook, eventsv1.Diff]) error
 
-		// NotifyMergeConflict sends a message indicating a merge conflict.
+		// NotifyMergeConflict sends a message indicating a merge conflict. It is a thin wrapper over Notify.
 		//
 		// This method must not be called from the workflow.
 		NotifyMergeConflict(ctx context.Context, event *events.Event[eventsv1.ChatHook, eventsv1.Merge]) error
@@ -0,0 +1,301 @@
+package kernel
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"go.breu.io/quantm/internal/events"
+	eventsv1 "go.breu.io/quantm/internal/proto/ctrlplane/events/v1"
+)
+
+type (
+	// NotificationKind is the type of the notification. Chat implementations may use it to pick a template.
+	NotificationKind string
+
+	// Severity is the severity of the notification.
+	Severity string
+
+	// RecipientKind is the kind of the recipient of the notification.
+	RecipientKind string
+
+	// Recipient identifies who receives the notification. ID is the ID the chat link is linked to, i.e. the user ID
+	// for users, the repo ID for channels and the team ID for teams.
+	Recipient struct {
+		Kind RecipientKind `json:"kind"`
+		ID   uuid.UUID     `json:"id"`
+	}
+
+	// NotificationField is a key value pair rendered as part of the notification.
+	NotificationField struct {
+		Title string `json:"title"`
+		Value string `json:"value"`
+		Short bool   `json:"short"` // Short hints that the field can be rendered side by side with other short fields.
+	}
+
+	// NotificationLink is a link rendered as part of the notification.
+	NotificationLink struct {
+		Text string `json:"text"`
+		URL  string `json:"url"`
+	}
+
+	// NotificationAction is an interactive action attached to the notification, e.g. a button.
+	NotificationAction struct {
+		ID    string `json:"id"`    // ID identifies the action when the user interacts with it.
+		Text  string `json:"text"`  // Text is the label of the action.
+		Value string `json:"value"` // Value is the opaque value sent back when the user interacts with it.
+		Style string `json:"style"` // Style is an optional hint, either "primary" or "danger".
+	}
+
+	// Thread groups notifications about the same subject, e.g. a branch or a pull request, into one conversation.
+	// Chat implementations post the first notification as the root of the thread, reply to it afterwards and keep
+	// the root updated with the latest status.
+	Thread struct {
+		SubjectID uuid.UUID `json:"subject_id"` // SubjectID is the ID of the subject, usually the repo ID.
+		Ref       string    `json:"ref"`        // Ref identifies the thread within the subject, e.g. "branch/main".
+	}
+
+	// Notification is the provider neutral message sent through a chat hook.
+	Notification struct {
+		Kind      NotificationKind     `json:"kind"`
+		Title     string               `json:"title"`
+		Body      string               `json:"body"`
+		Severity  Severity             `json:"severity"`
+		Recipient Recipient            `json:"recipient"`
+		Fields    []NotificationField  `json:"fields"`
+		Links     []NotificationLink   `json:"links"`
+		Actions   []NotificationAction `json:"actions"`
+		Thread    *Thread              `json:"thread,omitempty"` // Thread, if set, threads the notification.
+		Status    string               `json:"status,omitempty"` // Status is the latest status shown on the thread root.
+		Final     bool                 `json:"final,omitempty"`  // Final closes the thread, the next one starts a new thread.
+	}
+)
+
+const (
+	NotificationKindLinesExceeded NotificationKind = "lines_exceeded" // NotificationKindLinesExceeded for large diffs.
+	NotificationKindMergeConflict NotificationKind = "merge_conflict" // NotificationKindMergeConflict for rebase conflicts.
+	NotificationKindStaleBranch   NotificationKind = "stale_branch"   // NotificationKindStaleBranch for idle branches.
+	NotificationKindQueuePosition NotificationKind = "queue_position" // NotificationKindQueuePosition for merge queue updates.
+	NotificationKindCIFailure     NotificationKind = "ci_failure"     // NotificationKindCIFailure for failed checks.
+	NotificationKindStatus        NotificationKind = "status"         // NotificationKindStatus for status updates of a thread.
+)
+
+const (
+	SeverityInfo    Severity = "info"
+	SeveritySuccess Severity = "success"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+const (
+	RecipientUser    RecipientKind = "user"
+	RecipientChannel RecipientKind = "channel"
+	RecipientTeam    RecipientKind = "team"
+)
+
+// String returns the string representation of the NotificationKind.
+func (k NotificationKind) String() string { return string(k) }
+
+// String returns the string representation of the Severity.
+func (s Severity) String() string { return string(s) }
+
+// String returns the string representation of the RecipientKind.
+func (k RecipientKind) String() string { return string(k) }
+
+// String returns the string representation of the Recipient, e.g. "channel/<repo id>".
+func (r Recipient) String() string { return r.Kind.String() + "/" + r.ID.String() }
+
+// AddField appends a field to the notification.
+func (n *Notification) AddField(title, value string, short bool) *Notification {
+	n.Fields = append(n.Fields, NotificationField{Title: title, Value: value, Short: short})
+	return n
+}
+
+// AddLink appends a link to the notification.
+func (n *Notification) AddLink(text, url string) *Notification {
+	n.Links = append(n.Links, NotificationLink{Text: text, URL: url})
+	return n
+}
+
+// AddAction appends an action to the notification.
+func (n *Notification) AddAction(id, text, value, style string) *Notification {
+	n.Actions = append(n.Actions, NotificationAction{ID: id, Text: text, Value: value, Style: style})
+	return n
+}
+
+// SetThread threads the notification.
+func (n *Notification) SetThread(thread *Thread) *Notification {
+	n.Thread = thread
+	return n
+}
+
+// SetStatus sets the status shown on the root of the thread.
+func (n *Notification) SetStatus(status string) *Notification {
+	n.Status = status
+	return n
+}
+
+// Close closes the thread with the notification.
+func (n *Notification) Close() *Notification {
+	n.Final = true
+	return n
+}
+
+// BranchThread returns the thread for the branch of the repo.
+func BranchThread(repo uuid.UUID, branch string) *Thread {
+	return &Thread{SubjectID: repo, Ref: "branch/" + branch}
+}
+
+// PullRequestThread returns the thread for the pull request of the repo.
+func PullRequestThread(repo uuid.UUID, number int64) *Thread {
+	return &Thread{SubjectID: repo, Ref: fmt.Sprintf("pr/%d", number)}
+}
+
+// NewNotification creates a new notification.
+func NewNotification(kind NotificationKind, severity Severity, recipient Recipient, title, body string) *Notification {
+	return &Notification{
+		Kind:      kind,
+		Title:     title,
+		Body:      body,
+		Severity:  severity,
+		Recipient: recipient,
+		Fields:    make([]NotificationField, 0),
+		Links:     make([]NotificationLink, 0),
+		Actions:   make([]NotificationAction, 0),
+	}
+}
+
+// StatusNotification builds the status update of the thread. Unlike other notifications, a status update never starts
+// a thread, and is dropped if the thread doesn't exist.
+func StatusNotification(recipient Recipient, thread *Thread, severity Severity, status string) *Notification {
+	return NewNotification(NotificationKindStatus, severity, recipient, status, "").SetThread(thread).SetStatus(status)
+}
+
+// RecipientFromSubject resolves the recipient from the subject of the event. The user is preferred, falling back to
+// the channel linked with the subject.
+func RecipientFromSubject(subject events.Subject) Recipient {
+	if subject.UserID != uuid.Nil {
+		return Recipient{Kind: RecipientUser, ID: subject.UserID}
+	}
+
+	return Recipient{Kind: RecipientChannel, ID: subject.ID}
+}
+
+// LinesExceededNotification builds the notification sent when the lines changed on a branch exceed the threshold.
+func LinesExceededNotification(event *events.Event[eventsv1.ChatHook, eventsv1.Diff]) *Notification {
+	lines := event.Payload.GetLines()
+	files := event.Payload.GetFiles()
+
+	n := NewNotification(
+		NotificationKindLinesExceeded,
+		SeverityWarning,
+		RecipientFromSubject(event.Subject),
+		"Line Exceed Detected",
+		"The number of lines in this pull request exceeds the allowed threshold. Please review and adjust accordingly.",
+	)
+
+	n.
+		AddField("Repository", repo_name(event.Context.Source), true).
+		AddField("Total Lines Count", fmt.Sprintf("%d", lines.GetAdded()+lines.GetRemoved()), true).
+		AddField("Lines Added", fmt.Sprintf("%d", lines.GetAdded()), true).
+		AddField("Lines Deleted", fmt.Sprintf("%d", lines.GetRemoved()), true).
+		AddField("Added Files", bullets(files.GetAdded()), false).
+		AddField("Deleted Files", bullets(files.GetDeleted()), false).
+		AddField("Modified Files", bullets(files.GetModified()), false)
+
+	renamed := make([]string, 0, len(files.GetRenamed()))
+	for _, file := range files.GetRenamed() {
+		renamed = append(renamed, fmt.Sprintf("%s -> %s", file.GetOld(), file.GetNew()))
+	}
+
+	n.AddField("Renamed Files", bullets(renamed), false)
+	n.AddLink("Repository", event.Context.Source)
+
+	return n
+}
+
+// MergeConflictNotification builds the notification sent when a branch can no longer be rebased on the default branch.
+func MergeConflictNotification(event *events.Event[eventsv1.ChatHook, eventsv1.Merge]) *Notification {
+	n := NewNotification(
+		NotificationKindMergeConflict,
+		SeverityWarning,
+		RecipientFromSubject(event.Subject),
+		"Merge Conflict Detected",
+		fmt.Sprintf(
+			"We've detected a merge conflict in your feature branch, %s. This means there are changes in your branch "+
+				"that clash with recent updates on the main branch (trunk).",
+			event.Payload.GetHeadBranch(),
+		),
+	)
+
+	n.
+		AddField("Repository", repo_name(event.Context.Source), true).
+		AddField("Branch", event.Payload.GetBaseBranch(), true).
+		AddField("Current HEAD", event.Payload.GetHeadBranch(), true).
+		AddField("Affected Files", bullets(event.Payload.GetFiles()), false)
+
+	n.AddLink(event.Payload.GetHeadBranch(), fmt.Sprintf("%s/tree/%s", event.Context.Source, event.Payload.GetHeadBranch()))
+
+	return n
+}
+
+// QueuedNotification builds the notification sent when a pull request is added to the merge queue.
+func QueuedNotification(recipient Recipient, item *eventsv1.MergeQueue, position int) *Notification {
+	n := NewNotification(
+		NotificationKindQueuePosition,
+		SeverityInfo,
+		recipient,
+		"Pull Request Queued",
+		fmt.Sprintf("Pull request #%d from %s is in the merge queue.", item.GetNumber(), item.GetBranch()),
+	)
+
+	n.
+		AddField("Branch", item.GetBranch(), true).
+		AddField("Position", fmt.Sprintf("%d", position), true)
+
+	return n
+}
+
+// StaleBranchNotification builds the digest sent when a branch has had no commits for a while.
+func StaleBranchNotification(recipient Recipient, source, branch string, idle time.Duration) *Notification {
+	days := int(idle.Hours() / 24)
+
+	n := NewNotification(
+		NotificationKindStaleBranch,
+		SeverityInfo,
+		recipient,
+		"Stale Branch",
+		fmt.Sprintf(
+			"The branch %s has had no commits for %d days. Rebase it on the default branch to keep it mergeable, or "+
+				"delete it if it is no longer needed.",
+			branch, days,
+		),
+	)
+
+	n.
+		AddField("Repository", repo_name(source), true).
+		AddField("Branch", branch, true).
+		AddField("Idle", fmt.Sprintf("%d days", days), true)
+
+	n.AddLink(branch, fmt.Sprintf("%s/tree/%s", source, branch))
+
+	return n
+}
+
+// repo_name extracts the repository name from the url.
+func repo_name(url string) string {
+	parts := strings.Split(url, "/")
+	return parts[len(parts)-1]
+}
+
+// bullets formats the list as a bulleted list.
+func bullets(items []string) string {
+	result := ""
+	for _, item := range items {
+		result += "- " + item + "\n"
+	}
+
+	return result
+}
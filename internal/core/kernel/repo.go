@@ -7,10 +7,140 @@ import (
 )
 
 type (
+	// CommitState is the provider neutral state of a commit status.
+	CommitState string
+
+	// MergeStrategy is the provider neutral strategy used to merge a pull request.
+	MergeStrategy string
+
+	// CheckRunStatus is the provider neutral status of a check run.
+	CheckRunStatus string
+
+	// CheckRunConclusion is the provider neutral conclusion of a completed check run.
+	CheckRunConclusion string
+
+	// CommitStatus describes the status to set against a commit.
+	CommitStatus struct {
+		SHA         string      `json:"sha"`         // SHA is the commit to set the status on.
+		State       CommitState `json:"state"`       // State is the state of the status.
+		Context     string      `json:"context"`     // Context is the label that differentiates this status from others.
+		Description string      `json:"description"` // Description is the short, human readable description.
+		TargetURL   string      `json:"target_url"`  // TargetURL is the link to the details of the status.
+	}
+
+	// CheckRun describes a check run against a commit. Unlike a commit status, a check run carries a summary, and is
+	// updated in place as it progresses.
+	CheckRun struct {
+		ID         int64              `json:"id"`          // ID is the ID of the check run on the provider, set once created.
+		SHA        string             `json:"sha"`         // SHA is the commit the check runs against.
+		Name       string             `json:"name"`        // Name is the name of the check.
+		Status     CheckRunStatus     `json:"status"`      // Status is the status of the check.
+		Conclusion CheckRunConclusion `json:"conclusion"`  // Conclusion is required once the status is completed.
+		Title      string             `json:"title"`       // Title is the title of the output. Can be empty.
+		Summary    string             `json:"summary"`     // Summary is the markdown summary of the output. Can be empty.
+		DetailsURL string             `json:"details_url"` // DetailsURL is the link to the details of the check.
+	}
+
+	// MergeOptions describes how a pull request should be merged. A nil MergeOptions merges with MergeStrategyMerge.
+	MergeOptions struct {
+		Strategy MergeStrategy `json:"strategy"` // Strategy is the merge strategy.
+		Title    string        `json:"title"`    // Title is the title of the merge commit. Can be empty.
+		Message  string        `json:"message"`  // Message is the body of the merge commit. Can be empty.
+		SHA      string        `json:"sha"`      // SHA, if set, must match the head of the pull request for the merge to happen.
+	}
+
 	Repo interface {
 		// TokenizedCloneUrl returns the tokenized clone URL for the repository with the given ID.
 		//
 		// This method must not be called from the workflow.
 		TokenizedCloneUrl(ctx context.Context, repo *entities.Repo) (string, error)
+
+		// AddComment posts a comment on the pull request with the given number.
+		//
+		// This method must not be called from the workflow.
+		AddComment(ctx context.Context, repo *entities.Repo, number int64, body string) error
+
+		// SetCommitStatus sets the status of a commit.
+		//
+		// This method must not be called from the workflow.
+		SetCommitStatus(ctx context.Context, repo *entities.Repo, status *CommitStatus) error
+
+		// CreateCheckRun creates a check run against a commit and returns its ID.
+		//
+		// This method must not be called from the workflow.
+		CreateCheckRun(ctx context.Context, repo *entities.Repo, run *CheckRun) (int64, error)
+
+		// UpdateCheckRun updates the check run with the ID of the run.
+		//
+		// This method must not be called from the workflow.
+		UpdateCheckRun(ctx context.Context, repo *entities.Repo, run *CheckRun) error
+
+		// AddLabels adds the given labels to the pull request with the given number.
+		//
+		// This method must not be called from the workflow.
+		AddLabels(ctx context.Context, repo *entities.Repo, number int64, labels ...string) error
+
+		// RemoveLabel removes the given label from the pull request with the given number.
+		//
+		// This method must not be called from the workflow.
+		RemoveLabel(ctx context.Context, repo *entities.Repo, number int64, label string) error
+
+		// MergePullRequest merges the pull request with the given number and returns the SHA of the merge commit.
+		//
+		// This method must not be called from the workflow.
+		MergePullRequest(ctx context.Context, repo *entities.Repo, number int64, opts *MergeOptions) (string, error)
+
+		// CreateBranch creates a branch pointing at the given SHA.
+		//
+		// This method must not be called from the workflow.
+		CreateBranch(ctx context.Context, repo *entities.Repo, branch, sha string) error
+
+		// DeleteBranch deletes the given branch.
+		//
+		// This method must not be called from the workflow.
+		DeleteBranch(ctx context.Context, repo *entities.Repo, branch string) error
+
+		// ListBranches returns the names of all the branches of the repository.
+		//
+		// This method must not be called from the workflow.
+		ListBranches(ctx context.Context, repo *entities.Repo) ([]string, error)
 	}
 )
+
+const (
+	CommitStatePending CommitState = "pending" // CommitStatePending indicates the check is in progress.
+	CommitStateSuccess CommitState = "success" // CommitStateSuccess indicates the check passed.
+	CommitStateFailure CommitState = "failure" // CommitStateFailure indicates the check failed.
+	CommitStateError   CommitState = "error"   // CommitStateError indicates the check could not be completed.
+)
+
+const (
+	CheckRunStatusQueued     CheckRunStatus = "queued"      // CheckRunStatusQueued indicates the check is waiting to run.
+	CheckRunStatusInProgress CheckRunStatus = "in_progress" // CheckRunStatusInProgress indicates the check is running.
+	CheckRunStatusCompleted  CheckRunStatus = "completed"   // CheckRunStatusCompleted indicates the check has concluded.
+)
+
+const (
+	CheckRunConclusionSuccess   CheckRunConclusion = "success"   // CheckRunConclusionSuccess indicates the check passed.
+	CheckRunConclusionFailure   CheckRunConclusion = "failure"   // CheckRunConclusionFailure indicates the check failed.
+	CheckRunConclusionNeutral   CheckRunConclusion = "neutral"   // CheckRunConclusionNeutral indicates the check passed with remarks.
+	CheckRunConclusionCancelled CheckRunConclusion = "cancelled" // CheckRunConclusionCancelled indicates the check was cancelled.
+)
+
+const (
+	MergeStrategyMerge  MergeStrategy = "merge"  // MergeStrategyMerge creates a merge commit.
+	MergeStrategySquash MergeStrategy = "squash" // MergeStrategySquash squashes all commits into one.
+	MergeStrategyRebase MergeStrategy = "rebase" // MergeStrategyRebase rebases the commits onto the base branch.
+)
+
+// String returns the string representation of the CommitState.
+func (s CommitState) String() string { return string(s) }
+
+// String returns the string representation of the MergeStrategy.
+func (s MergeStrategy) String() string { return string(s) }
+
+// String returns the string representation of the CheckRunStatus.
+func (s CheckRunStatus) String() string { return string(s) }
+
+// String returns the string representation of the CheckRunConclusion.
+func (c CheckRunConclusion) String() string { return string(c) }
@@ -7,15 +7,29 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"syscall"
+
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/temporal"
 
 	"go.breu.io/quantm/internal/core/kernel"
 	"go.breu.io/quantm/internal/core/repos/defs"
 	"go.breu.io/quantm/internal/core/repos/git"
+	"go.breu.io/quantm/internal/db"
 	eventsv1 "go.breu.io/quantm/internal/proto/ctrlplane/events/v1"
 )
 
 type (
-	Branch struct{}
+	Branch struct {
+		// MinFreeSpace is the free space in bytes the clones disk must have for the worker to accept a git session, see
+		// CheckDiskSpace.
+		MinFreeSpace uint64
+	}
+)
+
+const (
+	// clones is the directory the repos are cloned into.
+	clones = "/tmp"
 )
 
 // Clone clones a repo to a temp path, fetching a specified branch.
@@ -26,10 +40,10 @@ func (a *Branch) Clone(ctx context.Context, payload *defs.ClonePayload) (string,
 		return "", err
 	}
 
-	path := fmt.Sprintf("/tmp/%s", payload.Path)
+	path := fmt.Sprintf("%s/%s", clones, payload.Path)
 
 	// Ensure parent directory exists (though /tmp usually does)
-	if err := os.MkdirAll("/tmp", 0755); err != nil {
+	if err := os.MkdirAll(clones, 0755); err != nil {
 		return "", fmt.Errorf("failed to create temp dir: %w", err)
 	}
 
@@ -41,6 +55,42 @@ func (a *Branch) Clone(ctx context.Context, payload *defs.ClonePayload) (string,
 	return path, nil
 }
 
+// CheckDiskSpace fails if the clones disk of the worker has less than MinFreeSpace free for the clone. It is the first
+// activity of a git session, so that the session is given up, and created again on another worker, before anything is
+// cloned.
+func (a *Branch) CheckDiskSpace(ctx context.Context, payload *defs.ClonePayload) error {
+	stat := syscall.Statfs_t{}
+	if err := syscall.Statfs(clones, &stat); err != nil {
+		slog.Warn("check_disk_space: unable to stat", "path", clones, "error", err)
+		return err
+	}
+
+	free := stat.Bavail * uint64(stat.Bsize) // nolint: gosec
+	if free < a.MinFreeSpace {
+		slog.Warn(
+			"check_disk_space: not enough free space",
+			"repo", payload.Repo.ID, "branch", payload.Branch, "path", clones, "free", free, "required", a.MinFreeSpace,
+		)
+
+		return temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("%d bytes free on %s, %d required", free, clones, a.MinFreeSpace), "InsufficientDiskSpace", nil,
+		)
+	}
+
+	return nil
+}
+
+// Timezone returns the IANA timezone of the team, used to schedule the stale branch digest.
+func (a *Branch) Timezone(ctx context.Context, team uuid.UUID) (string, error) {
+	row, err := db.Queries().GetTeamByID(ctx, team)
+	if err != nil {
+		slog.Warn("timezone: unable to get team", "team", team, "error", err)
+		return "", err
+	}
+
+	return row.Timezone, nil
+}
+
 // RemoveDir removes a directory and handles potential errors.
 func (a *Branch) RemoveDir(ctx context.Context, path string) error {
 	slog.Debug("removing directory", "path", path)
@@ -54,6 +104,13 @@ func (a *Branch) RemoveDir(ctx context.Context, path string) error {
 
 // Diff computes the diff between two commits using git CLI.
 func (a *Branch) Diff(ctx context.Context, payload *defs.DiffPayload) (*eventsv1.Diff, error) {
+	// Fetch latest origin to ensure we have the refs (CLI equivalent of refresh_remote). The clone only has the branch,
+	// so the base must be fetched before it can be resolved.
+	if _, err := git.Fetch(ctx, payload.Path, payload.Base); err != nil {
+		slog.Warn("diff: unable to fetch base", "base", payload.Base, "error", err)
+		// Proceeding anyway as local refs might be sufficient
+	}
+
 	// Ensure base and head exist
 	if _, err := git.RevParse(ctx, payload.Path, payload.Base); err != nil {
 		slog.Warn("diff: unable to resolve base", "base", payload.Base, "error", err)
@@ -65,12 +122,6 @@ func (a *Branch) Diff(ctx context.Context, payload *defs.DiffPayload) (*eventsv1
 		return nil, err
 	}
 
-	// Fetch latest origin to ensure we have the refs (CLI equivalent of refresh_remote)
-	if _, err := git.Fetch(ctx, payload.Path, payload.Base); err != nil {
-		slog.Warn("diff: unable to fetch base", "base", payload.Base, "error", err)
-		// Proceeding anyway as local refs might be sufficient
-	}
-
 	// 1. Get File Status (Added, Modified, Deleted, Renamed)
 	names, err := git.DiffStatus(ctx, payload.Path, payload.Base, payload.SHA)
 	if err != nil {
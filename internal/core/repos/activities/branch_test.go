@@ -0,0 +1,60 @@
+package activities_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.breu.io/quantm/internal/core/repos/activities"
+	"go.breu.io/quantm/internal/core/repos/defs"
+	"go.breu.io/quantm/internal/core/repos/git"
+)
+
+// TestBranch_Diff_FetchesBase diffs a clone of the branch alone, as the clone activity makes it. The default branch is
+// only on the remote, so the diff must fetch it before resolving it.
+func TestBranch_Diff_FetchesBase(t *testing.T) {
+	t.Setenv("GIT_AUTHOR_NAME", "quantm")
+	t.Setenv("GIT_AUTHOR_EMAIL", "quantm@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "quantm")
+	t.Setenv("GIT_COMMITTER_EMAIL", "quantm@example.com")
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	remote, work, clone := filepath.Join(dir, "remote.git"), filepath.Join(dir, "work"), filepath.Join(dir, "clone")
+
+	run := func(dir string, args ...string) string {
+		out, err := git.Run(ctx, dir, args...)
+		require.NoError(t, err)
+
+		return out
+	}
+
+	commit := func(branch, name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(work, name), []byte(content), 0600))
+		run(work, "add", "--all")
+		run(work, "commit", "--message", "update "+name)
+		run(work, "push", "origin", branch)
+	}
+
+	run(dir, "init", "--bare", "--initial-branch", "main", remote)
+	run(dir, "clone", remote, work)
+	run(work, "checkout", "-b", "main")
+	commit("main", "README.md", "# quantm\n")
+
+	run(work, "checkout", "-b", "feature")
+	commit("feature", "feature.txt", strings.Repeat("line\n", 3))
+
+	_, err := git.Clone(ctx, clone, remote, "feature")
+	require.NoError(t, err)
+
+	diff, err := (&activities.Branch{}).Diff(ctx, &defs.DiffPayload{Path: clone, Base: "main", SHA: run(work, "rev-parse", "HEAD")})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"feature.txt"}, diff.GetFiles().GetAdded())
+	assert.Equal(t, int32(3), diff.GetLines().GetAdded())
+}
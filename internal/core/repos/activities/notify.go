@@ -5,6 +5,7 @@ import (
 	"log/slog"
 
 	"go.breu.io/quantm/internal/core/kernel"
+	"go.breu.io/quantm/internal/core/repos/defs"
 	"go.breu.io/quantm/internal/events"
 	eventsv1 "go.breu.io/quantm/internal/proto/ctrlplane/events/v1"
 )
@@ -14,6 +15,17 @@ type (
 	Notify struct{}
 )
 
+// Send dispatches a generic notification via the chat hook in the payload. Returns error if notification fails,
+// logging a warning.
+func (n *Notify) Send(ctx context.Context, payload *defs.NotifyPayload) error {
+	if err := kernel.Get().ChatHook(payload.Hook).Notify(ctx, payload.Notification); err != nil {
+		slog.Warn("unable to notify on chat", "kind", payload.Notification.Kind, "error", err.Error())
+		return err
+	}
+
+	return nil
+}
+
 // LinesExceeded notifies a chat service of exceeded lines. It uses the context and event to dispatch a
 // notification via a chat hook. Returns error if notification fails, logging a warning.
 func (n *Notify) LinesExceeded(ctx context.Context, evt *events.Event[eventsv1.ChatHook, eventsv1.Diff]) error {
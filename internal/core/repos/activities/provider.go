@@ -0,0 +1,119 @@
+package activities
+
+import (
+	"context"
+	"log/slog"
+
+	"go.breu.io/quantm/internal/core/kernel"
+	"go.breu.io/quantm/internal/core/repos/defs"
+)
+
+type (
+	// Provider performs write operations against the repo provider, e.g. github, through the kernel.
+	Provider struct{}
+)
+
+// AddComment posts a comment on a pull request.
+func (a *Provider) AddComment(ctx context.Context, payload *defs.CommentPayload) error {
+	if err := kernel.Get().RepoHook(payload.Hook).AddComment(ctx, payload.Repo, payload.Number, payload.Body); err != nil {
+		slog.Warn("provider: unable to add comment", "repo", payload.Repo.ID, "number", payload.Number, "error", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// SetCommitStatus sets the status of a commit.
+func (a *Provider) SetCommitStatus(ctx context.Context, payload *defs.CommitStatusPayload) error {
+	if err := kernel.Get().RepoHook(payload.Hook).SetCommitStatus(ctx, payload.Repo, payload.Status); err != nil {
+		slog.Warn("provider: unable to set status", "repo", payload.Repo.ID, "sha", payload.Status.SHA, "error", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// CreateCheckRun creates a check run against a commit and returns its ID.
+func (a *Provider) CreateCheckRun(ctx context.Context, payload *defs.CheckRunPayload) (int64, error) {
+	id, err := kernel.Get().RepoHook(payload.Hook).CreateCheckRun(ctx, payload.Repo, payload.Run)
+	if err != nil {
+		slog.Warn("provider: unable to create check run", "repo", payload.Repo.ID, "sha", payload.Run.SHA, "error", err.Error())
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// UpdateCheckRun updates a check run.
+func (a *Provider) UpdateCheckRun(ctx context.Context, payload *defs.CheckRunPayload) error {
+	if err := kernel.Get().RepoHook(payload.Hook).UpdateCheckRun(ctx, payload.Repo, payload.Run); err != nil {
+		slog.Warn("provider: unable to update check run", "repo", payload.Repo.ID, "id", payload.Run.ID, "error", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// AddLabels adds labels to a pull request.
+func (a *Provider) AddLabels(ctx context.Context, payload *defs.LabelPayload) error {
+	if err := kernel.Get().RepoHook(payload.Hook).AddLabels(ctx, payload.Repo, payload.Number, payload.Labels...); err != nil {
+		slog.Warn("provider: unable to add labels", "repo", payload.Repo.ID, "number", payload.Number, "error", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// RemoveLabels removes labels from a pull request, one at a time.
+func (a *Provider) RemoveLabels(ctx context.Context, payload *defs.LabelPayload) error {
+	for _, label := range payload.Labels {
+		if err := kernel.Get().RepoHook(payload.Hook).RemoveLabel(ctx, payload.Repo, payload.Number, label); err != nil {
+			slog.Warn("provider: unable to remove label", "repo", payload.Repo.ID, "label", label, "error", err.Error())
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MergePullRequest merges a pull request and returns the SHA of the merge commit.
+func (a *Provider) MergePullRequest(ctx context.Context, payload *defs.MergePayload) (string, error) {
+	sha, err := kernel.Get().RepoHook(payload.Hook).MergePullRequest(ctx, payload.Repo, payload.Number, payload.Options)
+	if err != nil {
+		slog.Warn("provider: unable to merge", "repo", payload.Repo.ID, "number", payload.Number, "error", err.Error())
+		return "", err
+	}
+
+	return sha, nil
+}
+
+// CreateBranch creates a branch at the given SHA.
+func (a *Provider) CreateBranch(ctx context.Context, payload *defs.BranchPayload) error {
+	if err := kernel.Get().RepoHook(payload.Hook).CreateBranch(ctx, payload.Repo, payload.Branch, payload.SHA); err != nil {
+		slog.Warn("provider: unable to create branch", "repo", payload.Repo.ID, "branch", payload.Branch, "error", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// DeleteBranch deletes a branch.
+func (a *Provider) DeleteBranch(ctx context.Context, payload *defs.BranchPayload) error {
+	if err := kernel.Get().RepoHook(payload.Hook).DeleteBranch(ctx, payload.Repo, payload.Branch); err != nil {
+		slog.Warn("provider: unable to delete branch", "repo", payload.Repo.ID, "branch", payload.Branch, "error", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// ListBranches returns the branches of the repo on the provider.
+func (a *Provider) ListBranches(ctx context.Context, payload *defs.BranchPayload) ([]string, error) {
+	branches, err := kernel.Get().RepoHook(payload.Hook).ListBranches(ctx, payload.Repo)
+	if err != nil {
+		slog.Warn("provider: unable to list branches", "repo", payload.Repo.ID, "error", err.Error())
+		return nil, err
+	}
+
+	return branches, nil
+}
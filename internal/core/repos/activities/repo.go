@@ -2,8 +2,11 @@ package activities
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 
+	"go.temporal.io/api/serviceerror"
+
 	"go.breu.io/quantm/internal/core/repos/defs"
 	"go.breu.io/quantm/internal/durable"
 )
@@ -33,6 +36,22 @@ func (a *Repo) ForwardToBranch(ctx context.Context, payload *defs.SignalBranchPa
 	return err
 }
 
+// SignalBranch sends a signal to a running branch workflow. Unlike ForwardToBranch, the workflow is not started, and a
+// branch without a running workflow is not an error.
+func (a *Repo) SignalBranch(ctx context.Context, payload *defs.SignalBranchPayload, event any) error {
+	id := defs.BranchWorkflowOptions(payload.Repo, payload.Branch)
+
+	err := durable.OnCore().SignalWorkflow(ctx, id, payload.Signal, event)
+
+	nf := &serviceerror.NotFound{}
+	if errors.As(err, &nf) {
+		slog.Info("signal_branch: not running", "id", id.IDSuffix())
+		return nil
+	}
+
+	return err
+}
+
 // ForwardToTrunk sends a signal to the trunk workflow, starting it if it doesn't exist.
 func (a *Repo) ForwardToTrunk(ctx context.Context, payload *defs.SignalTrunkPayload, event, state any) error {
 	_, err := durable.
@@ -0,0 +1,299 @@
+// Package admin inspects and repairs the repo, branch and trunk workflows of an org, without the temporal UI.
+//
+// The workflows of a repo share the ID prefix built by durable.NewWorkflowOptions for the repo, i.e.
+//
+//	{queue}.org.{org}.repos.{repo}.
+//
+// followed by the name of the repo and, for the branch and the trunk, the branch. The workflows are listed by prefix
+// and told apart by their type, so that ops don't need to know the format.
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/client"
+
+	"go.breu.io/quantm/internal/core/repos/activities"
+	"go.breu.io/quantm/internal/core/repos/defs"
+	"go.breu.io/quantm/internal/core/repos/states"
+	"go.breu.io/quantm/internal/core/repos/workflows"
+	"go.breu.io/quantm/internal/db"
+	"go.breu.io/quantm/internal/db/entities"
+	"go.breu.io/quantm/internal/durable"
+)
+
+type (
+	// Kind is the type of a workflow, as registered on the core queue.
+	Kind string
+
+	// Workflow is a repo, branch or trunk workflow.
+	Workflow struct {
+		ID      string         `json:"id"`
+		RunID   string         `json:"run_id"`
+		Kind    Kind           `json:"kind"`
+		Repo    *entities.Repo `json:"-"`
+		Branch  string         `json:"branch,omitempty"` // Branch is only set for the branch workflows.
+		Status  string         `json:"status"`
+		Started time.Time      `json:"started"`
+	}
+)
+
+const (
+	KindRepo   Kind = "Repo"                    // KindRepo is the type of workflows.Repo.
+	KindBranch Kind = activities.WorkflowBranch // KindBranch is the type of workflows.Branch.
+	KindTrunk  Kind = activities.WorkflowTrunk  // KindTrunk is the type of workflows.Trunk.
+)
+
+var (
+	ErrNotFound = errors.New("admin: workflow not found")
+)
+
+// List returns the running workflows of every repo of the org, or all of them, including the closed ones, if all is
+// set.
+func List(ctx context.Context, org uuid.UUID, all bool) ([]*Workflow, error) {
+	repos, err := db.Queries().GetOrgReposByOrgID(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*Workflow, 0)
+
+	for idx := range repos {
+		found, err := list(ctx, &repos[idx], all)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, found...)
+	}
+
+	return result, nil
+}
+
+// Find returns the running workflow of the kind for the repo. The branch is only used for KindBranch.
+func Find(ctx context.Context, repo uuid.UUID, kind Kind, branch string) (*Workflow, error) {
+	entity, err := db.Queries().GetRepoByID(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := list(ctx, &entity, false)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, w := range found {
+		if w.Kind == kind && (kind != KindBranch || w.Branch == branch) {
+			return w, nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+// Inspect returns the state of the workflow, as returned by its query, i.e. the triggers of the repo, the status of
+// the branch or the merge queue of the trunk.
+func Inspect(ctx context.Context, w *Workflow) (any, error) {
+	var (
+		query  string
+		result any
+	)
+
+	switch w.Kind {
+	case KindRepo:
+		query, result = defs.QueryRepoForTriggers.String(), &states.BranchTriggers{}
+	case KindBranch:
+		query, result = defs.QueryBranchForStatus.String(), &defs.BranchStatus{}
+	case KindTrunk:
+		query, result = defs.QueryTrunkForQueue.String(), &defs.QueueStatus{}
+	default:
+		return nil, fmt.Errorf("admin: unknown kind %q", w.Kind)
+	}
+
+	c, err := durable.Get().Client()
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := c.QueryWorkflow(ctx, w.ID, w.RunID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := value.Get(result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Terminate terminates the run of the workflow with the reason.
+func Terminate(ctx context.Context, w *Workflow, reason string) error {
+	c, err := durable.Get().Client()
+	if err != nil {
+		return err
+	}
+
+	return c.TerminateWorkflow(ctx, w.ID, w.RunID, reason)
+}
+
+// Restart terminates the running workflow of the kind for the repo, if any, and starts it again with a fresh state.
+// A restarted repo workflow re-syncs its triggers with the provider right away, see Sync.
+func Restart(ctx context.Context, repo uuid.UUID, kind Kind, branch, reason string) (string, error) {
+	w, err := Find(ctx, repo, kind, branch)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return "", err
+	}
+
+	if w != nil {
+		if err := Terminate(ctx, w, reason); err != nil {
+			return "", err
+		}
+	}
+
+	entity, link, err := hydrate(ctx, repo)
+	if err != nil {
+		return "", err
+	}
+
+	var run client.WorkflowRun
+
+	switch kind {
+	case KindRepo:
+		run, err = durable.OnCore().SignalWithStartWorkflow(
+			ctx,
+			defs.RepoWorkflowOptions(entity),
+			defs.SignalSyncTriggers,
+			&defs.SyncTriggersPayload{Reason: reason},
+			workflows.Repo,
+			states.NewRepo(entity, link),
+		)
+	case KindBranch:
+		run, err = durable.OnCore().
+			ExecuteWorkflow(ctx, defs.BranchWorkflowOptions(entity, branch), workflows.Branch, states.NewBranch(entity, link, branch))
+	case KindTrunk:
+		run, err = durable.OnCore().ExecuteWorkflow(ctx, defs.TrunkWorkflowOptions(entity), workflows.Trunk, states.NewTrunk(entity, link))
+	default:
+		return "", fmt.Errorf("admin: unknown kind %q", kind)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return run.GetRunID(), nil
+}
+
+// Sync asks the repo workflow to re-sync its branch triggers with the branches on the provider, starting the workflow
+// if it isn't running.
+func Sync(ctx context.Context, repo uuid.UUID, reason string) error {
+	entity, link, err := hydrate(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	_, err = durable.OnCore().SignalWithStartWorkflow(
+		ctx,
+		defs.RepoWorkflowOptions(entity),
+		defs.SignalSyncTriggers,
+		&defs.SyncTriggersPayload{Reason: reason},
+		workflows.Repo,
+		states.NewRepo(entity, link),
+	)
+
+	return err
+}
+
+// - local -
+
+// list returns the workflows of the repo.
+func list(ctx context.Context, repo *entities.Repo, all bool) ([]*Workflow, error) {
+	c, err := durable.Get().Client()
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := durable.OnCore().WorkflowID(durable.NewWorkflowOptions(
+		durable.WithOrg(repo.OrgID.String()),
+		durable.WithSubject("repos"),
+		durable.WithSubjectID(repo.ID.String()),
+	)) + "."
+
+	query := fmt.Sprintf("WorkflowId STARTS_WITH %q", prefix)
+	if !all {
+		query += ` AND ExecutionStatus = "Running"`
+	}
+
+	result := make([]*Workflow, 0)
+	request := &workflowservice.ListWorkflowExecutionsRequest{Query: query}
+
+	for {
+		response, err := c.ListWorkflow(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, info := range response.GetExecutions() {
+			w := &Workflow{
+				ID:      info.GetExecution().GetWorkflowId(),
+				RunID:   info.GetExecution().GetRunId(),
+				Kind:    Kind(info.GetType().GetName()),
+				Repo:    repo,
+				Status:  info.GetStatus().String(),
+				Started: info.GetStartTime().AsTime(),
+			}
+
+			if w.Kind == KindBranch {
+				w.Branch = branch_of(strings.TrimPrefix(w.ID, prefix), repo.Name)
+			}
+
+			result = append(result, w)
+		}
+
+		if len(response.GetNextPageToken()) == 0 {
+			return result, nil
+		}
+
+		request.NextPageToken = response.GetNextPageToken()
+	}
+}
+
+// branch_of extracts the branch from the rest of the workflow ID after the repo prefix. Workflows started before the meta
+// of the ID was ordered may have the branch before the name.
+func branch_of(rest, name string) string {
+	if after, ok := strings.CutPrefix(rest, "name."+name+".branch."); ok {
+		return after
+	}
+
+	if middle, ok := strings.CutPrefix(rest, "branch."); ok {
+		return strings.TrimSuffix(middle, ".name."+name)
+	}
+
+	return ""
+}
+
+// hydrate returns the repo with its chat link. The chat link is nil if the repo isn't linked.
+func hydrate(ctx context.Context, id uuid.UUID) (*entities.Repo, *entities.ChatLink, error) {
+	repo, err := db.Queries().GetRepoByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	link, err := db.Queries().GetChatLink(ctx, repo.ID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, err
+	}
+
+	if err != nil {
+		return &repo, nil, nil
+	}
+
+	return &repo, &link, nil
+}
@@ -9,6 +9,23 @@ import (
 	"go.breu.io/quantm/internal/core/repos/workflows"
 )
 
+type (
+	// QueueStatus is the state of the merge queue returned by the trunk.
+	QueueStatus = defs.QueueStatus
+
+	// BranchStatus is the state of the branch returned by the branch.
+	BranchStatus = defs.BranchStatus
+
+	// QueueFreezePayload freezes or unfreezes the merge queue.
+	QueueFreezePayload = defs.QueueFreezePayload
+
+	// SyncTriggersPayload re-syncs the branch triggers of the repo with the provider.
+	SyncTriggersPayload = defs.SyncTriggersPayload
+
+	// BranchTriggers maps the branches tracked by the repo to their triggering event.
+	BranchTriggers = states.BranchTriggers
+)
+
 var (
 	// BranchNameFromRef extracts the branch name from a full Git reference string.
 	BranchNameFromRef = fns.BranchNameFromRef
@@ -41,6 +58,15 @@ var (
 
 	// RepoWorkflowOptions provides options for configuring the repository workflow.
 	RepoWorkflowOptions = defs.RepoWorkflowOptions
+
+	// NewTrunkWorkflowState creates a new state object for the trunk workflow.
+	NewTrunkWorkflowState = states.NewTrunk
+
+	// BranchWorkflowOptions provides options for configuring the branch workflow.
+	BranchWorkflowOptions = defs.BranchWorkflowOptions
+
+	// TrunkWorkflowOptions provides options for configuring the trunk workflow.
+	TrunkWorkflowOptions = defs.TrunkWorkflowOptions
 )
 
 var (
@@ -54,10 +80,16 @@ const (
 	SignalPullRequestReview        = defs.SignalPRReview
 	SignalPullRequestReviewComment = defs.ReviewComment
 	SignalMergeQueue               = defs.SignalMergeQueue
+	SignalChatAction               = defs.SignalChatAction
+	SignalQueueFreeze              = defs.SignalQueueFreeze
+	SignalSyncTriggers             = defs.SignalSyncTriggers
 )
 
 const (
 	QueryRepoForEventParent = defs.QueryRepoForEventParent
+	QueryTrunkForQueue      = defs.QueryTrunkForQueue
+	QueryBranchForStatus    = defs.QueryBranchForStatus
+	QueryRepoForTriggers    = defs.QueryRepoForTriggers
 )
 
 const (
@@ -74,6 +106,18 @@ func NewBranchActivities() *activities.Branch {
 	return &activities.Branch{}
 }
 
+// NewGitActivities creates the branch activities for the git queue. The worker only accepts a git session with
+// minfree bytes free on its clones disk.
+func NewGitActivities(minfree uint64) *activities.Branch {
+	return &activities.Branch{MinFreeSpace: minfree}
+}
+
 func NewNotifyActivities() *activities.Notify {
 	return &activities.Notify{}
 }
+
+// NewProviderActivities creates a new instance of the Provider activities, which perform write operations on the repo
+// provider.
+func NewProviderActivities() *activities.Provider {
+	return &activities.Provider{}
+}
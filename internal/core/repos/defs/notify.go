@@ -0,0 +1,14 @@
+package defs
+
+import (
+	"go.breu.io/quantm/internal/core/kernel"
+	eventsv1 "go.breu.io/quantm/internal/proto/ctrlplane/events/v1"
+)
+
+type (
+	// NotifyPayload is the payload to send a generic notification through a chat hook.
+	NotifyPayload struct {
+		Hook         eventsv1.ChatHook    `json:"hook"`
+		Notification *kernel.Notification `json:"notification"`
+	}
+)
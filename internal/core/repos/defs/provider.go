@@ -0,0 +1,55 @@
+package defs
+
+import (
+	"go.breu.io/quantm/internal/core/kernel"
+	"go.breu.io/quantm/internal/db/entities"
+	eventsv1 "go.breu.io/quantm/internal/proto/ctrlplane/events/v1"
+)
+
+type (
+	// CommentPayload is the payload to post a comment on a pull request.
+	CommentPayload struct {
+		Repo   *entities.Repo    `json:"repo"`
+		Hook   eventsv1.RepoHook `json:"hook"`
+		Number int64             `json:"number"`
+		Body   string            `json:"body"`
+	}
+
+	// CommitStatusPayload is the payload to set the status of a commit.
+	CommitStatusPayload struct {
+		Repo   *entities.Repo       `json:"repo"`
+		Hook   eventsv1.RepoHook    `json:"hook"`
+		Status *kernel.CommitStatus `json:"status"`
+	}
+
+	// CheckRunPayload is the payload to create or update a check run. The ID of the run is only required to update.
+	CheckRunPayload struct {
+		Repo *entities.Repo    `json:"repo"`
+		Hook eventsv1.RepoHook `json:"hook"`
+		Run  *kernel.CheckRun  `json:"run"`
+	}
+
+	// LabelPayload is the payload to add or remove labels on a pull request.
+	LabelPayload struct {
+		Repo   *entities.Repo    `json:"repo"`
+		Hook   eventsv1.RepoHook `json:"hook"`
+		Number int64             `json:"number"`
+		Labels []string          `json:"labels"`
+	}
+
+	// MergePayload is the payload to merge a pull request.
+	MergePayload struct {
+		Repo    *entities.Repo       `json:"repo"`
+		Hook    eventsv1.RepoHook    `json:"hook"`
+		Number  int64                `json:"number"`
+		Options *kernel.MergeOptions `json:"options"`
+	}
+
+	// BranchPayload is the payload to create or delete a branch. SHA is only required when creating.
+	BranchPayload struct {
+		Repo   *entities.Repo    `json:"repo"`
+		Hook   eventsv1.RepoHook `json:"hook"`
+		Branch string            `json:"branch"`
+		SHA    string            `json:"sha"`
+	}
+)
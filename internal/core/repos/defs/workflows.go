@@ -1,6 +1,8 @@
 package defs
 
 import (
+	"time"
+
 	"go.breu.io/durex/queues"
 
 	"go.breu.io/quantm/internal/db/entities"
@@ -22,10 +24,37 @@ const (
 	SignalPRReview         queues.Signal = "pr_review"         // signals a pull request review event.
 	ReviewComment          queues.Signal = "pr_review_comment" // signals a pull request review comment event.
 	SignalMergeQueue       queues.Signal = "merge_queue"       // signals a pull request queue event.
+	SignalChatAction       queues.Signal = "chat_action"       // signals an action taken on a chat notification.
+	SignalQueueFreeze      queues.Signal = "queue_freeze"      // signals the trunk to freeze or unfreeze the merge queue.
+	SignalSyncTriggers     queues.Signal = "sync_triggers"     // signals the repo to re-sync its triggers with the provider.
+)
+
+const (
+	SnoozeDuration    = 3 * 24 * time.Hour  // SnoozeDuration is how long the stale reminder is snoozed from chat.
+	StaleDigestCron   = "0 9 * * 1-5"       // StaleDigestCron sends the stale branch digest at 9am on weekdays, team's timezone.
+	StaleDigestJitter = 15 * time.Minute    // StaleDigestJitter spreads the digests of a team over a quarter of an hour.
+	StaleBranchAge    = 3 * 24 * time.Hour  // StaleBranchAge is how long a branch goes without commits before it is stale.
+	BranchIdleExpiry  = 30 * 24 * time.Hour // BranchIdleExpiry is how long a branch lives without activity before it exits.
+)
+
+// MergeQueueInFlight is the number of pull requests tested at once. The rest wait in the merge queue.
+const MergeQueueInFlight = 1
+
+// Git sessions run the clone, diff and rebase of a branch on a single git worker. The creation waits for a worker with a
+// free session, so it is given longer than the activities on the core queue. A worker without enough disk space for
+// the clone gives the session up, and the session is created again after the backoff, up to GitSessionAttempts times.
+const (
+	GitSessionExecutionTimeout = 30 * time.Minute // GitSessionExecutionTimeout is the maximum duration of a git session.
+	GitSessionCreationTimeout  = 5 * time.Minute  // GitSessionCreationTimeout is how long to wait for a free git session.
+	GitSessionAttempts         = 3                // GitSessionAttempts is the number of workers tried for a git session.
+	GitSessionBackoff          = 30 * time.Second // GitSessionBackoff is the wait before trying another git worker.
 )
 
 const (
 	QueryRepoForEventParent queues.Query = "event_parent" // query to find the parent event for the given event
+	QueryTrunkForQueue      queues.Query = "queue"        // query to get the merge queue from the trunk
+	QueryBranchForStatus    queues.Query = "status"       // query to get the status of the branch
+	QueryRepoForTriggers    queues.Query = "triggers"     // query to get the triggering event of every tracked branch
 )
 
 type (
@@ -78,8 +107,43 @@ type (
 	}
 
 	SignalQueuePayload struct{}
+
+	// QueueFreezePayload freezes or unfreezes the merge queue.
+	QueueFreezePayload struct {
+		Frozen bool   `json:"frozen"`
+		User   string `json:"user"` // User is the chat platform ID of the user who requested the change.
+	}
+
+	// SyncTriggersPayload asks the repo to re-sync its branch triggers with the branches on the provider.
+	SyncTriggersPayload struct {
+		Reason string `json:"reason"` // Reason is logged by the workflow, e.g. who asked for the sync.
+	}
+
+	// QueueStatus is the state of the merge queue, as returned by QueryTrunkForQueue.
+	QueueStatus struct {
+		Frozen   bool                   `json:"frozen"`
+		Items    []*eventsv1.MergeQueue `json:"items"`    // Items waiting in the queue, in order.
+		InFlight []*eventsv1.MergeQueue `json:"inflight"` // InFlight are the items being merged.
+	}
+
+	// BranchStatus is the state of the branch, as returned by QueryBranchForStatus.
+	BranchStatus struct {
+		Branch       string           `json:"branch"`
+		LatestCommit *eventsv1.Commit `json:"latest_commit"`
+	}
 )
 
+// Position returns the position of the branch in the queue, starting from 1. Returns 0 if the branch is not queued.
+func (q *QueueStatus) Position(branch string) int {
+	for idx, item := range q.Items {
+		if item.GetBranch() == branch {
+			return idx + 1
+		}
+	}
+
+	return 0
+}
+
 // Sum returns the sum of added and removed lines.
 func (d *DiffLines) Sum() int {
 	return d.Added + d.Removed
@@ -7,7 +7,11 @@ import (
 	"go.temporal.io/sdk/log"
 	"go.temporal.io/sdk/workflow"
 
+	"go.breu.io/quantm/internal/core/kernel"
+	"go.breu.io/quantm/internal/core/repos/activities"
+	"go.breu.io/quantm/internal/core/repos/defs"
 	"go.breu.io/quantm/internal/db/entities"
+	eventsv1 "go.breu.io/quantm/internal/proto/ctrlplane/events/v1"
 )
 
 type (
@@ -16,6 +20,7 @@ type (
 	Base struct {
 		Repo     *entities.Repo     `json:"repo"`      // Repository entity.
 		ChatLink *entities.ChatLink `json:"chat_link"` // ChatLink entity.
+		Schema   workflow.Version   `json:"schema"`    // Schema is the version of the state, see migrate.
 
 		logger log.Logger // Workflow logger.
 	}
@@ -48,6 +53,17 @@ func (state *Base) run(ctx workflow.Context, action string, activity, event, res
 	return nil
 }
 
+// send sends the notification through the chat hook with Notify.Send. Threaded notifications about the same subject
+// live in one conversation.
+func (state *Base) send(ctx workflow.Context, name string, hook eventsv1.ChatHook, notification *kernel.Notification) {
+	payload := &defs.NotifyPayload{Hook: hook, Notification: notification}
+	notify := &activities.Notify{}
+
+	if err := state.run(ctx, name, notify.Send, payload, nil); err != nil {
+		state.logger.Error(name+": unable to to send", "error", err.Error())
+	}
+}
+
 // - public
 
 // RestartRecommended checks if the workflow should be continued as new.
@@ -7,6 +7,7 @@ import (
 	"github.com/google/uuid"
 	"go.temporal.io/sdk/workflow"
 
+	"go.breu.io/quantm/internal/core/kernel"
 	"go.breu.io/quantm/internal/core/repos/activities"
 	"go.breu.io/quantm/internal/core/repos/cast"
 	"go.breu.io/quantm/internal/core/repos/defs"
@@ -23,6 +24,7 @@ type (
 	BranchIntervals struct {
 		pr    periodic.Interval // used to send a notifcation if a pr is not opened within a certain time.
 		stale periodic.Interval // used to send a notification if a branch is stale.
+		idle  periodic.Interval // used to end the branch if there is no activity, see Expires.
 	}
 
 	Branch struct {
@@ -30,11 +32,21 @@ type (
 
 		Branch       string           `json:"branch"`
 		LatestCommit *eventsv1.Commit `json:"latest_commit"`
+		Timezone     string           `json:"timezone"`     // Timezone of the team, once resolved, for the stale digest.
+		Conflicted   bool             `json:"conflicted"`   // Conflicted is true if the last rebase had conflicts.
+		Author       uuid.UUID        `json:"author"`       // Author is the user who pushed last, if known, for the stale digest.
+		PullRequest  int64            `json:"pull_request"` // PullRequest is the number of the pull request, once known.
+		Trunk        string           `json:"trunk"`        // Trunk is the head of the default branch at the last rebase.
 
 		intervals BranchIntervals
 		do        *activities.Branch
 		notify    *activities.Notify
 		done      bool
+		digest    bool // digest is true if the stale interval follows the team's working hours.
+		expires   bool // expires is true if the branch ends when deleted or idle, see ChangeBranchLifecycle.
+		git       bool // git is true if the git sessions run on the git queue, see ChangeGitQueue.
+		threaded  bool // threaded is true if the notifications are sent with Notify.Send, see ChangeThreadedNotify.
+		statuses  bool // statuses is true if the status of the thread is updated once resolved, see ChangeThreadStatus.
 	}
 )
 
@@ -51,19 +63,30 @@ func (state *Branch) PullRequestMonitor(ctx workflow.Context) {
 	})
 }
 
-// StaleMonitor is a goroutine that monitors the branch for staleness. If the branch is stale, a notification is
-// sent to the hook associated with the branch.
-//
-// TODO: implement the logic for sending a notification if the branch is stale.
+// StaleMonitor is a goroutine that monitors the branch for staleness. If the branch is stale, the digest is sent to
+// the author of the branch, see stale_digest.
 func (state *Branch) StaleMonitor(ctx workflow.Context) {
 	workflow.Go(ctx, func(ctx_ workflow.Context) {
 		for {
 			state.intervals.stale.Tick(ctx_)
-			_ = state.notify_user(ctx)
+			state.stale_digest(ctx_)
 		}
 	})
 }
 
+// IdleMonitor is a goroutine that waits for the branch to be idle for defs.BranchIdleExpiry. The returned channel
+// receives once the branch is idle, and must be handled with OnIdle.
+func (state *Branch) IdleMonitor(ctx workflow.Context) workflow.ReceiveChannel {
+	idle := workflow.NewChannel(ctx)
+
+	workflow.Go(ctx, func(ctx_ workflow.Context) {
+		state.intervals.idle.Tick(ctx_)
+		idle.Send(ctx_, true)
+	})
+
+	return idle
+}
+
 // OnPush resets the stale timer and processes the push event. The repo is cloned, the diff calculated, and
 // notifications sent if change complexity warrants. Author notification is prioritized, falling back to
 // the repo's chat hook.
@@ -71,12 +94,14 @@ func (state *Branch) OnPush(ctx workflow.Context) durable.ChannelHandler {
 	return func(ch workflow.ReceiveChannel, more bool) {
 		event := &events.Event[eventsv1.RepoHook, eventsv1.Push]{}
 		state.rx(ctx, ch, event)
+		state.touch(ctx)
 
 		state.intervals.stale.Reset(ctx)
+		state.localize(ctx, event.Subject.TeamID)
 
-		opts := &workflow.SessionOptions{ExecutionTimeout: time.Minute * 30, CreationTimeout: time.Second * 30}
+		clone := &defs.ClonePayload{Repo: state.Repo, Hook: event.Context.Hook, Branch: state.Branch, SHA: event.Payload.After}
 
-		session, err := workflow.CreateSession(ctx, opts)
+		session, err := state.session(ctx, clone)
 		if err != nil {
 			state.logger.Error("clone: unable to create session", "push", event.Payload.After, "error", err.Error())
 			return
@@ -86,13 +111,18 @@ func (state *Branch) OnPush(ctx workflow.Context) durable.ChannelHandler {
 
 		state.LatestCommit = fns.GetLatestCommit(event.Payload)
 
-		clone := &defs.ClonePayload{Repo: state.Repo, Hook: event.Context.Hook, Branch: state.Branch, SHA: event.Payload.After}
+		if event.Subject.UserID != uuid.Nil {
+			state.Author = event.Subject.UserID
+		}
+
+		host, rest := state.scopes(ctx, session)
+
 		path := state.clone(session, clone)
 		diff := state.diff(session, path, state.Repo.DefaultBranch, event.Payload.After)
-		state.remove_dir(ctx, path)
+		state.remove_dir(host, path)
 
 		// compare the diff
-		state.compare_diff(session, event, diff)
+		state.compare_diff(rest, event, diff)
 	}
 }
 
@@ -103,26 +133,39 @@ func (state *Branch) OnRebase(ctx workflow.Context) durable.ChannelHandler {
 		event := &events.Event[eventsv1.RepoHook, eventsv1.Rebase]{}
 		state.rx(ctx, ch, event)
 
-		opts := &workflow.SessionOptions{ExecutionTimeout: time.Minute * 30, CreationTimeout: time.Second * 30}
+		state.Trunk = event.Payload.GetHead()
+		state.rebase(ctx, event)
+	}
+}
 
-		session, err := workflow.CreateSession(ctx, opts)
-		if err != nil {
-			state.logger.Error("clone: unable to create session", "rebase", event.Payload.Head, "error", err.Error())
-			return
-		}
+// rebase creates a session, clones the repository at the head of the rebase event, attempts the rebase, notifies on
+// conflicts or once they are resolved, and removes the cloned repository.
+func (state *Branch) rebase(ctx workflow.Context, event *events.Event[eventsv1.RepoHook, eventsv1.Rebase]) {
+	clone := &defs.ClonePayload{Repo: state.Repo, Hook: event.Context.Hook, Branch: state.Branch, SHA: event.Payload.Head}
 
-		defer workflow.CompleteSession(session)
+	session, err := state.session(ctx, clone)
+	if err != nil {
+		state.logger.Error("clone: unable to create session", "rebase", event.Payload.Head, "error", err.Error())
+		return
+	}
 
-		clone := &defs.ClonePayload{Repo: state.Repo, Hook: event.Context.Hook, Branch: state.Branch, SHA: event.Payload.Head}
-		path := state.clone(session, clone)
+	defer workflow.CompleteSession(session)
 
-		rebase := &defs.RebaseResult{}
-		_ = state.run(ctx, "rebase", state.do.Rebase, &defs.RebasePayload{Rebase: event.Payload, Path: path}, rebase)
+	host, rest := state.scopes(ctx, session)
 
-		state.check_merge_conflict(session, event, rebase)
+	path := state.clone(session, clone)
 
-		state.remove_dir(ctx, path)
+	rebase := &defs.RebaseResult{}
+	err = state.run(host, "rebase", state.do.Rebase, &defs.RebasePayload{Rebase: event.Payload, Path: path}, rebase)
+
+	state.check_merge_conflict(rest, event, rebase)
+
+	// a rebase that failed for any other reason, e.g. the base could not be fetched, says nothing about the conflicts.
+	if err == nil && (rebase.Status == defs.RebaseStatusSuccess || rebase.Status == defs.RebaseStatusUpToDate) {
+		state.resolve_conflict(rest, event)
 	}
+
+	state.remove_dir(host, path)
 }
 
 // OnLabel handles pull request label events.
@@ -130,6 +173,8 @@ func (state *Branch) OnLabel(ctx workflow.Context) durable.ChannelHandler {
 	return func(rx workflow.ReceiveChannel, more bool) {
 		event := &events.Event[eventsv1.RepoHook, eventsv1.PullRequestLabel]{}
 		state.rx(ctx, rx, event)
+		state.touch(ctx)
+		state.track(event.Payload.GetNumber())
 
 		switch event.Payload.Name {
 		case "qmerge":
@@ -147,6 +192,8 @@ func (state *Branch) OnPrReview(ctx workflow.Context) durable.ChannelHandler {
 	return func(rx workflow.ReceiveChannel, more bool) {
 		event := &events.Event[eventsv1.RepoHook, eventsv1.PullRequestReview]{}
 		state.rx(ctx, rx, event)
+		state.touch(ctx)
+		state.track(event.Payload.GetPullRequestNumber())
 	}
 }
 
@@ -155,22 +202,252 @@ func (state *Branch) OnPRReviewComment(ctx workflow.Context) durable.ChannelHand
 	return func(rx workflow.ReceiveChannel, more bool) {
 		event := &events.Event[eventsv1.RepoHook, eventsv1.PullRequestReview]{}
 		state.rx(ctx, rx, event)
+		state.touch(ctx)
+	}
+}
+
+// OnChatAction handles the actions taken by users on the chat notifications for the branch.
+func (state *Branch) OnChatAction(ctx workflow.Context) durable.ChannelHandler {
+	return func(rx workflow.ReceiveChannel, more bool) {
+		action := &kernel.ChatAction{}
+		state.rx(ctx, rx, action)
+		state.touch(ctx)
+
+		switch action.Kind {
+		case kernel.ChatActionRebase:
+			state.rebase_now(ctx, action)
+		case kernel.ChatActionSnooze:
+			state.intervals.stale.Delay(ctx, defs.SnoozeDuration)
+		case kernel.ChatActionDismiss:
+			state.logger.Info("chat_action: dismissed", "branch", state.Branch, "user", action.User)
+		case kernel.ChatActionEnqueue: // enqueue is routed to the trunk by the repo.
+			state.logger.Warn("chat_action: enqueue must be sent to the trunk", "branch", state.Branch)
+		default:
+			state.logger.Warn("chat_action: unsupported action", "branch", state.Branch, "action", action.Kind)
+		}
+	}
+}
+
+// OnRef handles the creation and the deletion of the branch. The branch ends once deleted, after the signals already
+// received are handled. A branch created again before that keeps running.
+func (state *Branch) OnRef(ctx workflow.Context) durable.ChannelHandler {
+	return func(rx workflow.ReceiveChannel, more bool) {
+		ref := &events.Event[eventsv1.RepoHook, eventsv1.GitRef]{}
+		state.rx(ctx, rx, ref)
+
+		switch ref.Context.Action {
+		case events.ActionDeleted:
+			state.logger.Info("ref: branch deleted, exiting", "branch", state.Branch)
+			state.done = true
+		case events.ActionCreated:
+			state.done = false
+			state.touch(ctx)
+		}
 	}
 }
 
+// OnIdle ends the branch once it has been idle for defs.BranchIdleExpiry. The workflow is started again by the next
+// event forwarded to the branch.
+func (state *Branch) OnIdle(ctx workflow.Context) durable.ChannelHandler {
+	return func(rx workflow.ReceiveChannel, more bool) {
+		rx.Receive(ctx, nil)
+
+		state.logger.Info("idle: no activity, exiting", "branch", state.Branch, "expiry", defs.BranchIdleExpiry)
+		state.done = true
+	}
+}
+
+// QueryStatus returns the current status of the branch.
+func (state *Branch) QueryStatus() (*defs.BranchStatus, error) {
+	return &defs.BranchStatus{Branch: state.Branch, LatestCommit: state.LatestCommit}, nil
+}
+
 // ExitLoop returns true if the branch should exit the event loop.
 func (state *Branch) ExitLoop(ctx workflow.Context) bool {
 	return state.done || workflow.GetInfo(ctx).GetContinueAsNewSuggested()
 }
 
+// Pending returns true if the selector has signals to handle before the branch exits the event loop. Runs recorded
+// before ChangeBranchLifecycle exit without them.
+func (state *Branch) Pending(selector workflow.Selector) bool {
+	return state.expires && selector.HasPending()
+}
+
+// Expires returns true if the branch ends when it is deleted or idle.
+func (state *Branch) Expires() bool {
+	return state.expires
+}
+
+// Done returns true if the branch has ended, and must not continue as new.
+func (state *Branch) Done() bool {
+	return state.done
+}
+
 // Init initializes the branch state.
 func (state *Branch) Init(ctx workflow.Context) {
 	state.Base.Init(ctx)
+	state.migrate(ctx)
 
 	pr := periodic.New(ctx, time.Minute*60*24)
-	stale := periodic.New(ctx, time.Minute*60*24)
+
+	var stale periodic.Interval
+
+	if workflow.GetVersion(ctx, ChangeStaleDigest, workflow.DefaultVersion, 1) == workflow.DefaultVersion {
+		stale = periodic.New(ctx, time.Minute*60*24)
+	} else {
+		state.digest = true
+		stale = periodic.NewSchedule(ctx, state.digest_schedule(), periodic.WithJitter(defs.StaleDigestJitter))
+	}
 
 	state.intervals = BranchIntervals{pr: pr, stale: stale}
+
+	if workflow.GetVersion(ctx, ChangeBranchLifecycle, workflow.DefaultVersion, 1) != workflow.DefaultVersion {
+		state.expires = true
+		state.intervals.idle = periodic.New(ctx, defs.BranchIdleExpiry)
+	}
+
+	if workflow.GetVersion(ctx, ChangeGitQueue, workflow.DefaultVersion, 1) != workflow.DefaultVersion {
+		state.git = true
+	}
+
+	if workflow.GetVersion(ctx, ChangeThreadedNotify, workflow.DefaultVersion, 1) != workflow.DefaultVersion {
+		state.threaded = true
+	}
+
+	if workflow.GetVersion(ctx, ChangeThreadStatus, workflow.DefaultVersion, 1) != workflow.DefaultVersion {
+		state.statuses = true
+	}
+}
+
+// session creates the session the clone of a push or a rebase, and the git activities on it, run in. The session is
+// created on the git queue, and given up for another worker if the worker doesn't have enough disk space for the clone.
+// Runs recorded before ChangeGitQueue create the session on the core queue, without the check.
+func (state *Branch) session(ctx workflow.Context, clone *defs.ClonePayload) (workflow.Context, error) {
+	if !state.git {
+		return workflow.CreateSession(ctx, &workflow.SessionOptions{ExecutionTimeout: time.Minute * 30, CreationTimeout: time.Second * 30})
+	}
+
+	ctx = workflow.WithTaskQueue(ctx, durable.QueueGit)
+	opts := &workflow.SessionOptions{ExecutionTimeout: defs.GitSessionExecutionTimeout, CreationTimeout: defs.GitSessionCreationTimeout}
+
+	for attempt := 1; ; attempt++ {
+		session, err := workflow.CreateSession(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		host := workflow.GetSessionInfo(session).HostName
+
+		err = state.run(session, "check_disk_space", state.do.CheckDiskSpace, clone, nil, "host", host, "attempt", attempt)
+		if err == nil {
+			return session, nil
+		}
+
+		workflow.CompleteSession(session)
+
+		if attempt >= defs.GitSessionAttempts {
+			return nil, err
+		}
+
+		state.logger.Warn("session: worker rejected the session, retrying ...", "host", host, "attempt", attempt)
+
+		if err := workflow.Sleep(ctx, defs.GitSessionBackoff); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// scopes returns the contexts of the activities that must run on the host of the clone, and of the rest, e.g. the
+// notifications, which aren't registered on the git queue. Runs recorded before ChangeGitQueue ran the former outside
+// of the session, and the latter in it.
+func (state *Branch) scopes(ctx, session workflow.Context) (workflow.Context, workflow.Context) {
+	if !state.git {
+		return ctx, session
+	}
+
+	return session, ctx
+}
+
+// track keeps the number of the pull request of the branch, so that the alerts can add it to the merge queue.
+func (state *Branch) track(number int64) {
+	if number != 0 {
+		state.PullRequest = number
+	}
+}
+
+// touch restarts the idle expiry on activity. Rebases don't count, as they follow the activity on the default branch.
+func (state *Branch) touch(ctx workflow.Context) {
+	if state.expires {
+		state.intervals.idle.Reset(ctx)
+	}
+}
+
+// localize resolves the timezone of the team on the first push, and moves the stale digest to the team's working
+// hours.
+func (state *Branch) localize(ctx workflow.Context, team uuid.UUID) {
+	if !state.digest || state.Timezone != "" || team == uuid.Nil {
+		return
+	}
+
+	tz := ""
+	if err := state.run(ctx, "timezone", state.do.Timezone, team, &tz); err != nil || tz == "" {
+		state.logger.Warn("localize: unable to resolve timezone", "team", team)
+		return
+	}
+
+	state.Timezone = tz
+	state.intervals.stale.Reschedule(ctx, state.digest_schedule())
+}
+
+// digest_schedule returns the schedule of the stale digest in the timezone of the team, falling back to UTC.
+func (state *Branch) digest_schedule() periodic.Schedule {
+	loc, err := time.LoadLocation(state.Timezone)
+	if err != nil {
+		state.logger.Warn("digest: unknown timezone, using UTC", "timezone", state.Timezone)
+
+		loc = time.UTC
+	}
+
+	schedule, err := periodic.Cron(defs.StaleDigestCron, loc)
+	if err != nil {
+		state.logger.Warn("digest: invalid schedule, using daily interval", "error", err.Error())
+
+		return periodic.Every(time.Minute * 60 * 24)
+	}
+
+	return schedule
+}
+
+// rebase_now rebases the branch on the default branch, without waiting for the next push on the default branch. The
+// rebase is built the same way as the rebases of the repo on a push to the default branch, with the head of the default
+// branch seen on the last rebase, falling back to the default branch itself if the branch hasn't been rebased yet.
+func (state *Branch) rebase_now(ctx workflow.Context, action *kernel.ChatAction) {
+	if state.LatestCommit == nil {
+		state.logger.Warn("rebase_now: no commits on the branch yet", "branch", state.Branch, "user", action.User)
+		return
+	}
+
+	head := state.Trunk
+	if head == "" {
+		head = state.Repo.DefaultBranch
+	}
+
+	rebase := events.
+		New[eventsv1.RepoHook, eventsv1.Rebase]().
+		SetHook(eventsv1.RepoHook(state.Repo.Hook)).
+		SetScope(events.ScopeRebase).
+		SetAction(events.ActionRequested).
+		SetSource(state.Repo.Url).
+		SetSubjectName(events.SubjectNameRepos).
+		SetSubjectID(state.Repo.ID).
+		SetOrg(state.Repo.OrgID).
+		SetPayload(&eventsv1.Rebase{Base: state.Branch, Head: head, Repository: state.Repo.Name})
+
+	if err := pulse.Persist(ctx, rebase); err != nil {
+		state.logger.Warn("rebase_now: unable to persist rebase event", "branch", state.Branch, "error", err.Error())
+	}
+
+	state.rebase(ctx, rebase)
 }
 
 // clone clones the repository at the given SHA using a Temporal activity.  A UUID is generated for the clone path via SideEffect
@@ -222,9 +499,20 @@ func (state *Branch) compare_diff(
 			)
 		}
 
-		if err := state.run(ctx, "line_exceed", state.notify.LinesExceeded, event, nil); err != nil {
-			state.logger.Error("lines_exceed: unable to to send", "error", err.Error())
+		if !state.threaded {
+			if err := state.run(ctx, "line_exceed", state.notify.LinesExceeded, event, nil); err != nil {
+				state.logger.Error("lines_exceed: unable to to send", "error", err.Error())
+			}
+
+			return
 		}
+
+		notification := kernel.LinesExceededNotification(event).
+			SetThread(kernel.BranchThread(state.Repo.ID, state.Branch)).
+			SetStatus(fmt.Sprintf("%d lines changed, threshold is %d", dlt, state.Repo.Threshold))
+
+		state.alert(notification, kernel.ChatActionEnqueue, kernel.ChatActionDismiss)
+		state.send(ctx, "line_exceed", event.Context.Hook, notification)
 	}
 }
 
@@ -233,6 +521,8 @@ func (state *Branch) check_merge_conflict(
 	ctx workflow.Context, rebase *events.Event[eventsv1.RepoHook, eventsv1.Rebase], res *defs.RebaseResult,
 ) {
 	if len(res.Conflicts) > 0 {
+		state.Conflicted = true
+
 		// check the repo's connected chat or user's connected chat.
 		hook := int32(eventsv1.ChatHook_CHAT_HOOK_SLACK)
 
@@ -253,10 +543,88 @@ func (state *Branch) check_merge_conflict(
 			)
 		}
 
-		if err := state.run(ctx, "merge_conflict", state.notify.MergeConflict, event, nil); err != nil {
-			state.logger.Error("merge_conflict: unable to to send", "error", err.Error())
+		if !state.threaded {
+			if err := state.run(ctx, "merge_conflict", state.notify.MergeConflict, event, nil); err != nil {
+				state.logger.Error("merge_conflict: unable to to send", "error", err.Error())
+			}
+
+			return
+		}
+
+		notification := kernel.MergeConflictNotification(event).
+			SetThread(kernel.BranchThread(state.Repo.ID, state.Branch)).
+			SetStatus(fmt.Sprintf("merge conflict in %d file(s)", len(res.Conflicts)))
+
+		state.alert(notification, kernel.ChatActionRebase, kernel.ChatActionDismiss)
+		state.send(ctx, "merge_conflict", event.Context.Hook, notification)
+	}
+}
+
+// alert adds the chat actions of the kinds to the alert about the branch. The pull request can only be added to the
+// merge queue once its number is known.
+func (state *Branch) alert(notification *kernel.Notification, kinds ...kernel.ChatActionKind) {
+	for _, kind := range kinds {
+		action := kernel.NewChatAction(kind, state.Repo.ID, state.Branch)
+
+		if kind == kernel.ChatActionEnqueue {
+			if state.PullRequest == 0 {
+				continue
+			}
+
+			action.Number = state.PullRequest
 		}
+
+		notification.AddChatAction(action)
+	}
+}
+
+// resolve_conflict updates the thread of the branch once it rebases cleanly after a conflict. Runs recorded before
+// ChangeThreadStatus leave the thread as is.
+func (state *Branch) resolve_conflict(ctx workflow.Context, rebase *events.Event[eventsv1.RepoHook, eventsv1.Rebase]) {
+	if !state.Conflicted {
+		return
+	}
+
+	state.Conflicted = false
+
+	if !state.statuses {
+		return
+	}
+
+	notification := kernel.StatusNotification(
+		kernel.RecipientFromSubject(rebase.Subject),
+		kernel.BranchThread(state.Repo.ID, state.Branch),
+		kernel.SeveritySuccess,
+		"conflicts resolved",
+	)
+
+	state.send(ctx, "conflicts_resolved", eventsv1.ChatHook_CHAT_HOOK_SLACK, notification)
+}
+
+// stale_digest sends the stale branch digest once the branch has had no commits for defs.StaleBranchAge. The digest
+// goes to the author of the branch, falling back to the channel linked with the repo. Runs recorded before
+// ChangeStaleDigest don't send the digest.
+func (state *Branch) stale_digest(ctx workflow.Context) {
+	if !state.digest || state.LatestCommit == nil {
+		return
+	}
+
+	idle := workflow.Now(ctx).Sub(state.LatestCommit.GetTimestamp().AsTime())
+	if idle < defs.StaleBranchAge {
+		return
 	}
+
+	recipient := kernel.Recipient{Kind: kernel.RecipientChannel, ID: state.Repo.ID}
+	if state.Author != uuid.Nil {
+		recipient = kernel.Recipient{Kind: kernel.RecipientUser, ID: state.Author}
+	}
+
+	notification := kernel.StaleBranchNotification(recipient, state.Repo.Url, state.Branch, idle).
+		SetThread(kernel.BranchThread(state.Repo.ID, state.Branch)).
+		SetStatus(fmt.Sprintf("no commits for %d days", int(idle.Hours()/24)))
+
+	state.alert(notification, kernel.ChatActionRebase, kernel.ChatActionSnooze, kernel.ChatActionDismiss)
+	state.send(ctx, "stale_branch", eventsv1.ChatHook_CHAT_HOOK_SLACK, notification)
 }
 
 func (state *Branch) notify_user(_ workflow.Context) error { return nil }
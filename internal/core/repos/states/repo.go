@@ -2,12 +2,15 @@ package states
 
 import (
 	"errors"
+	"slices"
 
 	"github.com/google/uuid"
 	"go.breu.io/durex/dispatch"
 	"go.breu.io/durex/queues"
 	"go.temporal.io/sdk/workflow"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"go.breu.io/quantm/internal/core/kernel"
 	"go.breu.io/quantm/internal/core/repos/activities"
 	"go.breu.io/quantm/internal/core/repos/defs"
 	"go.breu.io/quantm/internal/core/repos/fns"
@@ -25,7 +28,8 @@ type (
 		*Base    `json:"base"`  // Base workflow state.
 		Triggers BranchTriggers `json:"triggers"` // Branch triggers.
 
-		do *activities.Repo
+		do       *activities.Repo
+		provider *activities.Provider
 	}
 )
 
@@ -56,6 +60,8 @@ func (state *Repo) OnPush(ctx workflow.Context) durable.ChannelHandler {
 	}
 }
 
+// OnRef handles the creation and the deletion of branches. The ref is forwarded to the branch, which exits once the
+// branch is deleted.
 func (state *Repo) OnRef(ctx workflow.Context) durable.ChannelHandler {
 	return func(rx workflow.ReceiveChannel, more bool) {
 		ref := &events.Event[eventsv1.RepoHook, eventsv1.GitRef]{}
@@ -64,7 +70,7 @@ func (state *Repo) OnRef(ctx workflow.Context) durable.ChannelHandler {
 		if ref.Payload.Kind == "branch" {
 			branch := fns.BranchNameFromRef(ref.Payload.Ref)
 
-			if err := state.forward_to_branch(ctx, defs.SignalRef, branch, ref); err != nil {
+			if err := state.forward_ref(ctx, branch, ref); err != nil {
 				state.logger.Warn("ref: unable to signal branch", "repo", state.Repo.ID, "branch", branch, "error", err.Error())
 			}
 
@@ -79,11 +85,33 @@ func (state *Repo) OnRef(ctx workflow.Context) durable.ChannelHandler {
 	}
 }
 
-// OnPR handles the pull request event on the repository.
+// OnPR handles the pull request event on the repository. A pull request closed or merged against the default branch is
+// forwarded to the trunk, so that it leaves the merge queue.
 func (state *Repo) OnPR(ctx workflow.Context) durable.ChannelHandler {
 	return func(rx workflow.ReceiveChannel, more bool) {
 		pr := &events.Event[eventsv1.RepoHook, eventsv1.PullRequest]{}
 		state.rx(ctx, rx, pr)
+
+		closed := pr.Context.Action == events.ActionClosed || pr.Context.Action == events.ActionMerged
+		if !closed || pr.Payload.GetBaseBranch() != state.Repo.DefaultBranch {
+			return
+		}
+
+		if workflow.GetVersion(ctx, ChangeClosedPR, workflow.DefaultVersion, 1) == workflow.DefaultVersion {
+			return
+		}
+
+		mq := events.
+			Next[eventsv1.RepoHook, eventsv1.PullRequest, eventsv1.MergeQueue](pr, events.ScopeMergeQueue, events.ActionClosed).
+			SetPayload(&eventsv1.MergeQueue{
+				Number:    pr.Payload.GetNumber(),
+				Branch:    pr.Payload.GetHeadBranch(),
+				Timestamp: timestamppb.New(workflow.Now(ctx)),
+			})
+
+		if err := state.forward_to_trunk(ctx, defs.SignalMergeQueue, mq); err != nil {
+			state.logger.Warn("pr: unable to signal trunk", "repo", state.Repo.ID, "number", pr.Payload.GetNumber(), "error", err.Error())
+		}
 	}
 }
 
@@ -112,6 +140,78 @@ func (state *Repo) OnMergeQueue(ctx workflow.Context) durable.ChannelHandler {
 	}
 }
 
+// OnChatAction handles the actions taken by users on chat notifications. Enqueue requests are turned into merge queue
+// events for the trunk, everything else is forwarded to the branch.
+func (state *Repo) OnChatAction(ctx workflow.Context) durable.ChannelHandler {
+	return func(rx workflow.ReceiveChannel, more bool) {
+		action := &kernel.ChatAction{}
+		state.rx(ctx, rx, action)
+
+		if action.Kind == kernel.ChatActionEnqueue {
+			state.enqueue(ctx, action)
+
+			return
+		}
+
+		if err := state.forward_to_branch(ctx, defs.SignalChatAction, action.Branch, action); err != nil {
+			state.logger.Warn(
+				"chat_action: unable to signal branch",
+				"repo", state.Repo.ID, "branch", action.Branch, "action", action.Kind, "error", err.Error(),
+			)
+		}
+	}
+}
+
+// OnSyncTriggers re-syncs the triggers with the branches on the provider, e.g. after the workflow was restarted with
+// a fresh state, or missed the ref events while it was wedged. The triggers of the branches gone from the provider are
+// removed. A branch created event is persisted for every untracked branch, and used as its trigger.
+func (state *Repo) OnSyncTriggers(ctx workflow.Context) durable.ChannelHandler {
+	return func(rx workflow.ReceiveChannel, more bool) {
+		sync := &defs.SyncTriggersPayload{}
+		state.rx(ctx, rx, sync)
+
+		state.logger.Info("sync_triggers: syncing", "repo", state.Repo.ID, "reason", sync.Reason)
+
+		branches := make([]string, 0)
+		payload := &defs.BranchPayload{Repo: state.Repo, Hook: eventsv1.RepoHook(state.Repo.Hook)}
+
+		if err := state.run(ctx, "list_branches", state.provider.ListBranches, payload, &branches); err != nil {
+			state.logger.Warn("sync_triggers: unable to list branches", "repo", state.Repo.ID, "error", err.Error())
+			return
+		}
+
+		for branch := range state.Triggers {
+			if !slices.Contains(branches, branch) {
+				state.Triggers.remove(branch)
+			}
+		}
+
+		for _, branch := range branches {
+			if _, ok := state.Triggers.get(branch); ok || branch == state.Repo.DefaultBranch {
+				continue
+			}
+
+			ref := events.
+				New[eventsv1.RepoHook, eventsv1.GitRef]().
+				SetHook(eventsv1.RepoHook(state.Repo.Hook)).
+				SetScope(events.ScopeBranch).
+				SetAction(events.ActionCreated).
+				SetSource(state.Repo.Url).
+				SetSubjectName(events.SubjectNameRepos).
+				SetSubjectID(state.Repo.ID).
+				SetOrg(state.Repo.OrgID).
+				SetPayload(&eventsv1.GitRef{Ref: fns.BranchNameToRef(branch), Kind: "branch"})
+
+			if err := pulse.Persist(ctx, ref); err != nil {
+				state.logger.Warn("sync_triggers: unable to persist ref event", "repo", state.Repo.ID, "branch", branch, "error", err.Error())
+				continue
+			}
+
+			state.Triggers.add(branch, ref.ID)
+		}
+	}
+}
+
 // - query handlers -
 
 // QueryBranchTrigger queries the parent branch for the specified branch.
@@ -124,6 +224,11 @@ func (state *Repo) QueryBranchTrigger(branch string) (uuid.UUID, error) {
 	return uuid.Nil, errors.New("branch not found")
 }
 
+// QueryTriggers returns the triggering event of every tracked branch.
+func (state *Repo) QueryTriggers() (BranchTriggers, error) {
+	return state.Triggers, nil
+}
+
 // - local -
 
 // forward_to_branch routes the signal to the appropriate branch.
@@ -136,6 +241,20 @@ func (state *Repo) forward_to_branch(ctx workflow.Context, signal queues.Signal,
 	return workflow.ExecuteActivity(ctx, state.do.ForwardToBranch, payload, event, next).Get(ctx, nil)
 }
 
+// forward_ref routes the ref to the branch. A deleted branch is only signaled if its workflow is running, so that the
+// deletion doesn't start a workflow just for it to exit.
+func (state *Repo) forward_ref(ctx workflow.Context, branch string, ref *events.Event[eventsv1.RepoHook, eventsv1.GitRef]) error {
+	if ref.Context.Action != events.ActionDeleted ||
+		workflow.GetVersion(ctx, ChangeBranchLifecycle, workflow.DefaultVersion, 1) == workflow.DefaultVersion {
+		return state.forward_to_branch(ctx, defs.SignalRef, branch, ref)
+	}
+
+	ctx = dispatch.WithDefaultActivityContext(ctx)
+	payload := &defs.SignalBranchPayload{Signal: defs.SignalRef, Repo: state.Repo, Branch: branch}
+
+	return workflow.ExecuteActivity(ctx, state.do.SignalBranch, payload, ref).Get(ctx, nil)
+}
+
 // forward_to_trunk routes the signal to the trunk.
 func (state *Repo) forward_to_trunk(ctx workflow.Context, signal queues.Signal, event any) error {
 	ctx = dispatch.WithDefaultActivityContext(ctx)
@@ -168,14 +287,61 @@ func (state *Repo) attempt_rebase(ctx workflow.Context, push *events.Event[event
 	}
 }
 
+// enqueue adds the pull request in the chat action to the merge queue.
+func (state *Repo) enqueue(ctx workflow.Context, action *kernel.ChatAction) {
+	mq := events.
+		New[eventsv1.RepoHook, eventsv1.MergeQueue]().
+		SetHook(eventsv1.RepoHook(state.Repo.Hook)).
+		SetScope(events.ScopeMergeQueue).
+		SetAction(events.EventActionAdded).
+		SetSource(state.Repo.Url).
+		SetSubjectName(events.SubjectNameRepos).
+		SetSubjectID(state.Repo.ID).
+		SetOrg(state.Repo.OrgID).
+		SetPayload(&eventsv1.MergeQueue{
+			Number:    action.Number,
+			Branch:    action.Branch,
+			Timestamp: timestamppb.New(workflow.Now(ctx)),
+		})
+
+	if id, ok := state.Triggers.get(action.Branch); ok {
+		mq.SetParents(id)
+	}
+
+	if err := pulse.Persist(ctx, mq); err != nil {
+		state.logger.Warn("enqueue: unable to persist merge queue event", "repo", state.Repo.ID, "error", err.Error())
+	}
+
+	if err := state.forward_to_trunk(ctx, defs.SignalMergeQueue, mq); err != nil {
+		state.logger.Warn("enqueue: unable to signal trunk", "repo", state.Repo.ID, "branch", action.Branch, "error", err.Error())
+	}
+}
+
 // - state managers -
 
 func (state *Repo) Init(ctx workflow.Context) {
 	state.Base.Init(ctx)
+	state.migrate(ctx, state.migrations()...)
 
 	if state.do == nil {
 		state.do = &activities.Repo{}
 	}
+
+	if state.provider == nil {
+		state.provider = &activities.Provider{}
+	}
+}
+
+// migrations returns the migrations of the repo state, in order.
+func (state *Repo) migrations() []Migration {
+	return []Migration{
+		// v1: the triggers of a state built without NewRepo are serialized as null.
+		func(_ workflow.Context) {
+			if state.Triggers == nil {
+				state.Triggers = make(BranchTriggers)
+			}
+		},
+	}
 }
 
 // NewRepo creates a new RepoState instance. It initializes BaseState using the provided context and
@@ -184,5 +350,5 @@ func NewRepo(repo *entities.Repo, chat *entities.ChatLink) *Repo {
 	base := &Base{Repo: repo, ChatLink: chat}
 	triggers := make(BranchTriggers)
 
-	return &Repo{base, triggers, &activities.Repo{}}
+	return &Repo{Base: base, Triggers: triggers, do: &activities.Repo{}, provider: &activities.Provider{}}
 }
@@ -171,8 +171,12 @@ func (q *Sequencer[K, E]) Demote(ctx workflow.Context, key K) {
 
 // - Queue Inspection -
 
-// Peek returns the item at the front of the queue without removing it.
+// Peek returns the item at the front of the queue without removing it. Returns nil if the queue is empty.
 func (q *Sequencer[K, E]) Peek(ctx workflow.Context) *E {
+	if q.Head == nil {
+		return nil
+	}
+
 	return q.Head.Item
 }
 
@@ -221,6 +225,17 @@ func (q *Sequencer[K, E]) All(ctx workflow.Context) []*E {
 	return items
 }
 
+// Snapshot returns all items in the queue without acquiring the lock. It is meant for query handlers, which must not
+// block and never run concurrently with the workflow code.
+func (q *Sequencer[K, E]) Snapshot() []*E {
+	items := make([]*E, 0)
+	for current := q.Head; current != nil; current = current.Next {
+		items = append(items, current.Item)
+	}
+
+	return items
+}
+
 // - Initialization and Creation -
 
 // Init restores the lock mutex.
@@ -1,55 +1,121 @@
 package states
 
 import (
+	"github.com/google/uuid"
 	"go.temporal.io/sdk/workflow"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"go.breu.io/quantm/internal/core/kernel"
+	"go.breu.io/quantm/internal/core/repos/defs"
 	"go.breu.io/quantm/internal/db/entities"
 	"go.breu.io/quantm/internal/durable"
 	"go.breu.io/quantm/internal/events"
 	eventsv1 "go.breu.io/quantm/internal/proto/ctrlplane/events/v1"
+	"go.breu.io/quantm/internal/pulse"
 )
 
 type (
 	Trunk struct {
 		*Base      `json:"base"`
 		MergeQueue *Sequencer[int64, eventsv1.MergeQueue] `json:"merge_queue"`
+		Frozen     bool                                   `json:"frozen"` // Frozen pauses the processing of the queue.
 
-		done     bool                   // done flag
-		channel  workflow.Channel       // for cross loop communication
-		inflight []*eventsv1.MergeQueue // in-flight merges
+		// Lineage holds the ID of the latest lifecycle event for each pull request in the queue, so that the lifecycle
+		// events of a pull request are chained together.
+		Lineage map[int64]uuid.UUID `json:"lineage"`
+
+		// InFlight holds the pull requests that left the queue and are being tested, stamped with the time they left it.
+		InFlight []*eventsv1.MergeQueue `json:"inflight"`
+
+		done      bool             // done flag
+		channel   workflow.Channel // for cross loop communication
+		lifecycle bool             // lifecycle is true if the lifecycle events are persisted, see ChangeQueueLifecycle.
+		statuses  bool             // statuses is true if the pull requests are threaded on chat, see ChangeThreadStatus.
 	}
 )
 
 // - queue process -
 
-// OnMergeQueue is the signal handler for the merge queue.
-func (state Trunk) OnMergeQueue(ctx workflow.Context) durable.ChannelHandler {
+// OnMergeQueue is the signal handler for the merge queue. Every change to the queue emits a lifecycle event.
+//
+// A pull request closed against the default branch is merged if it is being tested, and evicted if it is still waiting.
+func (state *Trunk) OnMergeQueue(ctx workflow.Context) durable.ChannelHandler {
 	return func(rx workflow.ReceiveChannel, more bool) {
 		mq := &events.Event[eventsv1.RepoHook, eventsv1.MergeQueue]{}
 		state.rx(ctx, rx, mq)
 
-		if mq.Context.Action == events.EventActionRemoved {
-			state.MergeQueue.Remove(ctx, mq.Payload.GetNumber())
-
-			return
+		number := mq.Payload.GetNumber()
+
+		switch {
+		case mq.Context.Action == events.ActionClosed:
+			if state.pop_inflight(number) != nil {
+				state.emit(ctx, events.ActionMerged, mq.Payload)
+			} else if state.MergeQueue.Position(ctx, number) > 0 {
+				state.MergeQueue.Remove(ctx, number)
+				state.emit(ctx, events.ActionEvicted, mq.Payload)
+			}
+
+		case mq.Context.Action == events.EventActionRemoved:
+			if state.pop_inflight(number) != nil {
+				state.emit(ctx, events.ActionEvicted, mq.Payload)
+			} else if state.MergeQueue.Position(ctx, number) > 0 {
+				state.MergeQueue.Remove(ctx, number)
+				state.emit(ctx, events.ActionEvicted, mq.Payload)
+			}
+
+		case mq.Payload.IsPriority:
+			state.Lineage[number] = mq.ID
+			state.MergeQueue.Priority(ctx, number, mq.Payload)
+			state.emit(ctx, events.ActionPromoted, mq.Payload)
+
+		default:
+			state.Lineage[number] = mq.ID
+			state.MergeQueue.Push(ctx, number, mq.Payload)
+			state.emit(ctx, events.ActionEnqueued, mq.Payload)
 		}
+	}
+}
 
-		if mq.Payload.IsPriority {
-			state.MergeQueue.Priority(ctx, mq.Payload.GetNumber(), mq.Payload)
-
-			return
-		}
+// OnQueueFreeze is the signal handler to freeze or unfreeze the merge queue. Items can still be queued while frozen,
+// but are not processed until the queue is unfrozen.
+func (state *Trunk) OnQueueFreeze(ctx workflow.Context) durable.ChannelHandler {
+	return func(rx workflow.ReceiveChannel, more bool) {
+		payload := &defs.QueueFreezePayload{}
+		state.rx(ctx, rx, payload)
 
-		state.MergeQueue.Push(ctx, mq.Payload.GetNumber(), mq.Payload)
+		state.Frozen = payload.Frozen
+		state.logger.Info("merge_queue: freeze updated", "frozen", state.Frozen, "user", payload.User)
 	}
 }
 
-// StartQueue is the main queue processing loop.
+// - query handlers -
+
+// QueryQueue returns the current state of the merge queue.
+func (state *Trunk) QueryQueue() (*defs.QueueStatus, error) {
+	return &defs.QueueStatus{Frozen: state.Frozen, Items: state.MergeQueue.Snapshot(), InFlight: state.InFlight}, nil
+}
+
+// StartQueue is the main queue processing loop. It waits for the queue to have an item while not frozen, and for a
+// free slot among the in-flight merges, see defs.MergeQueueInFlight. The item leaves the queue only then, so that the
+// wait time of the queue is the time from enqueued to testing.
 func (state *Trunk) StartQueue(ctx workflow.Context) {
 	log := workflow.GetLogger(ctx)
 
-	for state.Continue() && state.MergeQueue.Peek(ctx) != nil {
+	for state.Continue() {
+		_ = workflow.Await(ctx, func() bool {
+			return !state.Continue() ||
+				(!state.Frozen && len(state.InFlight) < defs.MergeQueueInFlight && state.MergeQueue.Peek(ctx) != nil)
+		})
+
+		if !state.Continue() {
+			return
+		}
+
 		next := state.MergeQueue.Pop(ctx) // next item
+		next.Timestamp = timestamppb.New(workflow.Now(ctx))
+
+		state.InFlight = append(state.InFlight, next)
+		state.emit(ctx, events.ActionTesting, next)
 
 		// ahead of line testing
 		// we rebase the changes from the branches that are being tested, this way, we can run tests on each.
@@ -59,8 +125,94 @@ func (state *Trunk) StartQueue(ctx workflow.Context) {
 		// this will allow us to run tests on each branch and merge them in order.
 		//
 		// we also will create a shadow branch that will be used to merge the changes into the main branch.
-		log.Info("merge_queue: attempting ahead of line merge ...", "next", next, "in_prgress", state.inflight)
+		log.Info("merge_queue: attempting ahead of line merge ...", "next", next, "in_prgress", state.InFlight)
+	}
+}
+
+// - local -
+
+// pop_inflight removes the pull request from the in-flight merges. Returns nil if it is not in flight.
+func (state *Trunk) pop_inflight(number int64) *eventsv1.MergeQueue {
+	for idx, item := range state.InFlight {
+		if item.GetNumber() == number {
+			state.InFlight = append(state.InFlight[:idx], state.InFlight[idx+1:]...)
+
+			return item
+		}
 	}
+
+	return nil
+}
+
+// emit persists the queue lifecycle event of the pull request, chained to its previous lifecycle event, and announces
+// it on chat. The lineage of the pull request is dropped once it leaves the queue for good. Runs recorded before
+// ChangeQueueLifecycle only keep the lineage.
+func (state *Trunk) emit(ctx workflow.Context, action events.Action, item *eventsv1.MergeQueue) {
+	event := events.
+		New[eventsv1.RepoHook, eventsv1.MergeQueue]().
+		SetHook(eventsv1.RepoHook(state.Repo.Hook)).
+		SetScope(events.ScopeMergeQueue).
+		SetAction(action).
+		SetSource(state.Repo.Url).
+		SetSubjectName(events.SubjectNameRepos).
+		SetSubjectID(state.Repo.ID).
+		SetOrg(state.Repo.OrgID).
+		SetPayload(&eventsv1.MergeQueue{
+			Number:     item.GetNumber(),
+			Branch:     item.GetBranch(),
+			IsPriority: item.GetIsPriority(),
+			Timestamp:  timestamppb.New(workflow.Now(ctx)),
+		})
+
+	if id, ok := state.Lineage[item.GetNumber()]; ok {
+		event.SetParents(id)
+	}
+
+	if action == events.ActionMerged || action == events.ActionEvicted {
+		delete(state.Lineage, item.GetNumber())
+	} else {
+		state.Lineage[item.GetNumber()] = event.ID
+	}
+
+	if !state.lifecycle {
+		return
+	}
+
+	if err := pulse.Persist(ctx, event); err != nil {
+		state.logger.Warn("merge_queue: unable to persist lifecycle event", "action", action, "number", item.GetNumber(), "error", err.Error())
+	}
+
+	state.announce(ctx, action, item)
+}
+
+// announce keeps the thread of the pull request, on the channel linked with the repo, updated with its state in the
+// queue. The thread starts once the pull request is queued, and is closed once it leaves the queue for good. Runs
+// recorded before ChangeThreadStatus, and repos without a chat link, are not announced.
+func (state *Trunk) announce(ctx workflow.Context, action events.Action, item *eventsv1.MergeQueue) {
+	if !state.statuses || state.ChatLink == nil {
+		return
+	}
+
+	recipient := kernel.Recipient{Kind: kernel.RecipientChannel, ID: state.Repo.ID}
+	thread := kernel.PullRequestThread(state.Repo.ID, item.GetNumber())
+
+	var notification *kernel.Notification
+
+	switch action {
+	case events.ActionEnqueued, events.ActionPromoted:
+		position := state.MergeQueue.Position(ctx, item.GetNumber())
+		notification = kernel.QueuedNotification(recipient, item, position).SetThread(thread).SetStatus("queued")
+	case events.ActionTesting:
+		notification = kernel.StatusNotification(recipient, thread, kernel.SeverityInfo, "testing")
+	case events.ActionMerged:
+		notification = kernel.StatusNotification(recipient, thread, kernel.SeveritySuccess, "merged").Close()
+	case events.ActionEvicted:
+		notification = kernel.StatusNotification(recipient, thread, kernel.SeverityWarning, "evicted").Close()
+	default:
+		return
+	}
+
+	state.send(ctx, "merge_queue_"+action.String(), eventsv1.ChatHook(state.ChatLink.Hook), notification)
 }
 
 func (state *Trunk) Continue() bool {
@@ -69,16 +221,32 @@ func (state *Trunk) Continue() bool {
 
 func (state *Trunk) Init(ctx workflow.Context) {
 	state.Base.Init(ctx)
+	state.migrate(ctx)
 	state.MergeQueue.Init(ctx)
 	state.channel = workflow.NewChannel(ctx)
+
+	if state.Lineage == nil {
+		state.Lineage = make(map[int64]uuid.UUID)
+	}
+
+	if state.InFlight == nil {
+		state.InFlight = make([]*eventsv1.MergeQueue, 0)
+	}
+
+	if workflow.GetVersion(ctx, ChangeQueueLifecycle, workflow.DefaultVersion, 1) != workflow.DefaultVersion {
+		state.lifecycle = true
+	}
+
+	if workflow.GetVersion(ctx, ChangeThreadStatus, workflow.DefaultVersion, 1) != workflow.DefaultVersion {
+		state.statuses = true
+	}
 }
 
 func NewTrunk(repo *entities.Repo, chat *entities.ChatLink) *Trunk {
 	return &Trunk{
-		&Base{Repo: repo, ChatLink: chat},
-		NewSequencer[int64, eventsv1.MergeQueue](),
-		false,
-		nil,
-		make([]*eventsv1.MergeQueue, 0),
+		Base:       &Base{Repo: repo, ChatLink: chat},
+		MergeQueue: NewSequencer[int64, eventsv1.MergeQueue](),
+		Lineage:    make(map[int64]uuid.UUID),
+		InFlight:   make([]*eventsv1.MergeQueue, 0),
 	}
 }
@@ -0,0 +1,64 @@
+package states
+
+import (
+	"go.temporal.io/sdk/workflow"
+)
+
+type (
+	// Migration upgrades the state from one schema version to the next. Migrations run in Init, on the state decoded from
+	// the input of the run, and must not issue any command, i.e. no activities, timers or side effects.
+	Migration func(ctx workflow.Context)
+)
+
+// Change IDs for workflow.GetVersion.
+//
+// The Repo, Branch and Trunk workflows run forever, continuing as new with their state. A running execution is replayed
+// against the latest code whenever its history is not cached on a worker, so every change to the commands a workflow
+// issues, e.g. a new activity, timer or side effect, the wiring of the selector, or the order of the handlers, must be
+// gated with workflow.GetVersion under a change ID declared here. The old branch must be kept until no execution
+// recorded without the change can be replayed, i.e. all of them have continued as new.
+//
+// Handling a new signal or query needs no gate, as no recorded history holds it.
+//
+// A change ID must never be reused or renamed.
+const (
+	ChangeStateSchema = "state_schema" // ChangeStateSchema gates the migrations applied to the state of a run.
+	ChangeStaleDigest = "stale_digest" // ChangeStaleDigest moves the stale branch digest to the working hours of the team.
+
+	// ChangeBranchLifecycle ends the branch when it is deleted or idle, instead of running forever.
+	ChangeBranchLifecycle = "branch_lifecycle"
+
+	// ChangeGitQueue moves the git sessions of the branch to the git queue, after checking the disk space of the worker.
+	ChangeGitQueue = "git_queue"
+
+	// ChangeThreadedNotify sends the notifications of the branch with Notify.Send, threaded per branch, instead of the
+	// activity per notification.
+	ChangeThreadedNotify = "threaded_notify"
+
+	// ChangeClosedPR forwards the pull requests closed against the default branch to the trunk, so that they leave the
+	// merge queue.
+	ChangeClosedPR = "closed_pr"
+
+	// ChangeQueueLifecycle persists the lifecycle events of the merge queue.
+	ChangeQueueLifecycle = "queue_lifecycle"
+
+	// ChangeThreadStatus keeps the status of the chat threads updated, i.e. the branch once its conflicts are resolved,
+	// and the pull request as it moves through the merge queue.
+	ChangeThreadStatus = "thread_status"
+)
+
+// migrate upgrades the state to the latest schema, running the migrations the state hasn't seen in order, where
+// migrations[i] upgrades the state from version i to i+1. Migrations are append only.
+//
+// The schema is gated by ChangeStateSchema, so that a run that started before a migration was added replays without
+// it. The migration is then applied after the run continues as new.
+func (state *Base) migrate(ctx workflow.Context, migrations ...Migration) {
+	target := workflow.GetVersion(ctx, ChangeStateSchema, workflow.DefaultVersion, workflow.Version(len(migrations)))
+
+	for state.Schema < target {
+		state.logger.Info("migrate: upgrading state", "from", state.Schema, "to", state.Schema+1)
+
+		migrations[state.Schema](ctx)
+		state.Schema++
+	}
+}
@@ -0,0 +1,319 @@
+package testkit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+
+	"go.breu.io/durex/queues"
+
+	"go.breu.io/quantm/internal/core/kernel"
+	"go.breu.io/quantm/internal/core/repos/defs"
+	"go.breu.io/quantm/internal/db/entities"
+	"go.breu.io/quantm/internal/events"
+	eventsv1 "go.breu.io/quantm/internal/proto/ctrlplane/events/v1"
+	"go.breu.io/quantm/internal/pulse"
+)
+
+type (
+	// Provider is the fake kernel.Repo. Clones are served from the local remote, everything else is recorded.
+	Provider struct {
+		remote *Remote
+
+		mutex    sync.Mutex
+		comments map[int64][]string
+		statuses []*kernel.CommitStatus
+		checks   []*kernel.CheckRun
+		labels   map[int64][]string
+		merged   []int64
+		branches map[string]string
+	}
+
+	// Messenger is the fake kernel.Chat. It records the notifications instead of sending them.
+	Messenger struct {
+		mutex         sync.Mutex
+		notifications []*kernel.Notification
+	}
+
+	// Forward is a signal the repo workflow forwarded to a branch or to the trunk. Branch is empty for the trunk.
+	Forward struct {
+		Signal queues.Signal
+		Branch string
+		Event  json.RawMessage
+		Start  bool // Start is true if the workflow would be started when not running.
+	}
+
+	// forwarder records the signals forwarded by the repo workflow, in place of the activities starting the workflows.
+	forwarder struct {
+		mutex    sync.Mutex
+		forwards []Forward
+	}
+)
+
+// - provider -
+
+// TokenizedCloneUrl returns the path of the local remote.
+func (p *Provider) TokenizedCloneUrl(_ context.Context, _ *entities.Repo) (string, error) {
+	return p.remote.Path, nil
+}
+
+// AddComment records the comment on the pull request.
+func (p *Provider) AddComment(_ context.Context, _ *entities.Repo, number int64, body string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.comments[number] = append(p.comments[number], body)
+
+	return nil
+}
+
+// SetCommitStatus records the commit status.
+func (p *Provider) SetCommitStatus(_ context.Context, _ *entities.Repo, status *kernel.CommitStatus) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.statuses = append(p.statuses, status)
+
+	return nil
+}
+
+// CreateCheckRun records the check run, and returns its position in the recorded check runs as the ID.
+func (p *Provider) CreateCheckRun(_ context.Context, _ *entities.Repo, run *kernel.CheckRun) (int64, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	created := *run
+	created.ID = int64(len(p.checks) + 1)
+	p.checks = append(p.checks, &created)
+
+	return created.ID, nil
+}
+
+// UpdateCheckRun replaces the check run with the same ID.
+func (p *Provider) UpdateCheckRun(_ context.Context, _ *entities.Repo, run *kernel.CheckRun) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if run.ID < 1 || run.ID > int64(len(p.checks)) {
+		return fmt.Errorf("testkit: unknown check run %d", run.ID)
+	}
+
+	updated := *run
+	p.checks[run.ID-1] = &updated
+
+	return nil
+}
+
+// AddLabels records the labels on the pull request.
+func (p *Provider) AddLabels(_ context.Context, _ *entities.Repo, number int64, labels ...string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, label := range labels {
+		if !slices.Contains(p.labels[number], label) {
+			p.labels[number] = append(p.labels[number], label)
+		}
+	}
+
+	return nil
+}
+
+// RemoveLabel removes the label from the pull request.
+func (p *Provider) RemoveLabel(_ context.Context, _ *entities.Repo, number int64, label string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.labels[number] = slices.DeleteFunc(p.labels[number], func(l string) bool { return l == label })
+
+	return nil
+}
+
+// MergePullRequest records the merge, and returns the head of the default branch of the remote as the merge commit.
+func (p *Provider) MergePullRequest(_ context.Context, repo *entities.Repo, number int64, _ *kernel.MergeOptions) (string, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.merged = append(p.merged, number)
+
+	return p.remote.Head(repo.DefaultBranch), nil
+}
+
+// CreateBranch records the branch.
+func (p *Provider) CreateBranch(_ context.Context, _ *entities.Repo, branch, sha string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.branches[branch] = sha
+
+	return nil
+}
+
+// DeleteBranch removes the branch created with CreateBranch.
+func (p *Provider) DeleteBranch(_ context.Context, _ *entities.Repo, branch string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	delete(p.branches, branch)
+
+	return nil
+}
+
+// ListBranches returns the branches of the local remote.
+func (p *Provider) ListBranches(_ context.Context, _ *entities.Repo) ([]string, error) {
+	return p.remote.Branches(), nil
+}
+
+// Comments returns the comments added to the pull request.
+func (p *Provider) Comments(number int64) []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return slices.Clone(p.comments[number])
+}
+
+// Statuses returns the commit statuses, in the order they were set.
+func (p *Provider) Statuses() []*kernel.CommitStatus {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return slices.Clone(p.statuses)
+}
+
+// CheckRuns returns the check runs, in the order they were created, as last updated.
+func (p *Provider) CheckRuns() []*kernel.CheckRun {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return slices.Clone(p.checks)
+}
+
+// Labels returns the labels of the pull request.
+func (p *Provider) Labels(number int64) []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return slices.Clone(p.labels[number])
+}
+
+// Merged returns the numbers of the merged pull requests, in the order they were merged.
+func (p *Provider) Merged() []int64 {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return slices.Clone(p.merged)
+}
+
+// - messenger -
+
+// Notify records the notification.
+func (m *Messenger) Notify(_ context.Context, notification *kernel.Notification) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.notifications = append(m.notifications, notification)
+
+	return nil
+}
+
+// NotifyLinesExceed records the lines exceeded notification of the event.
+func (m *Messenger) NotifyLinesExceed(ctx context.Context, event *events.Event[eventsv1.ChatHook, eventsv1.Diff]) error {
+	return m.Notify(ctx, kernel.LinesExceededNotification(event))
+}
+
+// NotifyMergeConflict records the merge conflict notification of the event.
+func (m *Messenger) NotifyMergeConflict(ctx context.Context, event *events.Event[eventsv1.ChatHook, eventsv1.Merge]) error {
+	return m.Notify(ctx, kernel.MergeConflictNotification(event))
+}
+
+// Notifications returns the notifications of the given kinds, or all of them if no kind is given, in the order they
+// were sent.
+func (m *Messenger) Notifications(kinds ...kernel.NotificationKind) []*kernel.Notification {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if len(kinds) == 0 {
+		return slices.Clone(m.notifications)
+	}
+
+	result := make([]*kernel.Notification, 0)
+
+	for _, notification := range m.notifications {
+		if slices.Contains(kinds, notification.Kind) {
+			result = append(result, notification)
+		}
+	}
+
+	return result
+}
+
+// - forwarder -
+
+// to_branch records the signal to the branch. It is registered as the ForwardToBranch activity.
+func (f *forwarder) to_branch(_ context.Context, payload *defs.SignalBranchPayload, event json.RawMessage, _ any) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.forwards = append(f.forwards, Forward{Signal: payload.Signal, Branch: payload.Branch, Event: event, Start: true})
+
+	return nil
+}
+
+// signal_branch records the signal to the running branch. It is registered as the SignalBranch activity.
+func (f *forwarder) signal_branch(_ context.Context, payload *defs.SignalBranchPayload, event json.RawMessage) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.forwards = append(f.forwards, Forward{Signal: payload.Signal, Branch: payload.Branch, Event: event})
+
+	return nil
+}
+
+// to_trunk records the signal to the trunk. It is registered as the ForwardToTrunk activity.
+func (f *forwarder) to_trunk(_ context.Context, payload *defs.SignalTrunkPayload, event json.RawMessage, _ any) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.forwards = append(f.forwards, Forward{Signal: payload.Signal, Event: event, Start: true})
+
+	return nil
+}
+
+// list returns the forwards with the signal.
+func (f *forwarder) list(signal queues.Signal) []Forward {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	result := make([]Forward, 0)
+
+	for _, fwd := range f.forwards {
+		if fwd.Signal == signal {
+			result = append(result, fwd)
+		}
+	}
+
+	return result
+}
+
+// - pulse -
+
+// persist_repo writes the repo event to the pulse sink. It is registered as the PersistRepoEvent activity.
+func persist_repo(ctx context.Context, flat events.Flat[eventsv1.RepoHook]) error {
+	return insert(ctx, pulse.NewRecord(flat))
+}
+
+// persist_chat writes the chat event to the pulse sink. It is registered as the PersistChatEvent activity.
+func persist_chat(ctx context.Context, flat events.Flat[eventsv1.ChatHook]) error {
+	return insert(ctx, pulse.NewRecord(flat))
+}
+
+// insert writes the record to the events table of the pulse sink.
+func insert(ctx context.Context, record pulse.Record) error {
+	sink, err := pulse.Sink()
+	if err != nil {
+		return err
+	}
+
+	return sink.Insert(ctx, EventsTable, []pulse.Record{record})
+}
@@ -0,0 +1,258 @@
+// Package testkit drives the repo, branch and trunk workflows on the temporal test environment.
+//
+// The kit replaces the I/O of the workflows with fakes, so that a test can script the signals a workflow receives and
+// assert on what it did:
+//
+//   - the provider, a fake kernel.Repo, serves clones from a bare git repository on the local disk, and records the
+//     comments, labels, statuses and merges.
+//   - the messenger, a fake kernel.Chat, records the notifications.
+//   - the events persisted by the workflows are written to a pulse.MemorySink.
+//   - the signals the repo workflow forwards to the branches and the trunk are recorded instead of starting the
+//     workflows.
+//
+// The git activities run for real against the local remote. The workflows run until the test restarts them with
+// Restart, since they only return to continue as new.
+//
+//	kit := testkit.New(s.T(), s.env)
+//	sha := kit.Remote.Commit("feature", "add feature", map[string]string{"feature.go": "package feature\n"})
+//
+//	kit.Signal(time.Second, defs.SignalPush, kit.Push("feature", sha))
+//	kit.Restart(time.Minute, defs.SignalPRReview, kit.Review())
+//
+//	s.env.ExecuteWorkflow(workflows.Branch, states.NewBranch(kit.Repo, kit.ChatLink, "feature"))
+//
+// The kit registers the fakes on the kernel and pulse singletons, so tests using it must not run in parallel.
+package testkit
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.breu.io/durex/queues"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/worker"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"go.breu.io/quantm/internal/core/kernel"
+	"go.breu.io/quantm/internal/core/repos/activities"
+	"go.breu.io/quantm/internal/core/repos/fns"
+	"go.breu.io/quantm/internal/db/entities"
+	"go.breu.io/quantm/internal/events"
+	eventsv1 "go.breu.io/quantm/internal/proto/ctrlplane/events/v1"
+	"go.breu.io/quantm/internal/pulse"
+)
+
+type (
+	// Kit is the test kit for a single workflow execution.
+	Kit struct {
+		Env       *testsuite.TestWorkflowEnvironment
+		Remote    *Remote            // Remote is the repository on the fake provider.
+		Provider  *Provider          // Provider is the fake repo hook.
+		Messenger *Messenger         // Messenger is the fake chat hook.
+		Sink      *pulse.MemorySink  // Sink holds the persisted events.
+		Repo      *entities.Repo     // Repo is the repo the workflows run for.
+		ChatLink  *entities.ChatLink // ChatLink is the chat link of the repo.
+
+		forwarder *forwarder
+	}
+)
+
+const (
+	DefaultBranch = "main"           // DefaultBranch is the default branch of the remote.
+	Threshold     = 10               // Threshold is the number of changed lines above which a branch is flagged.
+	EventsTable   = "events_testkit" // EventsTable is the table of the sink the events are persisted to.
+)
+
+// New creates the kit on the test environment. It registers the fakes on the kernel and the pulse sink, the activities
+// on the environment, and enables sessions on the test worker.
+func New(t testing.TB, env *testsuite.TestWorkflowEnvironment) *Kit {
+	t.Helper()
+
+	remote := NewRemote(t, DefaultBranch)
+
+	kit := &Kit{
+		Env:    env,
+		Remote: remote,
+		Provider: &Provider{
+			remote:   remote,
+			comments: make(map[int64][]string),
+			labels:   make(map[int64][]string),
+			branches: make(map[string]string),
+		},
+		Messenger: &Messenger{},
+		Sink:      pulse.NewMemorySink(),
+		Repo: &entities.Repo{
+			ID:            uuid.New(),
+			OrgID:         uuid.New(),
+			Name:          "quantm",
+			Hook:          int32(eventsv1.RepoHook_REPO_HOOK_GITHUB),
+			DefaultBranch: DefaultBranch,
+			Threshold:     Threshold,
+			Url:           "https://github.com/breuhq/quantm",
+			IsActive:      true,
+		},
+		forwarder: &forwarder{},
+	}
+
+	kit.ChatLink = &entities.ChatLink{
+		ID:     uuid.New(),
+		Hook:   int32(eventsv1.ChatHook_CHAT_HOOK_SLACK),
+		Kind:   "repo",
+		LinkTo: kit.Repo.ID,
+	}
+
+	kernel.Get().RegisterRepoHook(eventsv1.RepoHook_REPO_HOOK_GITHUB, kit.Provider)
+	kernel.Get().RegisterChatHook(eventsv1.ChatHook_CHAT_HOOK_SLACK, kit.Messenger)
+	pulse.SetSink(kit.Sink)
+
+	env.SetWorkerOptions(worker.Options{EnableSessionWorker: true})
+
+	env.RegisterActivity(&activities.Branch{})
+	env.RegisterActivity(&activities.Notify{})
+	env.RegisterActivity(&activities.Provider{})
+	env.RegisterActivityWithOptions(kit.forwarder.to_branch, activity.RegisterOptions{Name: "ForwardToBranch"})
+	env.RegisterActivityWithOptions(kit.forwarder.to_trunk, activity.RegisterOptions{Name: "ForwardToTrunk"})
+	env.RegisterActivityWithOptions(kit.forwarder.signal_branch, activity.RegisterOptions{Name: "SignalBranch"})
+	env.RegisterActivityWithOptions(persist_repo, activity.RegisterOptions{Name: "PersistRepoEvent"})
+	env.RegisterActivityWithOptions(persist_chat, activity.RegisterOptions{Name: "PersistChatEvent"})
+
+	return kit
+}
+
+// - scripting -
+
+// Signal sends the signal with the payload to the workflow after the delay.
+func (k *Kit) Signal(delay time.Duration, signal queues.Signal, payload any) {
+	k.Env.RegisterDelayedCallback(func() { k.Env.SignalWorkflow(signal.String(), payload) }, delay)
+}
+
+// Restart suggests the workflow to continue as new after the delay, and wakes up its event loop with the signal. The
+// workflow returns a continue as new error once the signal is handled.
+func (k *Kit) Restart(delay time.Duration, signal queues.Signal, payload any) {
+	k.Env.RegisterDelayedCallback(func() {
+		k.Env.SetContinueAsNewSuggested(true)
+		k.Env.SignalWorkflow(signal.String(), payload)
+	}, delay)
+}
+
+// Query runs the query with the args on the workflow after the delay, and decodes the result into the target. The
+// assertion is run with the error of the query.
+func (k *Kit) Query(delay time.Duration, query queues.Query, target any, assert func(err error), args ...any) {
+	k.Env.RegisterDelayedCallback(func() {
+		value, err := k.Env.QueryWorkflow(query.String(), args...)
+		if err == nil {
+			err = value.Get(target)
+		}
+
+		assert(err)
+	}, delay)
+}
+
+// - assertions -
+
+// Events returns the persisted events of the scope, and of the actions if any is given, in the order they were
+// persisted.
+func (k *Kit) Events(scope events.Scope, actions ...events.Action) []pulse.Record {
+	result := make([]pulse.Record, 0)
+
+	for _, record := range k.Sink.Records(EventsTable) {
+		if record.Scope != scope {
+			continue
+		}
+
+		if len(actions) > 0 && !slices.Contains(actions, record.Action) {
+			continue
+		}
+
+		result = append(result, record)
+	}
+
+	return result
+}
+
+// Forwards returns the signals forwarded by the repo workflow, in the order they were sent.
+func (k *Kit) Forwards(signal queues.Signal) []Forward {
+	return k.forwarder.list(signal)
+}
+
+// - events -
+
+// Push returns the push of the commit on the branch.
+func (k *Kit) Push(branch, sha string) *events.Event[eventsv1.RepoHook, eventsv1.Push] {
+	now := timestamppb.Now()
+
+	return event(k, events.ScopePush, events.ActionCreated, &eventsv1.Push{
+		Ref:        fns.BranchNameToRef(branch),
+		After:      sha,
+		Repository: k.Repo.Name,
+		Commits:    []*eventsv1.Commit{{Sha: sha, Message: "commit " + sha, Timestamp: now}},
+		Timestamp:  now,
+	})
+}
+
+// Ref returns the creation or the deletion of the branch, depending upon the action.
+func (k *Kit) Ref(branch string, action events.Action) *events.Event[eventsv1.RepoHook, eventsv1.GitRef] {
+	return event(k, events.ScopeBranch, action, &eventsv1.GitRef{Ref: fns.BranchNameToRef(branch), Kind: "branch"})
+}
+
+// PullRequest returns the pull request event of the branch against the default branch.
+func (k *Kit) PullRequest(
+	number int64, branch string, action events.Action,
+) *events.Event[eventsv1.RepoHook, eventsv1.PullRequest] {
+	return event(k, events.ScopePr, action, &eventsv1.PullRequest{
+		Number:     number,
+		Title:      "pull request " + branch,
+		HeadBranch: branch,
+		BaseBranch: k.Repo.DefaultBranch,
+		Timestamp:  timestamppb.Now(),
+	})
+}
+
+// Label returns the label added to the pull request of the branch.
+func (k *Kit) Label(number int64, branch, name string) *events.Event[eventsv1.RepoHook, eventsv1.PullRequestLabel] {
+	return event(k, events.ScopePrLabel, events.EventActionAdded, &eventsv1.PullRequestLabel{
+		Name:      name,
+		Number:    number,
+		Branch:    branch,
+		Timestamp: timestamppb.Now(),
+	})
+}
+
+// Review returns an empty pull request review, handled as a no-op by the workflows. It is useful to wake up the event
+// loop.
+func (k *Kit) Review() *events.Event[eventsv1.RepoHook, eventsv1.PullRequestReview] {
+	return event(k, events.ScopePr, events.ActionCreated, &eventsv1.PullRequestReview{})
+}
+
+// Rebase returns the request to rebase the head on the base.
+func (k *Kit) Rebase(base, head string) *events.Event[eventsv1.RepoHook, eventsv1.Rebase] {
+	return event(k, events.ScopeRebase, events.ActionRequested, &eventsv1.Rebase{Base: base, Head: head, Repository: k.Repo.Name})
+}
+
+// MergeQueue returns the merge queue event of the pull request of the branch.
+func (k *Kit) MergeQueue(
+	number int64, branch string, action events.Action,
+) *events.Event[eventsv1.RepoHook, eventsv1.MergeQueue] {
+	return event(k, events.ScopeMergeQueue, action, &eventsv1.MergeQueue{
+		Number:    number,
+		Branch:    branch,
+		Timestamp: timestamppb.Now(),
+	})
+}
+
+// event returns the event of the repo of the kit with the payload.
+func event[P events.Payload](k *Kit, scope events.Scope, action events.Action, payload *P) *events.Event[eventsv1.RepoHook, P] {
+	return events.
+		New[eventsv1.RepoHook, P]().
+		SetHook(eventsv1.RepoHook_REPO_HOOK_GITHUB).
+		SetScope(scope).
+		SetAction(action).
+		SetSource(k.Repo.Url).
+		SetSubjectName(events.SubjectNameRepos).
+		SetSubjectID(k.Repo.ID).
+		SetOrg(k.Repo.OrgID).
+		SetPayload(payload)
+}
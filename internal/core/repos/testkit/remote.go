@@ -0,0 +1,107 @@
+package testkit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.breu.io/quantm/internal/core/repos/git"
+)
+
+type (
+	// Remote is a bare git repository on the local disk, standing in for the repository on the provider. The workflows
+	// clone it through the fake provider, and the tests shape it through a work tree of their own.
+	Remote struct {
+		Path string // Path is the path of the bare repository, used as the clone url.
+
+		t    testing.TB
+		work string
+	}
+)
+
+// NewRemote creates a bare repository with the default branch holding a single commit.
+func NewRemote(t testing.TB, branch string) *Remote {
+	t.Helper()
+
+	// commits made by the fixture and by the git activities must not depend on the git config of the host.
+	t.Setenv("GIT_AUTHOR_NAME", "quantm")
+	t.Setenv("GIT_AUTHOR_EMAIL", "quantm@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "quantm")
+	t.Setenv("GIT_COMMITTER_EMAIL", "quantm@example.com")
+
+	dir := t.TempDir()
+	r := &Remote{Path: filepath.Join(dir, "remote.git"), t: t, work: filepath.Join(dir, "work")}
+
+	r.git(dir, "init", "--bare", "--initial-branch", branch, r.Path)
+	r.git(dir, "clone", r.Path, r.work)
+	r.git(r.work, "checkout", "-b", branch)
+	r.Commit(branch, "initial commit", map[string]string{"README.md": "# quantm\n"})
+
+	return r
+}
+
+// Commit writes the files on the branch, commits and pushes them to the remote, and returns the SHA of the commit. The
+// branch is created from the current commit if it doesn't exist.
+func (r *Remote) Commit(branch, message string, files map[string]string) string {
+	r.t.Helper()
+
+	if _, err := git.RevParse(context.Background(), r.work, "refs/heads/"+branch); err != nil {
+		r.git(r.work, "checkout", "-b", branch)
+	} else {
+		r.git(r.work, "checkout", branch)
+	}
+
+	for name, content := range files {
+		path := filepath.Join(r.work, name)
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			r.t.Fatalf("remote: unable to create dir: %v", err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			r.t.Fatalf("remote: unable to write file: %v", err)
+		}
+	}
+
+	r.git(r.work, "add", "--all")
+	r.git(r.work, "commit", "--message", message)
+	r.git(r.work, "push", "origin", branch)
+
+	return r.Head(branch)
+}
+
+// Checkout switches the work tree to the branch, so that the next branch created by Commit starts from it.
+func (r *Remote) Checkout(branch string) {
+	r.t.Helper()
+	r.git(r.work, "checkout", branch)
+}
+
+// Head returns the SHA of the head of the branch.
+func (r *Remote) Head(branch string) string {
+	r.t.Helper()
+
+	return r.git(r.work, "rev-parse", "refs/heads/"+branch)
+}
+
+// Branches returns the names of the branches of the remote.
+func (r *Remote) Branches() []string {
+	r.t.Helper()
+
+	out := r.git(r.Path, "for-each-ref", "--format=%(refname:short)", "refs/heads")
+
+	return strings.Fields(out)
+}
+
+// git runs the git command in the dir, failing the test on error.
+func (r *Remote) git(dir string, args ...string) string {
+	r.t.Helper()
+
+	out, err := git.Run(context.Background(), dir, args...)
+	if err != nil {
+		r.t.Fatalf("remote: %v", err)
+	}
+
+	return out
+}
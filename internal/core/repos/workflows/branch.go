@@ -12,6 +12,11 @@ func Branch(ctx workflow.Context, state *states.Branch) error {
 
 	selector := workflow.NewSelector(ctx)
 
+	// - query handlers -
+	if err := workflow.SetQueryHandler(ctx, defs.QueryBranchForStatus.String(), state.QueryStatus); err != nil {
+		return err
+	}
+
 	// - activity monitors -
 
 	state.PullRequestMonitor(ctx)
@@ -34,15 +39,27 @@ func Branch(ctx workflow.Context, state *states.Branch) error {
 	prrc := workflow.GetSignalChannel(ctx, defs.ReviewComment.String())
 	selector.AddReceive(prrc, state.OnPRReviewComment(ctx))
 
+	action := workflow.GetSignalChannel(ctx, defs.SignalChatAction.String())
+	selector.AddReceive(action, state.OnChatAction(ctx))
+
+	// - lifecycle -
+
+	if state.Expires() {
+		ref := workflow.GetSignalChannel(ctx, defs.SignalRef.String())
+		selector.AddReceive(ref, state.OnRef(ctx))
+
+		selector.AddReceive(state.IdleMonitor(ctx), state.OnIdle(ctx))
+	}
+
 	// - event loop -
 
-	for !state.ExitLoop(ctx) {
+	for !state.ExitLoop(ctx) || state.Pending(selector) {
 		selector.Select(ctx)
 	}
 
 	// - exit or continue -
 
-	if state.RestartRecommended(ctx) {
+	if !state.Done() && state.RestartRecommended(ctx) {
 		return workflow.NewContinueAsNewError(ctx, Branch, state)
 	}
 
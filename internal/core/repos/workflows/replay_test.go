@@ -0,0 +1,30 @@
+package workflows_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/worker"
+
+	"go.breu.io/quantm/internal/core/repos/workflows"
+)
+
+// TestReplay replays the captured histories in testdata/histories against the current workflows. A failure means a
+// change to the commands of a workflow is missing its workflow.GetVersion gate, see states.ChangeStateSchema.
+func TestReplay(t *testing.T) {
+	files, err := filepath.Glob("testdata/histories/*.json")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	replayer := worker.NewWorkflowReplayer()
+	replayer.RegisterWorkflow(workflows.Repo)
+	replayer.RegisterWorkflow(workflows.Branch)
+	replayer.RegisterWorkflow(workflows.Trunk)
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			require.NoError(t, replayer.ReplayWorkflowHistoryFromJSONFile(nil, file))
+		})
+	}
+}
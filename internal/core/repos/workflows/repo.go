@@ -20,6 +20,10 @@ func Repo(ctx workflow.Context, state *states.Repo) error {
 		return err
 	}
 
+	if err := workflow.SetQueryHandler(ctx, defs.QueryRepoForTriggers.String(), state.QueryTriggers); err != nil {
+		return err
+	}
+
 	// - signal handlers -
 
 	selector.AddReceive(workflow.GetSignalChannel(ctx, defs.SignalRef.String()), state.OnRef(ctx))
@@ -28,6 +32,8 @@ func Repo(ctx workflow.Context, state *states.Repo) error {
 	selector.AddReceive(workflow.GetSignalChannel(ctx, defs.SignalPRReview.String()), state.OnPRReview(ctx))
 	selector.AddReceive(workflow.GetSignalChannel(ctx, defs.SignalMergeQueue.String()), state.OnMergeQueue(ctx))
 	selector.AddReceive(workflow.GetSignalChannel(ctx, defs.ReviewComment.String()), state.OnReviewComment(ctx))
+	selector.AddReceive(workflow.GetSignalChannel(ctx, defs.SignalChatAction.String()), state.OnChatAction(ctx))
+	selector.AddReceive(workflow.GetSignalChannel(ctx, defs.SignalSyncTriggers.String()), state.OnSyncTriggers(ctx))
 
 	// - event loop -
 
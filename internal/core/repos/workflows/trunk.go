@@ -12,13 +12,21 @@ func Trunk(ctx workflow.Context, state *states.Trunk) error {
 
 	selector := workflow.NewSelector(ctx)
 
+	// - query handlers -
+	if err := workflow.SetQueryHandler(ctx, defs.QueryTrunkForQueue.String(), state.QueryQueue); err != nil {
+		return err
+	}
+
 	mq := workflow.GetSignalChannel(ctx, defs.SignalMergeQueue.String())
 	selector.AddReceive(mq, state.OnMergeQueue(ctx))
 
+	freeze := workflow.GetSignalChannel(ctx, defs.SignalQueueFreeze.String())
+	selector.AddReceive(freeze, state.OnQueueFreeze(ctx))
+
 	// - queue control -
 	workflow.Go(ctx, state.StartQueue)
 
-	for state.Continue() {
+	for state.Continue() && !state.RestartRecommended(ctx) {
 		selector.Select(ctx)
 	}
 
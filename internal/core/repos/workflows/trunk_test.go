@@ -0,0 +1,28 @@
+package workflows_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+
+	"go.breu.io/quantm/internal/core/repos/states"
+	"go.breu.io/quantm/internal/core/repos/workflows"
+	"go.breu.io/quantm/internal/db/entities"
+)
+
+// TestTrunk_ContinueAsNewSuggested checks that the trunk leaves its loop on the continue-as-new suggestion, like the
+// repo and branch workflows, instead of waiting for a signal that may never come.
+func TestTrunk_ContinueAsNewSuggested(t *testing.T) {
+	env := (&testsuite.WorkflowTestSuite{}).NewTestWorkflowEnvironment()
+	env.SetContinueAsNewSuggested(true)
+
+	repo := &entities.Repo{ID: uuid.New(), OrgID: uuid.New(), Name: "quantm", DefaultBranch: "main"}
+
+	env.ExecuteWorkflow(workflows.Trunk, states.NewTrunk(repo, nil))
+
+	assert.True(t, env.IsWorkflowCompleted())
+	assert.True(t, workflow.IsContinueAsNewError(env.GetWorkflowError()), "unexpected error: %v", env.GetWorkflowError())
+}
@@ -0,0 +1,522 @@
+package workflows_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"go.breu.io/quantm/internal/core/kernel"
+	"go.breu.io/quantm/internal/core/repos/defs"
+	"go.breu.io/quantm/internal/core/repos/states"
+	"go.breu.io/quantm/internal/core/repos/testkit"
+	"go.breu.io/quantm/internal/core/repos/workflows"
+	"go.breu.io/quantm/internal/events"
+	eventsv1 "go.breu.io/quantm/internal/proto/ctrlplane/events/v1"
+)
+
+type (
+	WorkflowsTestSuite struct {
+		suite.Suite
+		testsuite.WorkflowTestSuite
+
+		env *testsuite.TestWorkflowEnvironment
+		kit *testkit.Kit
+	}
+)
+
+func TestWorkflowsTestSuite(t *testing.T) {
+	suite.Run(t, new(WorkflowsTestSuite))
+}
+
+func (s *WorkflowsTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	s.kit = testkit.New(s.T(), s.env)
+}
+
+// lines returns the content of a file with n lines.
+func lines(n int) string {
+	return strings.Repeat("line\n", n)
+}
+
+// restarted asserts that the workflow returned to continue as new.
+func (s *WorkflowsTestSuite) restarted() {
+	s.True(s.env.IsWorkflowCompleted())
+	s.True(workflow.IsContinueAsNewError(s.env.GetWorkflowError()), "unexpected error: %v", s.env.GetWorkflowError())
+}
+
+// - branch -
+
+func (s *WorkflowsTestSuite) TestBranch_Push_LinesExceeded() {
+	sha := s.kit.Remote.Commit("feature", "add feature", map[string]string{"feature.txt": lines(testkit.Threshold * 2)})
+
+	s.kit.Signal(time.Millisecond*500, defs.SignalPullRequestLabel, s.kit.Label(7, "feature", "bug"))
+	s.kit.Signal(time.Second, defs.SignalPush, s.kit.Push("feature", sha))
+
+	status := &defs.BranchStatus{}
+	s.kit.Query(time.Minute, defs.QueryBranchForStatus, status, func(err error) {
+		s.NoError(err)
+	})
+
+	s.kit.Restart(time.Minute*2, defs.SignalPRReview, s.kit.Review())
+
+	s.env.ExecuteWorkflow(workflows.Branch, states.NewBranch(s.kit.Repo, s.kit.ChatLink, "feature"))
+
+	s.restarted()
+	s.Equal("feature", status.Branch)
+	s.Equal(sha, status.LatestCommit.GetSha())
+
+	notifications := s.kit.Messenger.Notifications(kernel.NotificationKindLinesExceeded)
+	if s.Len(notifications, 1) {
+		s.Equal(kernel.BranchThread(s.kit.Repo.ID, "feature"), notifications[0].Thread)
+		s.Contains(notifications[0].Status, "20 lines changed")
+
+		// the pull request of the branch, known from the label, can be added to the merge queue from the alert.
+		if s.Len(notifications[0].Actions, 2) {
+			action, err := kernel.ParseChatAction(notifications[0].Actions[0].ID, notifications[0].Actions[0].Value)
+			s.NoError(err)
+			s.Equal(kernel.ChatActionEnqueue, action.Kind)
+			s.Equal(int64(7), action.Number)
+		}
+	}
+
+	s.Len(s.kit.Events(events.ScopeDiff), 1)
+}
+
+func (s *WorkflowsTestSuite) TestBranch_StaleDigest() {
+	sha := s.kit.Remote.Commit("feature", "add feature", map[string]string{"feature.txt": lines(1)})
+
+	s.kit.Signal(time.Second, defs.SignalPush, s.kit.Push("feature", sha))
+	s.kit.Restart(time.Hour*24*7, defs.SignalPRReview, s.kit.Review())
+
+	s.env.ExecuteWorkflow(workflows.Branch, states.NewBranch(s.kit.Repo, s.kit.ChatLink, "feature"))
+
+	s.restarted()
+
+	// the digest goes out on the working days once the branch has had no commits for three days.
+	notifications := s.kit.Messenger.Notifications(kernel.NotificationKindStaleBranch)
+	if s.NotEmpty(notifications) {
+		s.Equal(kernel.BranchThread(s.kit.Repo.ID, "feature"), notifications[0].Thread)
+		s.Contains(notifications[0].Body, "feature")
+	}
+}
+
+func (s *WorkflowsTestSuite) TestBranch_Push_BelowThreshold() {
+	sha := s.kit.Remote.Commit("feature", "add feature", map[string]string{"feature.txt": lines(testkit.Threshold / 2)})
+
+	s.kit.Signal(time.Second, defs.SignalPush, s.kit.Push("feature", sha))
+	s.kit.Restart(time.Minute, defs.SignalPRReview, s.kit.Review())
+
+	s.env.ExecuteWorkflow(workflows.Branch, states.NewBranch(s.kit.Repo, s.kit.ChatLink, "feature"))
+
+	s.restarted()
+	s.Empty(s.kit.Messenger.Notifications())
+	s.Empty(s.kit.Events(events.ScopeDiff))
+}
+
+func (s *WorkflowsTestSuite) TestBranch_Push_DiskSpace() {
+	sha := s.kit.Remote.Commit("feature", "add feature", map[string]string{"feature.txt": lines(testkit.Threshold * 2)})
+
+	full := temporal.NewNonRetryableApplicationError("disk full", "InsufficientDiskSpace", nil)
+	s.env.OnActivity("CheckDiskSpace", mock.Anything, mock.Anything).Return(full).Once()
+	s.env.OnActivity("CheckDiskSpace", mock.Anything, mock.Anything).Return(nil).Once()
+
+	s.kit.Signal(time.Second, defs.SignalPush, s.kit.Push("feature", sha))
+	s.kit.Restart(time.Minute*2, defs.SignalPRReview, s.kit.Review())
+
+	s.env.ExecuteWorkflow(workflows.Branch, states.NewBranch(s.kit.Repo, s.kit.ChatLink, "feature"))
+
+	s.restarted()
+	s.env.AssertExpectations(s.T())
+	s.Len(s.kit.Messenger.Notifications(kernel.NotificationKindLinesExceeded), 1, "the push is handled on the next worker")
+}
+
+func (s *WorkflowsTestSuite) TestBranch_Push_DiskSpaceExhausted() {
+	sha := s.kit.Remote.Commit("feature", "add feature", map[string]string{"feature.txt": lines(testkit.Threshold * 2)})
+
+	full := temporal.NewNonRetryableApplicationError("disk full", "InsufficientDiskSpace", nil)
+	s.env.OnActivity("CheckDiskSpace", mock.Anything, mock.Anything).Return(full).Times(defs.GitSessionAttempts)
+
+	s.kit.Signal(time.Second, defs.SignalPush, s.kit.Push("feature", sha))
+	s.kit.Restart(time.Minute*5, defs.SignalPRReview, s.kit.Review())
+
+	s.env.ExecuteWorkflow(workflows.Branch, states.NewBranch(s.kit.Repo, s.kit.ChatLink, "feature"))
+
+	s.restarted()
+	s.env.AssertExpectations(s.T())
+	s.Empty(s.kit.Messenger.Notifications())
+}
+
+func (s *WorkflowsTestSuite) TestBranch_Rebase_Conflict() {
+	head := s.kit.Remote.Commit("feature", "change readme", map[string]string{"README.md": "# feature\n"})
+
+	s.kit.Remote.Checkout(testkit.DefaultBranch)
+	s.kit.Remote.Commit(testkit.DefaultBranch, "change readme", map[string]string{"README.md": "# main\n"})
+
+	s.kit.Signal(time.Second, defs.SignalRebase, s.kit.Rebase(testkit.DefaultBranch, head))
+	s.kit.Restart(time.Minute, defs.SignalPRReview, s.kit.Review())
+
+	s.env.ExecuteWorkflow(workflows.Branch, states.NewBranch(s.kit.Repo, s.kit.ChatLink, "feature"))
+
+	s.restarted()
+
+	notifications := s.kit.Messenger.Notifications(kernel.NotificationKindMergeConflict)
+	if s.Len(notifications, 1) {
+		s.Equal("merge conflict in 1 file(s)", notifications[0].Status)
+		s.Len(notifications[0].Actions, 2)
+	}
+
+	s.Len(s.kit.Events(events.ScopeMerge), 1)
+}
+
+func (s *WorkflowsTestSuite) TestBranch_Rebase_Clean() {
+	head := s.kit.Remote.Commit("feature", "add feature", map[string]string{"feature.txt": lines(1)})
+
+	s.kit.Remote.Checkout(testkit.DefaultBranch)
+	s.kit.Remote.Commit(testkit.DefaultBranch, "add main", map[string]string{"main.txt": lines(1)})
+
+	s.kit.Signal(time.Second, defs.SignalRebase, s.kit.Rebase(testkit.DefaultBranch, head))
+	s.kit.Restart(time.Minute, defs.SignalPRReview, s.kit.Review())
+
+	s.env.ExecuteWorkflow(workflows.Branch, states.NewBranch(s.kit.Repo, s.kit.ChatLink, "feature"))
+
+	s.restarted()
+	s.Empty(s.kit.Messenger.Notifications())
+}
+
+func (s *WorkflowsTestSuite) TestBranch_Rebase_Resolved() {
+	head := s.kit.Remote.Commit("feature", "add feature", map[string]string{"feature.txt": lines(1)})
+
+	s.kit.Remote.Checkout(testkit.DefaultBranch)
+	s.kit.Remote.Commit(testkit.DefaultBranch, "add main", map[string]string{"main.txt": lines(1)})
+
+	s.kit.Signal(time.Second, defs.SignalRebase, s.kit.Rebase(testkit.DefaultBranch, head))
+	s.kit.Restart(time.Minute, defs.SignalPRReview, s.kit.Review())
+
+	state := states.NewBranch(s.kit.Repo, s.kit.ChatLink, "feature")
+	state.Conflicted = true
+
+	s.env.ExecuteWorkflow(workflows.Branch, state)
+
+	s.restarted()
+
+	notifications := s.kit.Messenger.Notifications(kernel.NotificationKindStatus)
+	if s.Len(notifications, 1) {
+		s.Equal("conflicts resolved", notifications[0].Status)
+		s.Equal(kernel.BranchThread(s.kit.Repo.ID, "feature"), notifications[0].Thread)
+	}
+}
+
+func (s *WorkflowsTestSuite) TestBranch_Rebase_Failed() {
+	head := s.kit.Remote.Commit("feature", "add feature", map[string]string{"feature.txt": lines(1)})
+
+	// the base can't be fetched, so the rebase fails without conflicts.
+	s.kit.Signal(time.Second, defs.SignalRebase, s.kit.Rebase("missing", head))
+	s.kit.Restart(time.Minute, defs.SignalPRReview, s.kit.Review())
+
+	state := states.NewBranch(s.kit.Repo, s.kit.ChatLink, "feature")
+	state.Conflicted = true
+
+	s.env.ExecuteWorkflow(workflows.Branch, state)
+
+	s.restarted()
+	s.Empty(s.kit.Messenger.Notifications(kernel.NotificationKindStatus))
+}
+
+func (s *WorkflowsTestSuite) TestBranch_RebaseNow() {
+	sha := s.kit.Remote.Commit("feature", "add feature", map[string]string{"feature.txt": lines(1)})
+
+	s.kit.Signal(time.Second, defs.SignalPush, s.kit.Push("feature", sha))
+	s.kit.Signal(time.Second*2, defs.SignalRebase, s.kit.Rebase("feature", "trunk-sha"))
+	s.kit.Signal(time.Second*3, defs.SignalChatAction, kernel.NewChatAction(kernel.ChatActionRebase, s.kit.Repo.ID, "feature"))
+	s.kit.Restart(time.Minute, defs.SignalPRReview, s.kit.Review())
+
+	s.env.ExecuteWorkflow(workflows.Branch, states.NewBranch(s.kit.Repo, s.kit.ChatLink, "feature"))
+
+	s.restarted()
+
+	// the rebase requested from chat is built like the rebases of the repo, on the head of the default branch.
+	if requested := s.kit.Events(events.ScopeRebase, events.ActionRequested); s.Len(requested, 1) {
+		rebase := &eventsv1.Rebase{}
+		s.NoError(protojson.Unmarshal([]byte(requested[0].Payload), rebase))
+		s.Equal("feature", rebase.GetBase())
+		s.Equal("trunk-sha", rebase.GetHead())
+		s.Equal(s.kit.Repo.Name, rebase.GetRepository())
+	}
+}
+
+func (s *WorkflowsTestSuite) TestBranch_Label() {
+	s.kit.Signal(time.Second, defs.SignalPullRequestLabel, s.kit.Label(1, "feature", "qmerge"))
+	s.kit.Restart(time.Minute, defs.SignalPRReview, s.kit.Review())
+
+	s.env.ExecuteWorkflow(workflows.Branch, states.NewBranch(s.kit.Repo, s.kit.ChatLink, "feature"))
+
+	s.restarted()
+	s.Empty(s.kit.Messenger.Notifications())
+}
+
+func (s *WorkflowsTestSuite) TestBranch_Deleted() {
+	sha := s.kit.Remote.Commit("feature", "add feature", map[string]string{"feature.txt": lines(1)})
+
+	deleted := s.kit.Ref("feature", events.ActionDeleted)
+	push := s.kit.Push("feature", sha)
+
+	// the push is received in the same workflow task as the deletion, and handled before the branch exits.
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflowSkippingWorkflowTask(defs.SignalRef.String(), deleted)
+		s.env.SignalWorkflow(defs.SignalPush.String(), push)
+	}, time.Second)
+
+	s.env.ExecuteWorkflow(workflows.Branch, states.NewBranch(s.kit.Repo, s.kit.ChatLink, "feature"))
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+
+	status := &defs.BranchStatus{}
+	if value, err := s.env.QueryWorkflow(defs.QueryBranchForStatus.String()); s.NoError(err) {
+		s.NoError(value.Get(status))
+		s.Equal(sha, status.LatestCommit.GetSha())
+	}
+}
+
+func (s *WorkflowsTestSuite) TestBranch_Recreated() {
+	deleted := s.kit.Ref("feature", events.ActionDeleted)
+	created := s.kit.Ref("feature", events.ActionCreated)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflowSkippingWorkflowTask(defs.SignalRef.String(), deleted)
+		s.env.SignalWorkflow(defs.SignalRef.String(), created)
+	}, time.Second)
+
+	s.kit.Restart(time.Minute, defs.SignalPRReview, s.kit.Review())
+
+	s.env.ExecuteWorkflow(workflows.Branch, states.NewBranch(s.kit.Repo, s.kit.ChatLink, "feature"))
+
+	s.restarted()
+}
+
+func (s *WorkflowsTestSuite) TestBranch_Idle() {
+	s.kit.Signal(defs.BranchIdleExpiry/2, defs.SignalPRReview, s.kit.Review())
+
+	start := s.env.Now()
+
+	s.env.ExecuteWorkflow(workflows.Branch, states.NewBranch(s.kit.Repo, s.kit.ChatLink, "feature"))
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+
+	// the review restarts the expiry.
+	s.GreaterOrEqual(s.env.Now().Sub(start), defs.BranchIdleExpiry+defs.BranchIdleExpiry/2)
+}
+
+// - repo -
+
+func (s *WorkflowsTestSuite) TestRepo_Routing() {
+	created := s.kit.Ref("feature", events.ActionCreated)
+	push := s.kit.Push("feature", "a1")
+
+	s.kit.Signal(time.Second, defs.SignalRef, created)
+	s.kit.Signal(time.Second*2, defs.SignalPush, push)
+	s.kit.Signal(time.Second*3, defs.SignalPush, s.kit.Push(testkit.DefaultBranch, "b1"))
+	s.kit.Signal(time.Second*4, defs.SignalPullRequest, s.kit.PullRequest(1, "feature", events.ActionClosed))
+	s.kit.Signal(time.Second*5, defs.SignalPullRequest, s.kit.PullRequest(2, "feature", events.ActionCreated))
+	s.kit.Signal(time.Second*6, defs.SignalPullRequest, s.kit.PullRequest(3, "feature", events.ActionMerged))
+
+	parent := uuid.Nil
+	s.kit.Query(time.Minute, defs.QueryRepoForEventParent, &parent, func(err error) {
+		s.NoError(err)
+	}, "feature")
+
+	s.kit.Restart(time.Minute*2, defs.SignalPRReview, s.kit.Review())
+
+	s.env.ExecuteWorkflow(workflows.Repo, states.NewRepo(s.kit.Repo, s.kit.ChatLink))
+
+	s.restarted()
+	s.Equal(push.ID, parent)
+
+	if refs := s.kit.Forwards(defs.SignalRef); s.Len(refs, 1) {
+		s.Equal("feature", refs[0].Branch)
+	}
+
+	if pushes := s.kit.Forwards(defs.SignalPush); s.Len(pushes, 1) {
+		s.Equal("feature", pushes[0].Branch)
+		s.Contains(string(pushes[0].Event), push.ID.String())
+	}
+
+	if rebases := s.kit.Forwards(defs.SignalRebase); s.Len(rebases, 1) {
+		s.Equal("feature", rebases[0].Branch)
+	}
+
+	s.Len(s.kit.Events(events.ScopeRebase, events.ActionRequested), 1)
+
+	// only the closed and the merged pull requests leave the merge queue, the trunk is signaled with an empty branch.
+	if mq := s.kit.Forwards(defs.SignalMergeQueue); s.Len(mq, 2) {
+		s.Empty(mq[0].Branch)
+		s.Empty(mq[1].Branch)
+	}
+}
+
+func (s *WorkflowsTestSuite) TestRepo_RefDeleted() {
+	s.kit.Signal(time.Second, defs.SignalRef, s.kit.Ref("feature", events.ActionCreated))
+	s.kit.Signal(time.Second*2, defs.SignalRef, s.kit.Ref("feature", events.ActionDeleted))
+	s.kit.Signal(time.Second*3, defs.SignalPush, s.kit.Push(testkit.DefaultBranch, "b1"))
+
+	parent := uuid.Nil
+	s.kit.Query(time.Minute, defs.QueryRepoForEventParent, &parent, func(err error) {
+		s.Error(err)
+	}, "feature")
+
+	s.kit.Restart(time.Minute*2, defs.SignalPRReview, s.kit.Review())
+
+	s.env.ExecuteWorkflow(workflows.Repo, states.NewRepo(s.kit.Repo, s.kit.ChatLink))
+
+	s.restarted()
+	s.Empty(s.kit.Forwards(defs.SignalRebase))
+
+	// the deletion must not start a workflow for the deleted branch.
+	if refs := s.kit.Forwards(defs.SignalRef); s.Len(refs, 2) {
+		s.True(refs[0].Start)
+		s.False(refs[1].Start)
+	}
+}
+
+func (s *WorkflowsTestSuite) TestRepo_SyncTriggers() {
+	s.kit.Remote.Commit("feature", "add feature", map[string]string{"feature.txt": lines(1)})
+
+	s.kit.Signal(time.Second, defs.SignalRef, s.kit.Ref("gone", events.ActionCreated))
+	s.kit.Signal(time.Second*2, defs.SignalSyncTriggers, &defs.SyncTriggersPayload{Reason: "test"})
+
+	triggers := states.BranchTriggers{}
+	s.kit.Query(time.Minute, defs.QueryRepoForTriggers, &triggers, func(err error) {
+		s.NoError(err)
+	})
+
+	s.kit.Restart(time.Minute*2, defs.SignalPRReview, s.kit.Review())
+
+	s.env.ExecuteWorkflow(workflows.Repo, states.NewRepo(s.kit.Repo, s.kit.ChatLink))
+
+	s.restarted()
+	s.Len(triggers, 1)
+	s.Contains(triggers, "feature")
+
+	if created := s.kit.Events(events.ScopeBranch, events.ActionCreated); s.Len(created, 1) {
+		s.Equal(triggers["feature"], created[0].ID)
+	}
+}
+
+func (s *WorkflowsTestSuite) TestRepo_Migrate() {
+	push := s.kit.Push("feature", "a1")
+
+	s.kit.Signal(time.Second, defs.SignalPush, push)
+
+	parent := uuid.Nil
+	s.kit.Query(time.Minute, defs.QueryRepoForEventParent, &parent, func(err error) {
+		s.NoError(err)
+	}, "feature")
+
+	s.kit.Restart(time.Minute*2, defs.SignalPRReview, s.kit.Review())
+
+	// the state of a run that started before the triggers were initialized.
+	state := states.NewRepo(s.kit.Repo, s.kit.ChatLink)
+	state.Triggers = nil
+
+	s.env.ExecuteWorkflow(workflows.Repo, state)
+
+	s.restarted()
+	s.Equal(push.ID, parent)
+
+	can := &workflow.ContinueAsNewError{}
+	if s.ErrorAs(s.env.GetWorkflowError(), &can) {
+		next := &states.Repo{}
+		s.NoError(converter.GetDefaultDataConverter().FromPayloads(can.Input, next))
+		s.Equal(workflow.Version(1), next.Schema)
+		s.NotNil(next.Triggers)
+	}
+}
+
+// - trunk -
+
+func (s *WorkflowsTestSuite) TestTrunk_Lifecycle() {
+	s.kit.Signal(time.Second, defs.SignalQueueFreeze, &defs.QueueFreezePayload{Frozen: true})
+	s.kit.Signal(time.Second*2, defs.SignalMergeQueue, s.kit.MergeQueue(1, "one", events.EventActionAdded))
+	s.kit.Signal(time.Second*3, defs.SignalMergeQueue, s.kit.MergeQueue(2, "two", events.EventActionAdded))
+
+	frozen := &defs.QueueStatus{}
+	s.kit.Query(time.Second*4, defs.QueryTrunkForQueue, frozen, func(err error) {
+		s.NoError(err)
+	})
+
+	s.kit.Signal(time.Second*5, defs.SignalQueueFreeze, &defs.QueueFreezePayload{Frozen: false})
+	s.kit.Signal(time.Second*6, defs.SignalMergeQueue, s.kit.MergeQueue(1, "one", events.ActionClosed))
+
+	status := &defs.QueueStatus{}
+	s.kit.Query(time.Second*7, defs.QueryTrunkForQueue, status, func(err error) {
+		s.NoError(err)
+	})
+
+	s.kit.Restart(time.Minute, defs.SignalQueueFreeze, &defs.QueueFreezePayload{Frozen: true})
+
+	s.env.ExecuteWorkflow(workflows.Trunk, states.NewTrunk(s.kit.Repo, s.kit.ChatLink))
+
+	s.restarted()
+
+	s.True(frozen.Frozen)
+	s.Len(frozen.Items, 2)
+	s.Empty(frozen.InFlight)
+
+	// the first item is picked for testing once unfrozen, the second one waits for it to be merged on close.
+	s.False(status.Frozen)
+	s.Empty(status.Items)
+
+	if s.Len(status.InFlight, 1) {
+		s.Equal(int64(2), status.InFlight[0].GetNumber())
+	}
+
+	s.Len(s.kit.Events(events.ScopeMergeQueue, events.ActionEnqueued), 2)
+	s.Len(s.kit.Events(events.ScopeMergeQueue, events.ActionTesting), 2)
+
+	actions := make([]events.Action, 0)
+	for _, record := range s.kit.Events(events.ScopeMergeQueue, events.ActionTesting, events.ActionMerged) {
+		actions = append(actions, record.Action)
+	}
+
+	// the second item waits in the queue until the first one is merged.
+	s.Equal([]events.Action{events.ActionTesting, events.ActionMerged, events.ActionTesting}, actions)
+
+	if merged := s.kit.Events(events.ScopeMergeQueue, events.ActionMerged); s.Len(merged, 1) {
+		s.Len(merged[0].Parents, 1, "merged must be chained to the testing event")
+	}
+
+	// the thread of each pull request is started once queued, and closed once merged.
+	s.Len(s.kit.Messenger.Notifications(kernel.NotificationKindQueuePosition), 2)
+
+	statuses := make([]string, 0)
+	for _, notification := range s.kit.Messenger.Notifications(kernel.NotificationKindStatus) {
+		statuses = append(statuses, notification.Thread.Ref+": "+notification.Status)
+	}
+
+	s.Equal([]string{"pr/1: testing", "pr/1: merged", "pr/2: testing"}, statuses)
+
+	if merged := s.kit.Messenger.Notifications(kernel.NotificationKindStatus); s.Len(merged, 3) {
+		s.True(merged[1].Final)
+	}
+
+	// the in-flight merges are carried over to the next run.
+	can := &workflow.ContinueAsNewError{}
+	if s.ErrorAs(s.env.GetWorkflowError(), &can) {
+		next := &states.Trunk{}
+		s.NoError(converter.GetDefaultDataConverter().FromPayloads(can.Input, next))
+
+		if s.Len(next.InFlight, 1) {
+			s.Equal(int64(2), next.InFlight[0].GetNumber())
+		}
+	}
+}
@@ -64,3 +64,59 @@ func (q *Queries) GetChatLink(ctx context.Context, linkTo uuid.UUID) (ChatLink,
 	)
 	return i, err
 }
+
+const getChatLinkByChannel = `-- name: GetChatLinkByChannel :one
+SELECT id, created_at, updated_at, hook, kind, link_to, data
+FROM chat_links
+WHERE hook = $1 AND kind = 'bot'
+  AND data->>'channel_id' = $2::text
+`
+
+type GetChatLinkByChannelParams struct {
+	Hook      int32  `json:"hook"`
+	ChannelID string `json:"channel_id"`
+}
+
+func (q *Queries) GetChatLinkByChannel(ctx context.Context, arg GetChatLinkByChannelParams) (ChatLink, error) {
+	row := q.db.QueryRow(ctx, getChatLinkByChannel, arg.Hook, arg.ChannelID)
+	var i ChatLink
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Hook,
+		&i.Kind,
+		&i.LinkTo,
+		&i.Data,
+	)
+	return i, err
+}
+
+const getChatLinkByProviderUser = `-- name: GetChatLinkByProviderUser :one
+SELECT id, created_at, updated_at, hook, kind, link_to, data
+FROM chat_links
+WHERE hook = $1 AND kind = 'user'
+  AND data->>'provider_user_id' = $2::text
+  AND data->>'provider_team_id' = $3::text
+`
+
+type GetChatLinkByProviderUserParams struct {
+	Hook           int32  `json:"hook"`
+	ProviderUserID string `json:"provider_user_id"`
+	ProviderTeamID string `json:"provider_team_id"`
+}
+
+func (q *Queries) GetChatLinkByProviderUser(ctx context.Context, arg GetChatLinkByProviderUserParams) (ChatLink, error) {
+	row := q.db.QueryRow(ctx, getChatLinkByProviderUser, arg.Hook, arg.ProviderUserID, arg.ProviderTeamID)
+	var i ChatLink
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Hook,
+		&i.Kind,
+		&i.LinkTo,
+		&i.Data,
+	)
+	return i, err
+}
@@ -65,6 +65,41 @@ type ChatLink struct {
 	Data      []byte    `json:"data"`
 }
 
+type ChatThread struct {
+	ID          uuid.UUID `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Hook        int32     `json:"hook"`
+	SubjectID   uuid.UUID `json:"subject_id"`
+	Ref         string    `json:"ref"`
+	ChannelID   string    `json:"channel_id"`
+	Ts          string    `json:"ts"`
+	Status      string    `json:"status"`
+	Data        []byte    `json:"data"`
+	Recipient   string    `json:"recipient"`
+	DismissedBy string    `json:"dismissed_by"`
+}
+
+type EventRetention struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	OrgID     uuid.UUID `json:"org_id"`
+	Days      int32     `json:"days"`
+}
+
+type EventSink struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	OrgID     uuid.UUID `json:"org_id"`
+	Kind      string    `json:"kind"`
+	Mode      string    `json:"mode"`
+	Target    string    `json:"target"`
+	IsActive  bool      `json:"is_active"`
+	Headers   []byte    `json:"headers"`
+}
+
 type GithubInstallation struct {
 	ID                  uuid.UUID `json:"id"`
 	CreatedAt           time.Time `json:"created_at"`
@@ -155,6 +190,7 @@ type Team struct {
 	OrgID     uuid.UUID `json:"org_id"`
 	Name      string    `json:"name"`
 	Slug      string    `json:"slug"`
+	Timezone  string    `json:"timezone"`
 }
 
 type TeamUser struct {
@@ -72,6 +72,38 @@ func (q *Queries) GetOrgSlugByID(ctx context.Context, id uuid.UUID) (string, err
 	return slug, err
 }
 
+const listOrgsWithRetention = `-- name: ListOrgsWithRetention :many
+SELECT org.id, org.slug, COALESCE(retention.days, 0)::integer AS days
+FROM orgs org
+LEFT JOIN event_retention retention ON retention.org_id = org.id
+`
+
+type ListOrgsWithRetentionRow struct {
+	ID   uuid.UUID `json:"id"`
+	Slug string    `json:"slug"`
+	Days int32     `json:"days"`
+}
+
+func (q *Queries) ListOrgsWithRetention(ctx context.Context) ([]ListOrgsWithRetentionRow, error) {
+	rows, err := q.db.Query(ctx, listOrgsWithRetention)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListOrgsWithRetentionRow
+	for rows.Next() {
+		var i ListOrgsWithRetentionRow
+		if err := rows.Scan(&i.ID, &i.Slug, &i.Days); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const setOrgHooks = `-- name: SetOrgHooks :exec
 UPDATE orgs
 SET hooks = $2
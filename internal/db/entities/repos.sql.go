@@ -172,6 +172,38 @@ func (q *Queries) GetRepoByID(ctx context.Context, id uuid.UUID) (Repo, error) {
 	return i, err
 }
 
+const getRepoByOrgIDAndName = `-- name: GetRepoByOrgIDAndName :one
+SELECT id, created_at, updated_at, org_id, name, hook, hook_id, default_branch, is_monorepo, threshold, stale_duration, url, is_active
+FROM repos
+WHERE org_id = $1 AND name = $2
+`
+
+type GetRepoByOrgIDAndNameParams struct {
+	OrgID uuid.UUID `json:"org_id"`
+	Name  string    `json:"name"`
+}
+
+func (q *Queries) GetRepoByOrgIDAndName(ctx context.Context, arg GetRepoByOrgIDAndNameParams) (Repo, error) {
+	row := q.db.QueryRow(ctx, getRepoByOrgIDAndName, arg.OrgID, arg.Name)
+	var i Repo
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OrgID,
+		&i.Name,
+		&i.Hook,
+		&i.HookID,
+		&i.DefaultBranch,
+		&i.IsMonorepo,
+		&i.Threshold,
+		&i.StaleDuration,
+		&i.Url,
+		&i.IsActive,
+	)
+	return i, err
+}
+
 const getRepoForGithub = `-- name: GetRepoForGithub :one
 SELECT
  repo.id, repo.created_at, repo.updated_at, repo.org_id, repo.name, repo.hook, repo.hook_id, repo.default_branch, repo.is_monorepo, repo.threshold, repo.stale_duration, repo.url, repo.is_active,
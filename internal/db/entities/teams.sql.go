@@ -14,7 +14,7 @@ import (
 const createTeam = `-- name: CreateTeam :one
 INSERT INTO teams (name, org_id)
 VALUES ($1, $2)
-RETURNING id, created_at, updated_at, org_id, name, slug
+RETURNING id, created_at, updated_at, org_id, name, slug, timezone
 `
 
 type CreateTeamParams struct {
@@ -32,6 +32,7 @@ func (q *Queries) CreateTeam(ctx context.Context, arg CreateTeamParams) (Team, e
 		&i.OrgID,
 		&i.Name,
 		&i.Slug,
+		&i.Timezone,
 	)
 	return i, err
 }
@@ -47,7 +48,7 @@ func (q *Queries) DeleteTeam(ctx context.Context, id uuid.UUID) error {
 }
 
 const getTeam = `-- name: GetTeam :one
-SELECT id, created_at, updated_at, org_id, name, slug
+SELECT id, created_at, updated_at, org_id, name, slug, timezone
 FROM teams
 WHERE id = $1
 LIMIT 1
@@ -63,12 +64,13 @@ func (q *Queries) GetTeam(ctx context.Context, id uuid.UUID) (Team, error) {
 		&i.OrgID,
 		&i.Name,
 		&i.Slug,
+		&i.Timezone,
 	)
 	return i, err
 }
 
 const getTeamByID = `-- name: GetTeamByID :one
-SELECT id, created_at, updated_at, org_id, name, slug
+SELECT id, created_at, updated_at, org_id, name, slug, timezone
 FROM teams
 WHERE id = $1
 `
@@ -83,6 +85,7 @@ func (q *Queries) GetTeamByID(ctx context.Context, id uuid.UUID) (Team, error) {
 		&i.OrgID,
 		&i.Name,
 		&i.Slug,
+		&i.Timezone,
 	)
 	return i, err
 }
@@ -109,7 +112,7 @@ const updateTeam = `-- name: UpdateTeam :one
 UPDATE teams
 SET name = $2
 WHERE id = $1
-RETURNING id, created_at, updated_at, org_id, name, slug
+RETURNING id, created_at, updated_at, org_id, name, slug, timezone
 `
 
 type UpdateTeamParams struct {
@@ -127,6 +130,7 @@ func (q *Queries) UpdateTeam(ctx context.Context, arg UpdateTeamParams) (Team, e
 		&i.OrgID,
 		&i.Name,
 		&i.Slug,
+		&i.Timezone,
 	)
 	return i, err
 }
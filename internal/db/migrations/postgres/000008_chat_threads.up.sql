@@ -0,0 +1,23 @@
+-- chat_links::chat_threads::create
+create table chat_threads (
+  id uuid primary key default uuid_generate_v7(),
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  hook integer not null,
+  subject_id uuid not null,
+  ref varchar(255) not null,
+  channel_id varchar(255) not null,
+  ts varchar(255) not null,
+  status varchar(255) not null default '',
+  data jsonb not null default '{}',
+  constraint chat_threads_hook_subject_id_ref_unique unique (hook, subject_id, ref)
+);
+
+-- chat_links::chat_threads::index
+create index chat_threads_subject_id_idx on chat_threads (subject_id);
+
+-- chat_links::chat_threads::trigger
+create trigger update_chat_threads_updated_at
+  after update on chat_threads
+  for each row
+  execute function update_updated_at();
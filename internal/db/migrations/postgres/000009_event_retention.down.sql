@@ -0,0 +1,2 @@
+drop trigger if exists update_event_retention_updated_at on event_retention;
+drop table if exists event_retention;
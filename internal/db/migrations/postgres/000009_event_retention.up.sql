@@ -0,0 +1,14 @@
+-- pulse::event_retention::create
+create table event_retention (
+  id uuid primary key default uuid_generate_v7(),
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  org_id uuid not null unique references orgs (id) on delete cascade,
+  days integer not null default 0 check (days >= 0)
+);
+
+-- pulse::event_retention::trigger
+create trigger update_event_retention_updated_at
+  after update on event_retention
+  for each row
+  execute function update_updated_at();
@@ -0,0 +1,3 @@
+drop trigger if exists update_event_sinks_updated_at on event_sinks;
+drop index if exists idx_event_sinks_org_id;
+drop table if exists event_sinks;
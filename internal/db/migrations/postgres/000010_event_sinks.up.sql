@@ -0,0 +1,20 @@
+-- pulse::event_sinks::create
+create table event_sinks (
+  id uuid primary key default uuid_generate_v7(),
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  org_id uuid not null references orgs (id) on delete cascade,
+  kind varchar(16) not null check (kind in ('http', 'file')),
+  mode varchar(16) not null default 'structured' check (mode in ('structured', 'binary')),
+  target varchar(1024) not null,
+  is_active boolean not null default true
+);
+
+-- pulse::event_sinks::index
+create index idx_event_sinks_org_id on event_sinks (org_id);
+
+-- pulse::event_sinks::trigger
+create trigger update_event_sinks_updated_at
+  after update on event_sinks
+  for each row
+  execute function update_updated_at();
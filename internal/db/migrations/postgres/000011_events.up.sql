@@ -0,0 +1,26 @@
+-- pulse::events::create
+-- events are stored here by the postgres event sink, with a partition per org created when the org is migrated.
+create table events (
+  version varchar(16) not null,
+  id uuid not null,
+  parents uuid[] not null default '{}',
+  hook integer not null,
+  scope varchar(64) not null,
+  action varchar(64) not null,
+  source text not null default '',
+  subject_id uuid not null,
+  subject_name varchar(64) not null,
+  user_id uuid not null,
+  team_id uuid not null,
+  org_id uuid not null,
+  timestamp timestamptz not null,
+  payload jsonb not null default '{}',
+  primary key (org_id, id)
+) partition by list (org_id);
+
+-- pulse::events::index
+create index idx_events_subject on events (org_id, subject_id, timestamp);
+
+create index idx_events_timestamp on events (org_id, timestamp);
+
+create index idx_events_parents on events using gin (parents);
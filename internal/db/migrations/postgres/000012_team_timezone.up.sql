@@ -0,0 +1,3 @@
+-- auth::teams::timezone
+alter table teams
+  add column timezone varchar(64) not null default 'UTC';
@@ -0,0 +1,11 @@
+drop index if exists chat_threads_channel_id_ts_idx;
+
+delete from chat_threads where recipient <> '';
+
+alter table chat_threads
+  drop constraint if exists chat_threads_hook_subject_id_ref_recipient_unique,
+  add constraint chat_threads_hook_subject_id_ref_unique unique (hook, subject_id, ref);
+
+alter table chat_threads
+  drop column if exists dismissed_by,
+  drop column if exists recipient;
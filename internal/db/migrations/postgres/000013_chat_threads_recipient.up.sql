@@ -0,0 +1,14 @@
+-- chat_links::chat_threads::recipient
+-- threads are kept per recipient, as the same subject may be notified to a user and to a channel. the threads started
+-- before are left without a recipient, and are never found again; the next notification on their subject starts a new
+-- thread.
+alter table chat_threads
+  add column recipient varchar(255) not null default '',
+  add column dismissed_by varchar(255) not null default '';
+
+alter table chat_threads
+  drop constraint chat_threads_hook_subject_id_ref_unique,
+  add constraint chat_threads_hook_subject_id_ref_recipient_unique unique (hook, subject_id, ref, recipient);
+
+-- chat_links::chat_threads::index
+create index chat_threads_channel_id_ts_idx on chat_threads (channel_id, ts);
@@ -0,0 +1,6 @@
+alter table event_sinks
+  drop constraint if exists event_sinks_kind_check,
+  add constraint event_sinks_kind_check check (kind in ('http', 'file'));
+
+alter table event_sinks
+  drop column if exists headers;
@@ -0,0 +1,12 @@
+-- pulse::event_sinks::headers
+-- the headers are set on every request of the http sink, e.g. for authorization.
+alter table event_sinks
+  add column headers jsonb not null default '{}';
+
+-- pulse::event_sinks::kind
+-- the file sinks wrote to the local disk of whichever worker ran the export, and are dropped.
+delete from event_sinks where kind = 'file';
+
+alter table event_sinks
+  drop constraint event_sinks_kind_check,
+  add constraint event_sinks_kind_check check (kind in ('http'));
@@ -7,3 +7,16 @@ RETURNING *;
 SELECT *
 FROM chat_links
 WHERE link_to = $1;
+
+-- name: GetChatLinkByProviderUser :one
+SELECT *
+FROM chat_links
+WHERE hook = $1 AND kind = 'user'
+  AND data->>'provider_user_id' = sqlc.arg(provider_user_id)::text
+  AND data->>'provider_team_id' = sqlc.arg(provider_team_id)::text;
+
+-- name: GetChatLinkByChannel :one
+SELECT *
+FROM chat_links
+WHERE hook = $1 AND kind = 'bot'
+  AND data->>'channel_id' = sqlc.arg(channel_id)::text;
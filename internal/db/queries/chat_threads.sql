@@ -0,0 +1,28 @@
+-- name: CreateChatThread :one
+INSERT INTO chat_threads (hook, subject_id, ref, recipient, channel_id, ts, status, data)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetChatThread :one
+SELECT *
+FROM chat_threads
+WHERE hook = $1 AND subject_id = $2 AND ref = $3 AND recipient = $4;
+
+-- name: UpdateChatThreadStatus :exec
+UPDATE chat_threads
+SET status = $2
+WHERE id = $1;
+
+-- name: UpdateChatThreadRoot :exec
+UPDATE chat_threads
+SET channel_id = $2, ts = $3
+WHERE id = $1;
+
+-- name: DismissChatThread :exec
+UPDATE chat_threads
+SET dismissed_by = $4
+WHERE hook = $1 AND channel_id = $2 AND ts = $3;
+
+-- name: DeleteChatThread :exec
+DELETE FROM chat_threads
+WHERE hook = $1 AND subject_id = $2 AND ref = $3 AND recipient = $4;
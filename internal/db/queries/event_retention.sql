@@ -0,0 +1,10 @@
+-- name: GetEventRetention :one
+SELECT days
+FROM event_retention
+WHERE org_id = $1;
+
+-- name: SetEventRetention :exec
+INSERT INTO event_retention (org_id, days)
+VALUES ($1, $2)
+ON CONFLICT (org_id) DO UPDATE
+SET days = EXCLUDED.days;
@@ -0,0 +1,18 @@
+-- name: CreateEventSink :one
+INSERT INTO event_sinks (org_id, kind, mode, target, headers)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: ListEventSinks :many
+SELECT *
+FROM event_sinks
+WHERE org_id = $1 AND is_active = true;
+
+-- name: DeactivateEventSink :exec
+UPDATE event_sinks
+SET is_active = false
+WHERE id = $1;
+
+-- name: DeleteEventSink :exec
+DELETE FROM event_sinks
+WHERE id = $1;
@@ -13,6 +13,11 @@ SELECT slug
 FROM orgs
 WHERE id = $1;
 
+-- name: ListOrgsWithRetention :many
+SELECT org.id, org.slug, COALESCE(retention.days, 0)::integer AS days
+FROM orgs org
+LEFT JOIN event_retention retention ON retention.org_id = org.id;
+
 -- name: SetOrgHooks :exec
 UPDATE orgs
 SET hooks = $2
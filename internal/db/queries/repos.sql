@@ -8,6 +8,11 @@ SELECT *
 FROM repos
 WHERE id = $1;
 
+-- name: GetRepoByOrgIDAndName :one
+SELECT *
+FROM repos
+WHERE org_id = $1 AND name = $2;
+
 -- name: UpdateRepo :one
 UPDATE repos
 SET
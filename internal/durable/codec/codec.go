@@ -0,0 +1,287 @@
+// Crafted with ❤ at Breu, Inc. <info@breu.io>, Copyright © 2024.
+//
+// Functional Source License, Version 1.1, Apache 2.0 Future License
+//
+// We hereby irrevocably grant you an additional license to use the Software under the Apache License, Version 2.0 that
+// is effective on the second anniversary of the date we make the Software available. On or after that date, you may use
+// the Software under the Apache License, Version 2.0, in which case the following will apply:
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+// the License.
+//
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package codec
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	commonpb "go.temporal.io/api/common/v1"
+	"go.temporal.io/sdk/converter"
+	"google.golang.org/protobuf/proto"
+)
+
+type (
+	// Encryption is the converter.PayloadCodec encrypting the payloads with AES-GCM. The first key encrypts, all the
+	// keys decrypt. The ID of the key is recorded in the metadata of the payload.
+	Encryption struct {
+		keys []*key
+	}
+
+	// Compression is the converter.PayloadCodec compressing the payloads with zstd. Payloads smaller than MinCompressSize,
+	// or not getting smaller, are left as they are.
+	Compression struct {
+		encoder *zstd.Encoder
+		decoder *zstd.Decoder
+	}
+
+	// key is an AES-GCM key with its ID.
+	key struct {
+		id   string
+		aead cipher.AEAD
+	}
+)
+
+const (
+	EncodingEncrypted = "binary/encrypted"  // EncodingEncrypted is the encoding of the payloads encrypted by Encryption.
+	EncodingZstd      = "binary/zstd"       // EncodingZstd is the encoding of the payloads compressed by Compression.
+	MetadataKeyID     = "encryption-key-id" // MetadataKeyID is the metadata holding the ID of the encryption key.
+
+	MinCompressSize = 512      // MinCompressSize is the size in bytes below which the payloads are not compressed.
+	MaxDecodedSize  = 64 << 20 // MaxDecodedSize is the maximum size in bytes of a decompressed payload.
+)
+
+var (
+	ErrUnknownKey = errors.New("codec: unknown encryption key")
+	ErrNoSecret   = errors.New("codec: secret is required")
+)
+
+// New returns the codecs to encode the payloads with, in the order expected by DataConverter. The secret encrypts the
+// payloads, the previous secrets only decrypt them.
+func New(secret string, previous ...string) ([]converter.PayloadCodec, error) {
+	encryption, err := NewEncryption(secret, previous...)
+	if err != nil {
+		return nil, err
+	}
+
+	compression, err := NewCompression()
+	if err != nil {
+		return nil, err
+	}
+
+	// codecs are applied last to first on encode, i.e. the payloads are compressed before they are encrypted.
+	return []converter.PayloadCodec{encryption, compression}, nil
+}
+
+// DataConverter returns the default data converter of temporal, encoding the payloads with the codecs.
+func DataConverter(codecs ...converter.PayloadCodec) converter.DataConverter {
+	return converter.NewCodecDataConverter(converter.GetDefaultDataConverter(), codecs...)
+}
+
+// NewEncryption returns the encryption codec. The secret encrypts the payloads, the previous secrets only decrypt them.
+func NewEncryption(secret string, previous ...string) (*Encryption, error) {
+	if secret == "" {
+		return nil, ErrNoSecret
+	}
+
+	e := &Encryption{}
+
+	for _, s := range append([]string{secret}, previous...) {
+		if s == "" {
+			continue
+		}
+
+		k, err := newkey(s)
+		if err != nil {
+			return nil, err
+		}
+
+		e.keys = append(e.keys, k)
+	}
+
+	return e, nil
+}
+
+// Encode encrypts the payloads with the current key.
+func (e *Encryption) Encode(payloads []*commonpb.Payload) ([]*commonpb.Payload, error) {
+	current := e.keys[0]
+	result := make([]*commonpb.Payload, len(payloads))
+
+	for idx, payload := range payloads {
+		plain, err := proto.Marshal(payload)
+		if err != nil {
+			return payloads, err
+		}
+
+		nonce := make([]byte, current.aead.NonceSize())
+		if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+			return payloads, err
+		}
+
+		result[idx] = &commonpb.Payload{
+			Metadata: map[string][]byte{
+				converter.MetadataEncoding: []byte(EncodingEncrypted),
+				MetadataKeyID:              []byte(current.id),
+			},
+			Data: current.aead.Seal(nonce, nonce, plain, nil),
+		}
+	}
+
+	return result, nil
+}
+
+// Decode decrypts the encrypted payloads with the key they were encrypted with. Other payloads are left as they are.
+func (e *Encryption) Decode(payloads []*commonpb.Payload) ([]*commonpb.Payload, error) {
+	result := make([]*commonpb.Payload, len(payloads))
+
+	for idx, payload := range payloads {
+		if string(payload.GetMetadata()[converter.MetadataEncoding]) != EncodingEncrypted {
+			result[idx] = payload
+			continue
+		}
+
+		k, err := e.key(string(payload.GetMetadata()[MetadataKeyID]))
+		if err != nil {
+			return payloads, err
+		}
+
+		data := payload.GetData()
+		if len(data) < k.aead.NonceSize() {
+			return payloads, errors.New("codec: encrypted payload too short")
+		}
+
+		plain, err := k.aead.Open(nil, data[:k.aead.NonceSize()], data[k.aead.NonceSize():], nil)
+		if err != nil {
+			return payloads, err
+		}
+
+		result[idx] = &commonpb.Payload{}
+		if err := proto.Unmarshal(plain, result[idx]); err != nil {
+			return payloads, err
+		}
+	}
+
+	return result, nil
+}
+
+// key returns the key with the ID.
+func (e *Encryption) key(id string) (*key, error) {
+	for _, k := range e.keys {
+		if k.id == id {
+			return k, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrUnknownKey, id)
+}
+
+// NewCompression returns the compression codec.
+func NewCompression() (*Compression, error) {
+	encoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault))
+	if err != nil {
+		return nil, err
+	}
+
+	decoder, err := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(MaxDecodedSize))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Compression{encoder: encoder, decoder: decoder}, nil
+}
+
+// Encode compresses the payloads worth compressing.
+func (c *Compression) Encode(payloads []*commonpb.Payload) ([]*commonpb.Payload, error) {
+	result := make([]*commonpb.Payload, len(payloads))
+
+	for idx, payload := range payloads {
+		result[idx] = payload
+
+		if len(payload.GetData()) < MinCompressSize {
+			continue
+		}
+
+		plain, err := proto.Marshal(payload)
+		if err != nil {
+			return payloads, err
+		}
+
+		compressed := c.encoder.EncodeAll(plain, nil)
+		if len(compressed) >= len(plain) {
+			continue
+		}
+
+		result[idx] = &commonpb.Payload{
+			Metadata: map[string][]byte{converter.MetadataEncoding: []byte(EncodingZstd)},
+			Data:     compressed,
+		}
+	}
+
+	return result, nil
+}
+
+// Decode decompresses the compressed payloads. Other payloads are left as they are.
+func (c *Compression) Decode(payloads []*commonpb.Payload) ([]*commonpb.Payload, error) {
+	result := make([]*commonpb.Payload, len(payloads))
+
+	for idx, payload := range payloads {
+		if string(payload.GetMetadata()[converter.MetadataEncoding]) != EncodingZstd {
+			result[idx] = payload
+			continue
+		}
+
+		plain, err := c.decoder.DecodeAll(payload.GetData(), nil)
+		if err != nil {
+			return payloads, err
+		}
+
+		result[idx] = &commonpb.Payload{}
+		if err := proto.Unmarshal(plain, result[idx]); err != nil {
+			return payloads, err
+		}
+	}
+
+	return result, nil
+}
+
+// newkey returns the AES-256 key of the secret. As for the sensitive fields of the db, the secret is truncated or
+// padded to 32 bytes. The ID is derived from the key, so that it is stable across deploys without being configured.
+func newkey(secret string) (*key, error) {
+	raw := []byte(secret)
+
+	if len(raw) > 32 {
+		raw = raw[:32]
+	}
+
+	if len(raw) < 32 {
+		raw = append(raw, make([]byte, 32-len(raw))...)
+	}
+
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(raw)
+
+	return &key{id: hex.EncodeToString(sum[:4]), aead: aead}, nil
+}
@@ -0,0 +1,177 @@
+package codec_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	commonpb "go.temporal.io/api/common/v1"
+	"go.temporal.io/sdk/converter"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"go.breu.io/quantm/internal/durable/codec"
+)
+
+type (
+	state struct {
+		Name string `json:"name"`
+		Diff string `json:"diff"`
+	}
+)
+
+func TestDataConverter_Roundtrip(t *testing.T) {
+	t.Parallel()
+
+	codecs, err := codec.New("secret")
+	require.NoError(t, err)
+
+	dc := codec.DataConverter(codecs...)
+
+	tests := []struct {
+		name string
+		in   state
+		want string // want is the encoding of the payload sent to temporal.
+	}{
+		{"small", state{Name: "quantm", Diff: "+ line"}, codec.EncodingEncrypted},
+		{"large", state{Name: "quantm", Diff: strings.Repeat("+ added line\n", 1000)}, codec.EncodingEncrypted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := dc.ToPayload(tt.in)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, string(payload.GetMetadata()[converter.MetadataEncoding]))
+			assert.False(t, bytes.Contains(payload.GetData(), []byte("quantm")), "payload holds the plain text")
+
+			out := state{}
+			require.NoError(t, dc.FromPayload(payload, &out))
+			assert.Equal(t, tt.in, out)
+		})
+	}
+}
+
+func TestCompression(t *testing.T) {
+	t.Parallel()
+
+	compression, err := codec.NewCompression()
+	require.NoError(t, err)
+
+	small := &commonpb.Payload{Data: []byte("small")}
+	large := &commonpb.Payload{Data: bytes.Repeat([]byte("large"), 1000)}
+
+	encoded, err := compression.Encode([]*commonpb.Payload{small, large})
+	require.NoError(t, err)
+
+	assert.Same(t, small, encoded[0], "small payloads are not compressed")
+	assert.Equal(t, codec.EncodingZstd, string(encoded[1].GetMetadata()[converter.MetadataEncoding]))
+	assert.Less(t, len(encoded[1].GetData()), len(large.GetData()))
+
+	decoded, err := compression.Decode(encoded)
+	require.NoError(t, err)
+
+	assert.Equal(t, small.GetData(), decoded[0].GetData())
+	assert.Equal(t, large.GetData(), decoded[1].GetData())
+}
+
+func TestEncryption_Rotation(t *testing.T) {
+	t.Parallel()
+
+	plain := &commonpb.Payload{
+		Metadata: map[string][]byte{converter.MetadataEncoding: []byte(converter.MetadataEncodingJSON)},
+		Data:     []byte(`{"name":"quantm"}`),
+	}
+
+	old, err := codec.NewEncryption("old")
+	require.NoError(t, err)
+
+	encoded, err := old.Encode([]*commonpb.Payload{plain})
+	require.NoError(t, err)
+
+	rotated, err := codec.NewEncryption("new", "old")
+	require.NoError(t, err)
+
+	decoded, err := rotated.Decode(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, plain.GetData(), decoded[0].GetData())
+
+	reencoded, err := rotated.Encode(decoded)
+	require.NoError(t, err)
+	assert.NotEqual(t, encoded[0].GetMetadata()[codec.MetadataKeyID], reencoded[0].GetMetadata()[codec.MetadataKeyID])
+
+	dropped, err := codec.NewEncryption("new")
+	require.NoError(t, err)
+
+	_, err = dropped.Decode(encoded)
+	require.ErrorIs(t, err, codec.ErrUnknownKey)
+
+	// payloads recorded before the encryption was enabled are read as they are.
+	passthrough, err := dropped.Decode([]*commonpb.Payload{plain})
+	require.NoError(t, err)
+	assert.Same(t, plain, passthrough[0])
+
+	_, err = codec.NewEncryption("")
+	require.ErrorIs(t, err, codec.ErrNoSecret)
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	codecs, err := codec.New("secret")
+	require.NoError(t, err)
+
+	encoded, err := codec.DataConverter(codecs...).ToPayloads(state{Name: "quantm"})
+	require.NoError(t, err)
+
+	body, err := protojson.Marshal(encoded)
+	require.NoError(t, err)
+
+	authorize := func(r *http.Request) error {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			return errors.New("invalid token")
+		}
+
+		return nil
+	}
+
+	handler := codec.Handler(authorize, codecs, codec.WithOrigins("https://ui.example.com"), codec.WithNamespace("quantm"))
+
+	request := func(method, token, namespace string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/codec/decode", bytes.NewReader(body))
+		r.Header.Set("Origin", "https://ui.example.com")
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set(codec.HeaderNamespace, namespace)
+
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		return w
+	}
+
+	preflight := request(http.MethodOptions, "", "")
+	assert.Equal(t, http.StatusNoContent, preflight.Code)
+	assert.Equal(t, "https://ui.example.com", preflight.Header().Get("Access-Control-Allow-Origin"))
+
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodPost, "", "quantm").Code)
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodPost, "invalid", "quantm").Code)
+	assert.Equal(t, http.StatusForbidden, request(http.MethodPost, "token", "other").Code)
+
+	decoded := request(http.MethodPost, "token", "quantm")
+	require.Equal(t, http.StatusOK, decoded.Code)
+
+	payloads := &commonpb.Payloads{}
+	require.NoError(t, protojson.Unmarshal(decoded.Body.Bytes(), payloads))
+
+	out := state{}
+	require.NoError(t, converter.GetDefaultDataConverter().FromPayloads(payloads, &out))
+	assert.Equal(t, "quantm", out.Name)
+}
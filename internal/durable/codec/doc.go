@@ -0,0 +1,38 @@
+// Crafted with ❤ at Breu, Inc. <info@breu.io>, Copyright © 2024.
+//
+// Functional Source License, Version 1.1, Apache 2.0 Future License
+//
+// We hereby irrevocably grant you an additional license to use the Software under the Apache License, Version 2.0 that
+// is effective on the second anniversary of the date we make the Software available. On or after that date, you may use
+// the Software under the Apache License, Version 2.0, in which case the following will apply:
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+// the License.
+//
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+// Package codec encrypts and compresses the payloads sent to temporal, so that the org data carried by the workflows,
+// e.g. the repos and the chat links in the workflow states, is never stored in plain text in the workflow histories.
+//
+// The codecs are chained on the data converter of the client, compressing with zstd first, then encrypting with
+// AES-GCM:
+//
+//	codecs, err := codec.New(secret)
+//	if err != nil {
+//	    return err
+//	}
+//
+//	options := client.Options{DataConverter: codec.DataConverter(codecs...)}
+//
+// Payloads not encoded by the codecs, e.g. the histories recorded before the codecs were enabled, are decoded as they
+// are. The secret is rotated by moving the current secret to the previous ones, so that the payloads encrypted with it
+// can still be decoded.
+//
+// The Temporal UI decodes the payloads through the codec server, see Handler.
+package codec
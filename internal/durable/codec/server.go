@@ -0,0 +1,111 @@
+// Crafted with ❤ at Breu, Inc. <info@breu.io>, Copyright © 2024.
+//
+// Functional Source License, Version 1.1, Apache 2.0 Future License
+//
+// We hereby irrevocably grant you an additional license to use the Software under the Apache License, Version 2.0 that
+// is effective on the second anniversary of the date we make the Software available. On or after that date, you may use
+// the Software under the Apache License, Version 2.0, in which case the following will apply:
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+// the License.
+//
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package codec
+
+import (
+	"net/http"
+	"slices"
+
+	"go.temporal.io/sdk/converter"
+)
+
+type (
+	// Authorizer returns an error if the request to the codec server is not allowed.
+	Authorizer func(r *http.Request) error
+
+	// HandlerOption configures the codec server.
+	HandlerOption func(*handler)
+
+	// handler is the codec server. It wraps the handler of the SDK with the CORS headers expected by the Temporal UI,
+	// and authorizes the requests, since anyone able to call it can decrypt the payloads.
+	handler struct {
+		codec     http.Handler
+		authorize Authorizer
+		origins   []string
+		namespace string
+	}
+)
+
+const (
+	HeaderNamespace = "X-Namespace" // HeaderNamespace is the header the Temporal UI sends the namespace of the payloads in.
+)
+
+// Handler returns the codec server for the Temporal UI, serving POST {endpoint}/encode and POST {endpoint}/decode.
+// Every request must pass the authorizer.
+//
+//	handler := codec.Handler(authorize, codecs, codec.WithOrigins("https://temporal.example.com"), codec.WithNamespace("default"))
+//	mux.Handle("/codec/", handler)
+//
+// The UI is pointed to the endpoint with the codec endpoint setting, passing the access token of the user along.
+func Handler(authorize Authorizer, codecs []converter.PayloadCodec, opts ...HandlerOption) http.Handler {
+	h := &handler{codec: converter.NewPayloadCodecHTTPHandler(codecs...), authorize: authorize}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// WithOrigins allows the browsers on the origins, i.e. the Temporal UI, to call the codec server.
+func WithOrigins(origins ...string) HandlerOption {
+	return func(h *handler) {
+		h.origins = append(h.origins, origins...)
+	}
+}
+
+// WithNamespace limits the codec server to the payloads of the namespace.
+func WithNamespace(namespace string) HandlerOption {
+	return func(h *handler) {
+		h.namespace = namespace
+	}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if origin := r.Header.Get("Origin"); origin != "" && slices.Contains(h.origins, origin) {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, "+HeaderNamespace)
+		w.Header().Add("Vary", "Origin")
+	}
+
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if h.authorize == nil {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.authorize(r); err != nil {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	if h.namespace != "" && r.Header.Get(HeaderNamespace) != h.namespace {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	h.codec.ServeHTTP(w, r)
+}
@@ -33,7 +33,10 @@ import (
 	"github.com/knadh/koanf/providers/structs"
 	"github.com/knadh/koanf/v2"
 	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
 	"go.temporal.io/sdk/log"
+
+	"go.breu.io/quantm/internal/durable/codec"
 )
 
 type (
@@ -44,6 +47,13 @@ type (
 		Port      int    `json:"port" koanf:"PORT" validate:"required"`           // Temporal port.
 		Skip      int    `json:"skip" koanf:"LOG_SKIP"`                           // Skip frames for logging.
 
+		// Secret encrypts the payloads sent to temporal. The payloads are sent as they are if empty.
+		Secret string `json:"-" koanf:"SECRET"`
+		// PreviousSecret decrypts the payloads encrypted before the secret was rotated.
+		PreviousSecret string `json:"-" koanf:"PREVIOUS_SECRET"`
+		// CodecOrigins are the comma separated origins of the Temporal UI allowed to call the codec server.
+		CodecOrigins string `json:"codec_origins" koanf:"CODEC_ORIGINS"`
+
 		client client.Client // Temporal client.
 		once   *sync.Once    // We can have only one Temporal client per configuration.
 	}
@@ -65,8 +75,13 @@ var (
 
 func (c *Config) Validate() error {
 	validate := validator.New()
+	if err := validate.Struct(c); err != nil {
+		return err
+	}
+
+	_, err := c.Codecs()
 
-	return validate.Struct(c)
+	return err
 }
 
 // Start is a no-op function to satisfy the graceful.Service interface.
@@ -116,8 +131,30 @@ func (c *Config) Client() (client.Client, error) {
 	return c.client, err
 }
 
+// Codecs returns the codecs encoding the payloads sent to temporal, or none if no secret is set.
+func (c *Config) Codecs() ([]converter.PayloadCodec, error) {
+	if c.Secret == "" {
+		return nil, nil
+	}
+
+	return codec.New(c.Secret, c.PreviousSecret)
+}
+
 func (c *Config) dial() error {
-	_c, err := client.Dial(c.options())
+	opts := c.options()
+
+	codecs, err := c.Codecs()
+	if err != nil {
+		return err
+	}
+
+	if len(codecs) > 0 {
+		opts.DataConverter = codec.DataConverter(codecs...)
+	} else {
+		slog.Warn("durable: no secret set, payloads are sent to temporal in plain text")
+	}
+
+	_c, err := client.Dial(opts)
 	if err != nil {
 		return err
 	}
@@ -196,6 +233,9 @@ func WithConfig(conf *Config) Option {
 		c.Host = conf.Host
 		c.Port = conf.Port
 		c.Skip = conf.Skip
+		c.Secret = conf.Secret
+		c.PreviousSecret = conf.PreviousSecret
+		c.CodecOrigins = conf.CodecOrigins
 	}
 }
 
@@ -23,6 +23,19 @@ var (
 	// hooksq is the hooks queue.
 	hooksq     queues.Queue
 	hooksqonce sync.Once
+
+	// gitq is the git queue.
+	gitq     queues.Queue
+	gitqonce sync.Once
+)
+
+// -- Queue Names --
+
+// The name of a queue is the task queue its workers poll.
+const (
+	QueueCore  = "core"  // QueueCore is the name of the core queue.
+	QueueHooks = "hooks" // QueueHooks is the name of the hooks queue.
+	QueueGit   = "git"   // QueueGit is the name of the git queue.
 )
 
 // -- Types --
@@ -70,7 +83,7 @@ func OnCore() queues.Queue {
 			panic(err)
 		}
 
-		coreq = queues.New(queues.WithName("core"), queues.WithClient(client))
+		coreq = queues.New(queues.WithName(QueueCore), queues.WithClient(client))
 	})
 
 	return coreq
@@ -89,8 +102,27 @@ func OnHooks() queues.Queue {
 			panic(err)
 		}
 
-		hooksq = queues.New(queues.WithName("hooks"), queues.WithClient(client))
+		hooksq = queues.New(queues.WithName(QueueHooks), queues.WithClient(client))
 	})
 
 	return hooksq
 }
+
+// OnGit returns the git queue.
+//
+// The git queue only runs the sessions of the git activities, i.e. clone, diff and rebase, which need the disk of the
+// host and must run on the same one. The workflows starting the sessions run on the core queue, so that the git workers
+// can be scaled on their own.
+func OnGit() queues.Queue {
+	gitqonce.Do(func() {
+		client, err := Get().Client()
+		if err != nil {
+			slog.Error("durable: unable to connect to durable server ...", "error", err.Error())
+			panic(err)
+		}
+
+		gitq = queues.New(queues.WithName(QueueGit), queues.WithClient(client))
+	})
+
+	return gitq
+}
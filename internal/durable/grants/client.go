@@ -0,0 +1,231 @@
+// Crafted with ❤ at Breu, Inc. <info@breu.io>, Copyright © 2024.
+//
+// Functional Source License, Version 1.1, Apache 2.0 Future License
+//
+// We hereby irrevocably grant you an additional license to use the Software under the Apache License, Version 2.0 that
+// is effective on the second anniversary of the date we make the Software available. On or after that date, you may use
+// the Software under the Apache License, Version 2.0, in which case the following will apply:
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+// the License.
+//
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package grants
+
+import (
+	"github.com/google/uuid"
+	"go.breu.io/durex/dispatch"
+	"go.temporal.io/sdk/log"
+	"go.temporal.io/sdk/workflow"
+)
+
+type (
+	// lease keeps the grant of a request alive while the critical section runs.
+	lease struct {
+		ctx    workflow.Context        // ctx is the context of the critical section, cancelled on expiry.
+		lost   bool                    // lost is true once the resource has expired the lease.
+		stop   workflow.CancelFunc     // stop ends the renewals.
+		cancel workflow.CancelFunc     // cancel cancels the context of the critical section.
+		token  uint64                  // token is the token of the grant.
+		expiry workflow.ReceiveChannel // expiry receives the expiry notifications of the resource for the request.
+	}
+)
+
+// Hold blocks until the request is granted (or timeout), executes the closure, and releases the grant. If the context
+// is cancelled while waiting, the pending request is withdrawn and the context error is returned.
+//
+// While the closure runs, the lease is renewed periodically. If the lease is lost nonetheless, the context of the
+// closure is cancelled, the grant is not released (it belongs to someone else by then), and an expired error is
+// returned once the closure returns.
+func Hold(ctx workflow.Context, spec *Spec, rx Requester, logger log.Logger, fn func(workflow.Context)) error {
+	// 1. Acquire
+	if err := acquire(ctx, spec, rx, logger); err != nil {
+		return err
+	}
+
+	// 2. Keep the lease alive
+	l := renew(ctx, spec, rx, logger)
+
+	// 3. Ensure Release
+	defer func() {
+		l.end()
+
+		if l.lost {
+			return
+		}
+
+		if err := release(ctx, spec, rx, logger); err != nil {
+			logger.Error(spec.Name+": failed to release "+spec.Noun, "error", err)
+		}
+	}()
+
+	// 4. Execute Critical Section
+	fn(l.ctx)
+
+	if l.lost {
+		return spec.ExpiredError(rx.ticket().ResourceID)
+	}
+
+	return nil
+}
+
+// acquire requests the grant under a new request ID, and waits for it.
+func acquire(ctx workflow.Context, spec *Spec, rx Requester, logger log.Logger) error {
+	t := rx.ticket()
+
+	_ = workflow.SideEffect(ctx, func(ctx workflow.Context) any { return uuid.New().String() }).Get(&t.Request)
+
+	c := dispatch.WithDefaultActivityContext(ctx)
+
+	exe := &workflow.Execution{}
+	if err := workflow.ExecuteActivity(c, spec.Activity, rx).Get(c, exe); err != nil {
+		logger.Warn(spec.Name+": unable to request "+spec.Noun, "error", err)
+		return spec.AcquireError(t.ResourceID)
+	}
+
+	t.Execution = exe
+
+	token := uint64(0)
+	timeout := false
+	cancelled := false
+
+	workflow.NewSelector(ctx).
+		AddReceive(workflow.GetSignalChannel(ctx, t.Signal(spec.Signals.Acquired)), func(c workflow.ReceiveChannel, _ bool) {
+			c.Receive(ctx, &token)
+		}).
+		AddFuture(workflow.NewTimer(ctx, MaxAcquireWait), func(_ workflow.Future) {
+			timeout = true
+		}).
+		AddReceive(ctx.Done(), func(_ workflow.ReceiveChannel, _ bool) {
+			cancelled = true
+		}).
+		Select(ctx)
+
+	switch {
+	case cancelled:
+		cancel(ctx, spec, rx, logger)
+		return ctx.Err()
+	case timeout:
+		logger.Warn(spec.Name + ": timeout waiting for " + spec.Noun)
+		cancel(ctx, spec, rx, logger)
+
+		return spec.AcquireError(t.ResourceID)
+	case token > 0:
+		t.Token = token
+		return nil
+	default:
+		return spec.AcquireError(t.ResourceID)
+	}
+}
+
+// cancel withdraws a pending request. If it was granted in the meantime, the resource releases it.
+func cancel(ctx workflow.Context, spec *Spec, rx Requester, logger log.Logger) {
+	t := rx.ticket()
+	ctx, _ = workflow.NewDisconnectedContext(ctx)
+
+	if err := workflow.
+		SignalExternalWorkflow(ctx, t.Execution.ID, t.Execution.RunID, spec.Signals.Cancel.String(), rx).
+		Get(ctx, nil); err != nil {
+		logger.Warn(spec.Name+": unable to withdraw request", "error", err)
+	}
+}
+
+// release gives up the grant, and waits for the confirmation.
+func release(ctx workflow.Context, spec *Spec, rx Requester, logger log.Logger) error {
+	t := rx.ticket()
+
+	if err := workflow.
+		SignalExternalWorkflow(ctx, t.Execution.ID, t.Execution.RunID, spec.Signals.Release.String(), rx).
+		Get(ctx, nil); err != nil {
+		logger.Warn(spec.Name+": unable to request release", "error", err)
+		return spec.ReleaseError(t.ResourceID)
+	}
+
+	released := false
+	timeout := false
+
+	workflow.NewSelector(ctx).
+		AddReceive(workflow.GetSignalChannel(ctx, t.Signal(spec.Signals.Released)), func(c workflow.ReceiveChannel, _ bool) {
+			c.Receive(ctx, &released)
+		}).
+		AddFuture(workflow.NewTimer(ctx, SignalTimeout), func(_ workflow.Future) {
+			timeout = true
+		}).
+		Select(ctx)
+
+	if timeout {
+		logger.Warn(spec.Name + ": timeout waiting for release confirmation")
+		return spec.ReleaseError(t.ResourceID)
+	}
+
+	return nil
+}
+
+// renew starts renewing the lease of the grant in the background.
+func renew(ctx workflow.Context, spec *Spec, rx Requester, logger log.Logger) *lease {
+	t := rx.ticket()
+	l := &lease{token: t.Token, expiry: workflow.GetSignalChannel(ctx, t.Signal(spec.Signals.Expired))}
+
+	l.ctx, l.cancel = workflow.WithCancel(ctx)
+
+	hbctx, stop := workflow.WithCancel(ctx)
+	l.stop = stop
+
+	workflow.Go(hbctx, func(ctx workflow.Context) {
+		for ctx.Err() == nil && !l.lost {
+			workflow.NewSelector(ctx).
+				AddFuture(workflow.NewTimer(ctx, RenewInterval(t.timeout(spec))), func(f workflow.Future) {
+					if f.Get(ctx, nil) == nil {
+						heartbeat(ctx, spec, rx, logger)
+					}
+				}).
+				AddReceive(l.expiry, func(c workflow.ReceiveChannel, _ bool) {
+					token := uint64(0)
+					c.Receive(ctx, &token)
+
+					l.expired(spec, logger, token)
+				}).
+				AddReceive(ctx.Done(), func(_ workflow.ReceiveChannel, _ bool) {}).
+				Select(ctx)
+		}
+	})
+
+	return l
+}
+
+// end stops the renewals and releases the context of the critical section.
+func (l *lease) end() {
+	l.stop()
+	l.cancel()
+}
+
+// expired cancels the critical section if the expiry is for the current grant. Notifications left over from an earlier
+// grant are ignored.
+func (l *lease) expired(spec *Spec, logger log.Logger, token uint64) {
+	if token != l.token {
+		return
+	}
+
+	logger.Warn(spec.Name+": "+spec.Noun+" expired", "token", token)
+
+	l.lost = true
+	l.cancel()
+}
+
+// heartbeat asks the resource to extend the lease.
+func heartbeat(ctx workflow.Context, spec *Spec, rx Requester, logger log.Logger) {
+	t := rx.ticket()
+
+	if err := workflow.
+		SignalExternalWorkflow(ctx, t.Execution.ID, t.Execution.RunID, spec.Signals.Renew.String(), rx).
+		Get(ctx, nil); err != nil {
+		logger.Warn(spec.Name+": unable to renew lease", "error", err)
+	}
+}
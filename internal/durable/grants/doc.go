@@ -0,0 +1,30 @@
+// Crafted with ❤ at Breu, Inc. <info@breu.io>, Copyright © 2024.
+//
+// Functional Source License, Version 1.1, Apache 2.0 Future License
+//
+// We hereby irrevocably grant you an additional license to use the Software under the Apache License, Version 2.0 that
+// is effective on the second anniversary of the date we make the Software available. On or after that date, you may use
+// the Software under the Apache License, Version 2.0, in which case the following will apply:
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+// the License.
+//
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+// Package grants holds the machinery shared by the semaphore and rwlock packages: the request sent by a workflow for a
+// resource, the client that waits for the grant, renews its lease and releases it, and the table of holders and waiters
+// the workflow of the resource serves.
+//
+// A primitive embeds Ticket in its handler and Table in its state, and decides which waiter is admitted next. Every
+// request carries an ID that keys the signals the resource sends back to it, so a grant or an expiry left over from a
+// withdrawn request, or meant for another resource held by the same workflow, is never taken for the pending one.
+//
+// The holders renew their lease every third of its timeout while the critical section runs. The workflow of the
+// resource continues as new, with its holders and waiters, once the server suggests it.
+package grants
@@ -0,0 +1,366 @@
+// Crafted with ❤ at Breu, Inc. <info@breu.io>, Copyright © 2024.
+//
+// Functional Source License, Version 1.1, Apache 2.0 Future License
+//
+// We hereby irrevocably grant you an additional license to use the Software under the Apache License, Version 2.0 that
+// is effective on the second anniversary of the date we make the Software available. On or after that date, you may use
+// the Software under the Apache License, Version 2.0, in which case the following will apply:
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+// the License.
+//
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package grants
+
+import (
+	"slices"
+	"time"
+
+	"go.temporal.io/sdk/log"
+	"go.temporal.io/sdk/workflow"
+
+	"go.breu.io/quantm/internal/durable/periodic"
+)
+
+type (
+	// Entry is a request in the table, either holding the resource or waiting for it.
+	Entry[R Requester] struct {
+		Handler R         `json:"handler"` // Handler is the handler of the requesting workflow.
+		Since   time.Time `json:"since"`   // Since is the time the request was granted, or received while waiting.
+		Expiry  time.Time `json:"expiry"`  // Expiry is the time the lease of the holder runs out.
+	}
+
+	// Table is the state shared by the workflows of the primitives: the holders of the resource, the requests waiting for
+	// it, and the leases of the holders. The primitives embed it in their state, and decide which waiter is admitted.
+	Table[R Requester] struct {
+		ResourceID string      `json:"resource_id"`
+		Holders    []*Entry[R] `json:"holders"`
+		Waiters    []*Entry[R] `json:"waiters"`
+		Token      uint64      `json:"token"`
+		Persist    bool        `json:"persist"`
+
+		spec    *Spec
+		leases  map[uint64]workflow.CancelFunc // leases cancels the lease timers of the holders, by token.
+		expired workflow.Channel               // expired receives the tokens of the expired leases.
+		logger  log.Logger
+	}
+
+	// handler pairs a signal channel with its callback.
+	handler struct {
+		channel  workflow.ReceiveChannel
+		callback func(workflow.ReceiveChannel, bool)
+	}
+)
+
+/***
+ * Initialization
+ **/
+
+// NewTable creates the table of the resource.
+func NewTable[R Requester](resource_id string) Table[R] {
+	return Table[R]{
+		ResourceID: resource_id,
+		Holders:    make([]*Entry[R], 0),
+		Waiters:    make([]*Entry[R], 0),
+		Persist:    true,
+	}
+}
+
+// Restore reinitializes the table at the start of a run, restarting the leases of the holders carried over.
+func (t *Table[R]) Restore(ctx workflow.Context, spec *Spec, logger log.Logger) {
+	t.spec = spec
+	t.logger = logger
+	t.leases = make(map[uint64]workflow.CancelFunc)
+	t.expired = workflow.NewChannel(ctx)
+
+	for _, holder := range t.Holders {
+		t.lease(ctx, holder)
+	}
+}
+
+// Idle returns true if there are neither holders nor waiters.
+func (t *Table[R]) Idle() bool {
+	return len(t.Holders) == 0 && len(t.Waiters) == 0
+}
+
+/***
+ * Workflow
+ **/
+
+// Run serves the resource as a serialized state machine:
+// 1. Admit the waiters, in arrival order, while admit allows the head of the queue.
+// 2. Wait for Acquire, Release, Cancel, Renew or a lease expiry (or Idle Timeout when there are neither holders nor
+// waiters).
+// 3. Repeat.
+//
+// It returns true once the server suggests to continue as new, after taking in the signals received so far. The caller
+// then continues as new with its state, carrying the holders and waiters over.
+func (t *Table[R]) Run(ctx workflow.Context, admit func(R) bool) bool {
+	// Idle Timer Setup
+	// If there are neither holders nor waiters within IdleTimeout, the workflow shuts down.
+	idle := periodic.New(ctx, IdleTimeout)
+	tick := workflow.NewBufferedChannel(ctx, 1)
+
+	workflow.Go(ctx, func(ctx workflow.Context) {
+		idle.Tick(ctx)
+		tick.Send(ctx, true)
+	})
+
+	handlers := []handler{
+		{workflow.GetSignalChannel(ctx, t.spec.Signals.Acquire.String()), t.on_acquire(ctx)},
+		{workflow.GetSignalChannel(ctx, t.spec.Signals.Release.String()), t.on_release(ctx)},
+		{workflow.GetSignalChannel(ctx, t.spec.Signals.Cancel.String()), t.on_cancel(ctx)},
+		{workflow.GetSignalChannel(ctx, t.spec.Signals.Renew.String()), t.on_renew(ctx)},
+	}
+
+	busy := false
+
+	for t.Persist {
+		t.grant(ctx, admit)
+
+		if workflow.GetInfo(ctx).GetContinueAsNewSuggested() {
+			t.drain(handlers)
+			idle.Stop(ctx)
+
+			return true
+		}
+
+		// the idle timer only runs while nobody holds or waits for the resource.
+		if busy == t.Idle() {
+			busy = !busy
+
+			if busy {
+				idle.Restart(ctx, LongTimeout)
+			} else {
+				idle.Restart(ctx, IdleTimeout)
+			}
+		}
+
+		selector := workflow.NewSelector(ctx)
+		for _, h := range handlers {
+			selector.AddReceive(h.channel, h.callback)
+		}
+
+		selector.
+			AddReceive(t.expired, t.on_expire(ctx)).
+			AddReceive(tick, t.stop(ctx)).
+			Select(ctx)
+	}
+
+	idle.Stop(ctx)
+
+	return false
+}
+
+// drain takes in the signals received but not handled yet, as the signals left in the channels are lost when the
+// workflow continues as new.
+func (t *Table[R]) drain(handlers []handler) {
+	for _, h := range handlers {
+		for h.channel.Len() > 0 {
+			h.callback(h.channel, true)
+		}
+	}
+}
+
+/***
+ * Signal Handlers
+ **/
+
+// on_acquire returns a callback for handling the acquire signal.
+func (t *Table[R]) on_acquire(ctx workflow.Context) func(workflow.ReceiveChannel, bool) {
+	return func(c workflow.ReceiveChannel, _ bool) {
+		var rx R
+		c.Receive(ctx, &rx)
+
+		if find(t.Waiters, rx) >= 0 || find(t.Holders, rx) >= 0 {
+			t.logger.Warn(t.spec.Name+": ignored duplicate request", "sender", rx.ticket().WorkflowExecutionID())
+			return
+		}
+
+		t.Waiters = append(t.Waiters, &Entry[R]{Handler: rx, Since: workflow.Now(ctx)})
+	}
+}
+
+// on_release returns a callback for handling the release signal. Only the holder of the grant can release it.
+func (t *Table[R]) on_release(ctx workflow.Context) func(workflow.ReceiveChannel, bool) {
+	return func(c workflow.ReceiveChannel, _ bool) {
+		var rx R
+		c.Receive(ctx, &rx)
+
+		idx := t.holder(rx)
+		if idx < 0 {
+			t.logger.Warn(t.spec.Name+": ignored release from non-holder", "sender", rx.ticket().WorkflowExecutionID())
+			return
+		}
+
+		t.release(ctx, idx)
+	}
+}
+
+// on_cancel returns a callback for handling the cancel signal. A pending request is withdrawn. If the request was
+// granted before the cancellation arrived, it is released.
+func (t *Table[R]) on_cancel(ctx workflow.Context) func(workflow.ReceiveChannel, bool) {
+	return func(c workflow.ReceiveChannel, _ bool) {
+		var rx R
+		c.Receive(ctx, &rx)
+
+		if idx := find(t.Waiters, rx); idx >= 0 {
+			t.Waiters = slices.Delete(t.Waiters, idx, idx+1)
+			return
+		}
+
+		if idx := find(t.Holders, rx); idx >= 0 {
+			t.release(ctx, idx)
+		}
+	}
+}
+
+// on_renew returns a callback for handling the renew signal. Only the holder of the grant can extend its lease, which
+// then runs for its timeout from now.
+func (t *Table[R]) on_renew(ctx workflow.Context) func(workflow.ReceiveChannel, bool) {
+	return func(c workflow.ReceiveChannel, _ bool) {
+		var rx R
+		c.Receive(ctx, &rx)
+
+		idx := t.holder(rx)
+		if idx < 0 {
+			t.logger.Warn(t.spec.Name+": ignored renewal from non-holder", "sender", rx.ticket().WorkflowExecutionID())
+			return
+		}
+
+		holder := t.Holders[idx]
+		t.unlease(holder)
+
+		holder.Expiry = workflow.Now(ctx).Add(holder.Handler.ticket().timeout(t.spec))
+		t.lease(ctx, holder)
+	}
+}
+
+// on_expire returns a callback for handling the lease expiry of a holder.
+func (t *Table[R]) on_expire(ctx workflow.Context) func(workflow.ReceiveChannel, bool) {
+	return func(c workflow.ReceiveChannel, _ bool) {
+		token := uint64(0)
+		c.Receive(ctx, &token)
+
+		idx := slices.IndexFunc(t.Holders, func(e *Entry[R]) bool { return e.Handler.ticket().Token == token })
+		if idx < 0 {
+			return
+		}
+
+		holder := t.Holders[idx].Handler.ticket()
+		t.Holders = slices.Delete(t.Holders, idx, idx+1)
+		delete(t.leases, token)
+
+		t.logger.Warn(t.spec.Name+": "+t.spec.Noun+" expired", "holder", holder.WorkflowExecutionID(), "token", token)
+
+		_ = workflow.
+			SignalExternalWorkflow(ctx, holder.WorkflowExecutionID(), holder.WorkflowRunID(), holder.Signal(t.spec.Signals.Expired), token).
+			Get(ctx, nil)
+	}
+}
+
+// stop returns a callback for handling the idle timeout.
+func (t *Table[R]) stop(_ workflow.Context) func(workflow.ReceiveChannel, bool) {
+	return func(_ workflow.ReceiveChannel, _ bool) {
+		t.logger.Info(t.spec.Name + ": shutting down due to inactivity")
+		t.Persist = false
+	}
+}
+
+/***
+ * Actions
+ **/
+
+// grant hands the resource to the waiters, in arrival order, while admit allows the head of the queue. A waiter that
+// is not admitted holds back the waiters behind it.
+func (t *Table[R]) grant(ctx workflow.Context, admit func(R) bool) {
+	for len(t.Waiters) > 0 && admit(t.Waiters[0].Handler) {
+		next := t.Waiters[0]
+		t.Waiters = slices.Delete(t.Waiters, 0, 1)
+
+		rx := next.Handler.ticket()
+
+		t.Token++
+		rx.Token = t.Token
+		next.Since = workflow.Now(ctx)
+		next.Expiry = next.Since.Add(rx.timeout(t.spec))
+
+		t.Holders = append(t.Holders, next)
+		t.lease(ctx, next)
+
+		_ = workflow.
+			SignalExternalWorkflow(ctx, rx.WorkflowExecutionID(), rx.WorkflowRunID(), rx.Signal(t.spec.Signals.Acquired), t.Token).
+			Get(ctx, nil)
+
+		t.logger.Info(t.spec.Name+": "+t.spec.Noun+" granted", "holder", rx.WorkflowExecutionID(), "holders", len(t.Holders))
+	}
+}
+
+// release removes the holder at idx, and confirms the release to it.
+func (t *Table[R]) release(ctx workflow.Context, idx int) {
+	holder := t.Holders[idx]
+	rx := holder.Handler.ticket()
+
+	t.Holders = slices.Delete(t.Holders, idx, idx+1)
+	t.unlease(holder)
+
+	_ = workflow.
+		SignalExternalWorkflow(ctx, rx.WorkflowExecutionID(), rx.WorkflowRunID(), rx.Signal(t.spec.Signals.Released), true).
+		Get(ctx, nil)
+
+	t.logger.Info(t.spec.Name+": "+t.spec.Noun+" released", "holder", rx.WorkflowExecutionID(), "holders", len(t.Holders))
+}
+
+// lease starts the lease timer of the holder, running until its expiry. On expiry, the token of the holder is sent to
+// the expired channel.
+func (t *Table[R]) lease(ctx workflow.Context, holder *Entry[R]) {
+	token := holder.Handler.ticket().Token
+	timeout := max(holder.Expiry.Sub(workflow.Now(ctx)), 0)
+
+	ctx, cancel := workflow.WithCancel(ctx)
+	t.leases[token] = cancel
+
+	workflow.Go(ctx, func(ctx workflow.Context) {
+		if err := workflow.NewTimer(ctx, timeout).Get(ctx, nil); err == nil {
+			t.expired.Send(ctx, token)
+		}
+	})
+}
+
+// unlease stops the lease timer of the holder.
+func (t *Table[R]) unlease(holder *Entry[R]) {
+	token := holder.Handler.ticket().Token
+
+	if cancel, ok := t.leases[token]; ok {
+		cancel()
+		delete(t.leases, token)
+	}
+}
+
+// holder returns the position of the holder of the grant of the request, or -1.
+func (t *Table[R]) holder(rx R) int {
+	return slices.IndexFunc(t.Holders, func(e *Entry[R]) bool {
+		return e.Handler.ticket().Token == rx.ticket().Token &&
+			e.Handler.ticket().WorkflowExecutionID() == rx.ticket().WorkflowExecutionID()
+	})
+}
+
+// find returns the position of the entry of the request, or -1. Requests are told apart by their ID, or by their
+// workflow execution when they carry none.
+func find[R Requester](entries []*Entry[R], rx R) int {
+	return slices.IndexFunc(entries, func(e *Entry[R]) bool {
+		a, b := e.Handler.ticket(), rx.ticket()
+		if a.Request != "" || b.Request != "" {
+			return a.Request == b.Request
+		}
+
+		return a.WorkflowExecutionID() == b.WorkflowExecutionID()
+	})
+}
@@ -0,0 +1,154 @@
+// Crafted with ❤ at Breu, Inc. <info@breu.io>, Copyright © 2024.
+//
+// Functional Source License, Version 1.1, Apache 2.0 Future License
+//
+// We hereby irrevocably grant you an additional license to use the Software under the Apache License, Version 2.0 that
+// is effective on the second anniversary of the date we make the Software available. On or after that date, you may use
+// the Software under the Apache License, Version 2.0, in which case the following will apply:
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+// the License.
+//
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package grants
+
+import (
+	"fmt"
+	"time"
+
+	"go.breu.io/durex/queues"
+	"go.temporal.io/sdk/workflow"
+)
+
+const (
+	SignalTimeout  = 1 * time.Minute      // SignalTimeout is the timeout for signal handshakes.
+	MaxAcquireWait = 24 * time.Hour       // MaxAcquireWait is the maximum time to wait for a grant.
+	IdleTimeout    = 10 * time.Minute     // IdleTimeout shuts the resource down once nobody holds or waits for it.
+	LongTimeout    = 365 * 24 * time.Hour // Effectively infinite for "pausing" the idle timer
+)
+
+type (
+	// Ticket is the part of a request shared by the primitives. Their handlers embed it, and are sent as the payload of
+	// the signals to the resource.
+	Ticket struct {
+		ResourceID string              `json:"resource_id"` // ResourceID identifies the resource being guarded.
+		Info       *workflow.Info      `json:"info"`        // Info holds the workflow info that makes the request.
+		Execution  *workflow.Execution `json:"execution"`   // Execution holds the execution details of the resource.
+		Timeout    time.Duration       `json:"timeout"`     // Timeout sets the lease timeout of the grant.
+		Token      uint64              `json:"token"`       // Token identifies the current grant.
+		Request    string              `json:"request"`     // Request identifies the pending request.
+	}
+
+	// Requester is a request of a primitive, i.e. a handler embedding the Ticket.
+	Requester interface {
+		ticket() *Ticket
+	}
+
+	// Signals names the signals of a primitive.
+	Signals struct {
+		Acquire  queues.Signal // Acquire queues a request.
+		Acquired queues.Signal // Acquired grants a request, with the token of the grant.
+		Release  queues.Signal // Release gives up a grant.
+		Released queues.Signal // Released confirms the release.
+		Cancel   queues.Signal // Cancel withdraws a request, releasing it if granted meanwhile.
+		Renew    queues.Signal // Renew extends the lease of a grant.
+		Expired  queues.Signal // Expired tells the holder its lease has run out.
+	}
+
+	// Spec describes a primitive to the shared machinery.
+	Spec struct {
+		Name     string        // Name prefixes the log messages, e.g. "semaphore".
+		Noun     string        // Noun names a grant in the log messages and errors, e.g. "permit".
+		Signals  Signals       // Signals are the signals of the primitive.
+		Activity any           // Activity signals the resource to acquire, starting its workflow if need be.
+		Timeout  time.Duration // Timeout is the lease timeout of the requests without one.
+	}
+
+	// Error is returned when a primitive fails.
+	Error struct {
+		id   string // the id of the resource.
+		kind string // kind of error, e.g. "acquire permit", "release permit", or "keep permit".
+	}
+)
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: failed to %s.", e.id, e.kind)
+}
+
+// NewError creates a new error of the kind for the resource.
+func NewError(id, kind string) error {
+	return &Error{id, kind}
+}
+
+func (t *Ticket) ticket() *Ticket {
+	return t
+}
+
+func (t *Ticket) WorkflowExecutionID() string {
+	if t.Info == nil {
+		return ""
+	}
+
+	return t.Info.WorkflowExecution.ID
+}
+
+func (t *Ticket) WorkflowRunID() string {
+	if t.Info == nil {
+		return ""
+	}
+
+	return t.Info.WorkflowExecution.RunID
+}
+
+// Signal returns the name of the signal the resource sends to the request.
+func (t *Ticket) Signal(signal queues.Signal) string {
+	return Signal(signal, t.Request)
+}
+
+// Signal returns the name of the signal the resource sends to the request. The signals are keyed by the request, so
+// that a grant or an expiry left over from a withdrawn request, or sent by another resource held by the same workflow,
+// is never taken for one of the pending request.
+func Signal(signal queues.Signal, request string) string {
+	if request == "" {
+		return signal.String()
+	}
+
+	return signal.String() + "__" + request
+}
+
+// timeout returns the lease timeout of the request.
+func (t *Ticket) timeout(spec *Spec) time.Duration {
+	if t.Timeout > 0 {
+		return t.Timeout
+	}
+
+	return spec.Timeout
+}
+
+// RenewInterval returns how often the lease of the given timeout is renewed. Renewing thrice per lease tolerates a
+// missed renewal.
+func RenewInterval(timeout time.Duration) time.Duration {
+	return timeout / 3
+}
+
+// AcquireError creates a new acquire error of the primitive.
+func (s *Spec) AcquireError(id string) error {
+	return NewError(id, "acquire "+s.Noun)
+}
+
+// ReleaseError creates a new release error of the primitive.
+func (s *Spec) ReleaseError(id string) error {
+	return NewError(id, "release "+s.Noun)
+}
+
+// ExpiredError creates a new expired error of the primitive.
+func (s *Spec) ExpiredError(id string) error {
+	return NewError(id, "keep "+s.Noun)
+}
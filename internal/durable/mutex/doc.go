@@ -33,4 +33,12 @@
 //	    // The lock is held during this execution.
 //	    // It is automatically released when this function returns.
 //	})
+//
+// Requests waiting for the lock are queued by priority, and then by arrival. Use WithPriority to jump ahead of the
+// normal requests, e.g. for hotfixes. Cancelling the context of a pending OnAcquire withdraws the request from the
+// queue. The holder, the ordered waiters and their wait times are exposed by the query__mutex__state query.
+//
+// The lease is renewed while the critical section runs. Should the lease be lost regardless, e.g. the holder is stuck,
+// the context of the critical section is cancelled and OnAcquire returns an error. Every grant carries a fencing token,
+// available with FencingToken(lockCtx), that increases with each grant so downstream writes can reject stale holders.
 package mutex
@@ -58,3 +58,8 @@ func NewPrepareMutexError(id string) error {
 func NewCleanupMutexError(id string) error {
 	return &MutexError{id, "cleanup mutex"}
 }
+
+// NewLeaseLostError creates a new lease lost error.
+func NewLeaseLostError(id string) error {
+	return &MutexError{id, "keep lease"}
+}
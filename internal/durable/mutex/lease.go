@@ -0,0 +1,120 @@
+// Crafted with ❤ at Breu, Inc. <info@breu.io>, Copyright © 2024.
+//
+// Functional Source License, Version 1.1, Apache 2.0 Future License
+//
+// We hereby irrevocably grant you an additional license to use the Software under the Apache License, Version 2.0 that
+// is effective on the second anniversary of the date we make the Software available. On or after that date, you may use
+// the Software under the Apache License, Version 2.0, in which case the following will apply:
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+// the License.
+//
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package mutex
+
+import (
+	"time"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+type (
+	// lease keeps the lock of the handler alive while the critical section runs.
+	lease struct {
+		ctx    workflow.Context        // ctx is the context of the critical section, cancelled on lease loss.
+		lost   bool                    // lost is true once the mutex has expired the lease.
+		stop   workflow.CancelFunc     // stop ends the renewals.
+		cancel workflow.CancelFunc     // cancel cancels the context of the critical section.
+		token  uint64                  // token is the fencing token of the grant.
+		expiry workflow.ReceiveChannel // expiry receives the expiry notifications of the mutex for the request.
+	}
+
+	token_key struct{}
+)
+
+// FencingToken returns the fencing token of the lock held by the context passed to OnAcquire, and 0 outside of it. The
+// token increases with every grant of the lock, so the writes guarded by the lock can reject a stale holder.
+func FencingToken(ctx workflow.Context) uint64 {
+	if token, ok := ctx.Value(token_key{}).(uint64); ok {
+		return token
+	}
+
+	return 0
+}
+
+// RenewInterval returns how often the lease of the given timeout is renewed. Renewing thrice per lease tolerates a
+// missed renewal.
+func RenewInterval(timeout time.Duration) time.Duration {
+	return timeout / 3
+}
+
+// renew starts renewing the lease of the lock in the background. Handlers recorded before ChangeLease run the critical
+// section on the context of the caller, and never renew.
+func (h *Handler) renew(ctx workflow.Context) *lease {
+	if !h.leased {
+		return &lease{ctx: ctx, token: h.Token, stop: func() {}, cancel: func() {}}
+	}
+
+	l := &lease{token: h.Token, expiry: workflow.GetSignalChannel(ctx, Signal(WorkflowSignalExpired, h.Request))}
+
+	l.ctx, l.cancel = workflow.WithCancel(workflow.WithValue(ctx, token_key{}, h.Token))
+
+	hbctx, stop := workflow.WithCancel(ctx)
+	l.stop = stop
+
+	workflow.Go(hbctx, func(ctx workflow.Context) {
+		for ctx.Err() == nil && !l.lost {
+			workflow.NewSelector(ctx).
+				AddFuture(workflow.NewTimer(ctx, RenewInterval(h.Timeout)), func(f workflow.Future) {
+					if f.Get(ctx, nil) == nil {
+						h.heartbeat(ctx)
+					}
+				}).
+				AddReceive(l.expiry, func(c workflow.ReceiveChannel, _ bool) {
+					token := uint64(0)
+					c.Receive(ctx, &token)
+
+					l.expired(h, token)
+				}).
+				AddReceive(ctx.Done(), func(_ workflow.ReceiveChannel, _ bool) {}).
+				Select(ctx)
+		}
+	})
+
+	return l
+}
+
+// end stops the renewals and releases the context of the critical section.
+func (l *lease) end() {
+	l.stop()
+	l.cancel()
+}
+
+// heartbeat asks the mutex to extend the lease.
+func (h *Handler) heartbeat(ctx workflow.Context) {
+	if err := workflow.
+		SignalExternalWorkflow(ctx, h.Execution.ID, h.Execution.RunID, WorkflowSignalRenew.String(), h).
+		Get(ctx, nil); err != nil {
+		h.logger.warn(h.WorkflowExecutionID(), "renew", "unable to renew lease", err)
+	}
+}
+
+// expired cancels the critical section if the expiry is for the current grant. Notifications left over from an earlier
+// grant are ignored.
+func (l *lease) expired(h *Handler, token uint64) {
+	if token != l.token {
+		return
+	}
+
+	h.logger.warn(h.WorkflowExecutionID(), "renew", "lease lost", "token", token)
+
+	l.lost = true
+	l.cancel()
+}
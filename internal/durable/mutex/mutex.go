@@ -20,8 +20,10 @@
 package mutex
 
 import (
+	"encoding/json"
 	"time"
 
+	"github.com/google/uuid"
 	"go.breu.io/durex/dispatch"
 	"go.breu.io/durex/queues"
 	"go.temporal.io/sdk/workflow"
@@ -38,6 +40,9 @@ const (
 	WorkflowSignalLocked   queues.Signal = "mutex__locked"
 	WorkflowSignalRelease  queues.Signal = "mutex__release"
 	WorkflowSignalReleased queues.Signal = "mutex__released"
+	WorkflowSignalCancel   queues.Signal = "mutex__cancel"
+	WorkflowSignalRenew    queues.Signal = "mutex__renew"
+	WorkflowSignalExpired  queues.Signal = "mutex__expired"
 )
 
 type (
@@ -46,7 +51,8 @@ type (
 	// Mutex defines the signature for the workflow mutex.
 	Mutex interface {
 		// OnAcquire blocks until the lock is acquired, executes fn, and then releases the lock.
-		// It returns an error if the lock cannot be acquired or the context is cancelled.
+		// It returns an error if the lock cannot be acquired, the context is cancelled, or the lease is lost while fn
+		// runs. The context passed to fn is cancelled on lease loss, and carries the fencing token of the grant.
 		OnAcquire(ctx workflow.Context, fn func(workflow.Context)) error
 	}
 
@@ -56,7 +62,11 @@ type (
 		Info       *workflow.Info      `json:"info"`        // Info holds the workflow info that requests the mutex.
 		Execution  *workflow.Execution `json:"execution"`   // Execution holds the mutex workflow execution details.
 		Timeout    time.Duration       `json:"timeout"`     // Timeout sets the lease timeout.
+		Priority   Priority            `json:"priority"`    // Priority orders the request among the waiters.
+		Token      uint64              `json:"token"`       // Token is the fencing token of the current grant.
+		Request    string              `json:"request"`     // Request identifies the pending request for the lock.
 		logger     *MutexLogger
+		leased     bool // leased is false for the handlers recorded before ChangeLease.
 	}
 )
 
@@ -74,6 +84,13 @@ func WithTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithPriority sets the priority of the lock requests. Requests with a higher priority are granted the lock first.
+func WithPriority(priority Priority) Option {
+	return func(m *Handler) {
+		m.Priority = priority
+	}
+}
+
 // New returns a new Mutex.
 func New(ctx workflow.Context, opts ...Option) (Mutex, error) {
 	h := &Handler{Timeout: DefaultTimeout}
@@ -83,6 +100,7 @@ func New(ctx workflow.Context, opts ...Option) (Mutex, error) {
 
 	h.Info = workflow.GetInfo(ctx)
 	h.logger = NewMutexHandlerLogger(ctx, h.ResourceID)
+	h.leased = workflow.GetVersion(ctx, ChangeLease, workflow.DefaultVersion, 1) != workflow.DefaultVersion
 
 	if err := h.validate(); err != nil {
 		h.logger.error(h.WorkflowExecutionID(), "create", "validate error", err)
@@ -94,6 +112,17 @@ func New(ctx workflow.Context, opts ...Option) (Mutex, error) {
 	return h, nil
 }
 
+// Signal returns the name of the signal the mutex sends to the request. The signals are keyed by the request, so that a
+// grant left over from a withdrawn request, or a grant of another mutex held by the same workflow, is never taken for
+// the grant of the pending request. Requests recorded before ChangeLease carry no ID, and use the plain signal.
+func Signal(signal queues.Signal, request string) string {
+	if request == "" {
+		return signal.String()
+	}
+
+	return signal.String() + "__" + request
+}
+
 func (h *Handler) WorkflowExecutionID() string {
 	if h.Info == nil {
 		return ""
@@ -110,22 +139,40 @@ func (h *Handler) WorkflowRunID() string {
 	return h.Info.WorkflowExecution.RunID
 }
 
-// OnAcquire blocks until acquired (or timeout), executes the closure, and releases the lock.
+// OnAcquire blocks until acquired (or timeout), executes the closure, and releases the lock. If the context is cancelled
+// while waiting, the pending request is withdrawn from the queue and the context error is returned.
+//
+// While the closure runs, the lease is renewed periodically. If the lease is lost nonetheless, the context of the
+// closure is cancelled, the lock is not released (it belongs to someone else by then), and a lease lost error is
+// returned once the closure returns.
 func (h *Handler) OnAcquire(ctx workflow.Context, fn func(workflow.Context)) error {
 	// 1. Acquire
 	if err := h.acquire(ctx); err != nil {
 		return err
 	}
 
-	// 2. Ensure Release
+	// 2. Keep the lease alive
+	lease := h.renew(ctx)
+
+	// 3. Ensure Release
 	defer func() {
+		lease.end()
+
+		if lease.lost {
+			return
+		}
+
 		if err := h.release(ctx); err != nil {
 			h.logger.error(h.WorkflowExecutionID(), "release", "failed to release lock", err)
 		}
 	}()
 
-	// 3. Execute Critical Section
-	fn(ctx)
+	// 4. Execute Critical Section
+	fn(lease.ctx)
+
+	if lease.lost {
+		return NewLeaseLostError(h.ResourceID)
+	}
 
 	return nil
 }
@@ -134,6 +181,10 @@ func (h *Handler) OnAcquire(ctx workflow.Context, fn func(workflow.Context)) err
 func (h *Handler) acquire(ctx workflow.Context) error {
 	h.logger.info(h.WorkflowExecutionID(), "acquire", "requesting lock")
 
+	if h.leased {
+		_ = workflow.SideEffect(ctx, func(ctx workflow.Context) any { return uuid.New().String() }).Get(&h.Request)
+	}
+
 	c := dispatch.WithDefaultActivityContext(ctx)
 
 	exe := &workflow.Execution{}
@@ -147,31 +198,79 @@ func (h *Handler) acquire(ctx workflow.Context) error {
 
 	locked := false
 	timeout := false
+	cancelled := false
 	waiter := workflow.NewSelector(ctx)
 
-	waiter.AddReceive(workflow.GetSignalChannel(ctx, WorkflowSignalLocked.String()), func(c workflow.ReceiveChannel, _ bool) {
-		c.Receive(ctx, &locked)
+	waiter.AddReceive(workflow.GetSignalChannel(ctx, Signal(WorkflowSignalLocked, h.Request)), func(c workflow.ReceiveChannel, _ bool) {
+		payload := json.RawMessage{}
+		c.Receive(ctx, &payload)
+
+		locked, h.Token = grant(payload)
 	})
 
 	waiter.AddFuture(workflow.NewTimer(ctx, MaxAcquireWait), func(_ workflow.Future) {
 		timeout = true
 	})
 
+	if h.leased {
+		waiter.AddReceive(ctx.Done(), func(_ workflow.ReceiveChannel, _ bool) {
+			cancelled = true
+		})
+	}
+
 	waiter.Select(ctx)
 
+	if cancelled {
+		h.logger.warn(h.WorkflowExecutionID(), "acquire", "cancelled waiting for lock")
+		h.cancel(ctx)
+
+		return ctx.Err()
+	}
+
 	if timeout {
 		h.logger.warn(h.WorkflowExecutionID(), "acquire", "timeout waiting for lock")
+
+		if h.leased {
+			h.cancel(ctx)
+		}
+
 		return NewAcquireLockError(h.ResourceID)
 	}
 
 	if locked {
-		h.logger.info(h.WorkflowExecutionID(), "acquire", "lock acquired")
+		h.logger.info(h.WorkflowExecutionID(), "acquire", "lock acquired", "token", h.Token)
+
 		return nil
 	}
 
 	return NewAcquireLockError(h.ResourceID)
 }
 
+// grant decodes the payload of the locked signal, returning whether the lock was granted and the fencing token of the
+// grant. The mutex sends the token, mutexes recorded before ChangeLease send true and no token.
+func grant(payload json.RawMessage) (bool, uint64) {
+	token := uint64(0)
+	if err := json.Unmarshal(payload, &token); err == nil {
+		return token > 0, token
+	}
+
+	locked := false
+	_ = json.Unmarshal(payload, &locked)
+
+	return locked, 0
+}
+
+// Internal helper to withdraw a pending request. If the lock was granted in the meantime, the mutex releases it.
+func (h *Handler) cancel(ctx workflow.Context) {
+	ctx, _ = workflow.NewDisconnectedContext(ctx)
+
+	if err := workflow.
+		SignalExternalWorkflow(ctx, h.Execution.ID, h.Execution.RunID, WorkflowSignalCancel.String(), h).
+		Get(ctx, nil); err != nil {
+		h.logger.warn(h.WorkflowExecutionID(), "cancel", "unable to withdraw request", err)
+	}
+}
+
 // Internal helper to release the lock.
 func (h *Handler) release(ctx workflow.Context) error {
 	h.logger.info(h.WorkflowExecutionID(), "release", "requesting release")
@@ -189,7 +288,7 @@ func (h *Handler) release(ctx workflow.Context) error {
 	timeout := false
 	waiter := workflow.NewSelector(ctx)
 
-	waiter.AddReceive(workflow.GetSignalChannel(ctx, WorkflowSignalReleased.String()), func(c workflow.ReceiveChannel, _ bool) {
+	waiter.AddReceive(workflow.GetSignalChannel(ctx, Signal(WorkflowSignalReleased, h.Request)), func(c workflow.ReceiveChannel, _ bool) {
 		c.Receive(ctx, &released)
 	})
 
@@ -1,6 +1,8 @@
 package mutex_test
 
 import (
+	"context"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -232,11 +234,12 @@ func (s *MutexTestSuite) TestClient_OnAcquire_Success() {
 	resourceID := "test-resource-client"
 	mutexWorkflowID := "ai.ctrlplane.mutex.resource-v2." + resourceID
 
-	// Mock the AcquireMutexActivity
-	s.env.OnActivity(mutex.AcquireMutexActivity, mock.Anything, mock.Anything).Return(
-		&workflow.Execution{ID: mutexWorkflowID, RunID: "mutex-run-id"},
-		nil,
-	)
+	request := ""
+
+	// Mock the AcquireMutexActivity, keeping the ID of the request
+	s.env.OnActivity(mutex.AcquireMutexActivity, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { request = args.Get(1).(*mutex.Handler).Request }).
+		Return(&workflow.Execution{ID: mutexWorkflowID, RunID: "mutex-run-id"}, nil)
 
 	// Mock signals sent FROM Client TO MutexWorkflow (Acquire is sent via Activity, so only signals used in wait logic here)
 	// Actually, Acquire is sent via SignalExternalWorkflow inside AcquireMutexActivity helper in real usage?
@@ -250,11 +253,11 @@ func (s *MutexTestSuite) TestClient_OnAcquire_Success() {
 	// We can use RegisterDelayedCallback to simulate the signal arrival.
 
 	s.env.RegisterDelayedCallback(func() {
-		s.env.SignalWorkflow(mutex.WorkflowSignalLocked.String(), true)
+		s.env.SignalWorkflow(mutex.Signal(mutex.WorkflowSignalLocked, request), uint64(1))
 	}, 1*time.Second)
 
 	s.env.RegisterDelayedCallback(func() {
-		s.env.SignalWorkflow(mutex.WorkflowSignalReleased.String(), true)
+		s.env.SignalWorkflow(mutex.Signal(mutex.WorkflowSignalReleased, request), true)
 	}, 7*time.Second) // 1s (start) + 5s (sleep) + buffer
 
 	// Also mock the Release signal from Client -> Mutex
@@ -271,19 +274,20 @@ func (s *MutexTestSuite) TestClient_OnAcquire_PanicSafety() {
 	resourceID := "test-resource-panic"
 	mutexWorkflowID := "ai.ctrlplane.mutex.resource-v2." + resourceID
 
-	s.env.OnActivity(mutex.AcquireMutexActivity, mock.Anything, mock.Anything).Return(
-		&workflow.Execution{ID: mutexWorkflowID, RunID: "mutex-run-id"},
-		nil,
-	)
+	request := ""
+
+	s.env.OnActivity(mutex.AcquireMutexActivity, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { request = args.Get(1).(*mutex.Handler).Request }).
+		Return(&workflow.Execution{ID: mutexWorkflowID, RunID: "mutex-run-id"}, nil)
 
 	// Simulate lock acquired signal
 	s.env.RegisterDelayedCallback(func() {
-		s.env.SignalWorkflow(mutex.WorkflowSignalLocked.String(), true)
+		s.env.SignalWorkflow(mutex.Signal(mutex.WorkflowSignalLocked, request), uint64(1))
 	}, 1*time.Second)
 
 	// Simulate release confirmation signal
 	s.env.RegisterDelayedCallback(func() {
-		s.env.SignalWorkflow(mutex.WorkflowSignalReleased.String(), true)
+		s.env.SignalWorkflow(mutex.Signal(mutex.WorkflowSignalReleased, request), true)
 	}, 2*time.Second)
 
 	// Expect Release signal DESPITE panic
@@ -308,3 +312,462 @@ func (s *MutexTestSuite) TestClient_OnAcquire_PanicSafety() {
 	// Check for panic error string safely
 	s.True(strings.Contains(err.Error(), "business logic failure"), "Error should contain panic message")
 }
+
+func (s *MutexTestSuite) TestMutexWorkflow_Priority() {
+	// Scenario:
+	// 1. Client A acquires.
+	// 2. Client B queues with normal priority, then client C with high priority.
+	// 3. The query shows A as the holder, and C ahead of B.
+	// 4. On release, C gets the lock before B.
+	resourceID := "test-resource-priority"
+	handlerA := handler(resourceID, "client-A", mutex.PriorityNormal)
+	handlerB := handler(resourceID, "client-B", mutex.PriorityNormal)
+	handlerC := handler(resourceID, "client-C", mutex.PriorityHigh)
+
+	state := &mutex.MutexState{
+		Status:  mutex.MutexStatusReady,
+		Handler: handlerA,
+		Persist: true,
+	}
+
+	s.env.RegisterWorkflow(mutex.MutexWorkflow)
+
+	var eventLog []string
+
+	for _, id := range []string{"client-A", "client-B", "client-C"} {
+		s.env.OnSignalExternalWorkflow(mock.Anything, id, "run-"+id, mutex.WorkflowSignalLocked.String(), mock.Anything).
+			Run(func(args mock.Arguments) {
+				eventLog = append(eventLog, id+"-Locked")
+			}).Return(nil)
+		s.env.OnSignalExternalWorkflow(mock.Anything, id, "run-"+id, mutex.WorkflowSignalReleased.String(), mock.Anything).
+			Return(nil)
+	}
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(mutex.WorkflowSignalAcquire.String(), handlerA)
+	}, 1*time.Second)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(mutex.WorkflowSignalAcquire.String(), handlerB)
+	}, 2*time.Second)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(mutex.WorkflowSignalAcquire.String(), handlerC)
+	}, 3*time.Second)
+
+	s.env.RegisterDelayedCallback(func() {
+		val, err := s.env.QueryWorkflow(mutex.WorkflowQueryState.String())
+		s.Require().NoError(err)
+
+		info := &mutex.MutexInfo{}
+		s.Require().NoError(val.Get(info))
+
+		s.Equal(mutex.MutexStatusLocked, info.Status)
+		s.Equal("client-A", info.Holder.WorkflowExecutionID())
+		s.Equal(3*time.Second, info.HeldFor)
+		s.Require().Len(info.Waiters, 2)
+		s.Equal("client-C", info.Waiters[0].ID)
+		s.Equal(mutex.PriorityHigh, info.Waiters[0].Priority)
+		s.Equal(time.Second, info.Waiters[0].Wait)
+		s.Equal("client-B", info.Waiters[1].ID)
+		s.Equal(2*time.Second, info.Waiters[1].Wait)
+	}, 4*time.Second)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(mutex.WorkflowSignalRelease.String(), handlerA)
+	}, 5*time.Second)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(mutex.WorkflowSignalRelease.String(), handlerC)
+	}, 6*time.Second)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(mutex.WorkflowSignalRelease.String(), handlerB)
+	}, 7*time.Second)
+
+	s.env.ExecuteWorkflow(mutex.MutexWorkflow, state)
+
+	s.Equal([]string{"client-A-Locked", "client-C-Locked", "client-B-Locked"}, eventLog)
+}
+
+func (s *MutexTestSuite) TestMutexWorkflow_Cancel() {
+	// Scenario:
+	// 1. Client A acquires, client B queues.
+	// 2. Client B cancels its pending request.
+	// 3. On release, the mutex goes idle without granting B.
+	resourceID := "test-resource-cancel"
+	handlerA := handler(resourceID, "client-A", mutex.PriorityNormal)
+	handlerB := handler(resourceID, "client-B", mutex.PriorityNormal)
+
+	state := &mutex.MutexState{
+		Status:  mutex.MutexStatusReady,
+		Handler: handlerA,
+		Persist: true,
+	}
+
+	s.env.RegisterWorkflow(mutex.MutexWorkflow)
+
+	var eventLog []string
+
+	s.env.OnSignalExternalWorkflow(mock.Anything, "client-A", "run-client-A", mutex.WorkflowSignalLocked.String(), mock.Anything).
+		Run(func(args mock.Arguments) {
+			eventLog = append(eventLog, "A-Locked")
+		}).Return(nil)
+	s.env.OnSignalExternalWorkflow(mock.Anything, "client-A", "run-client-A", mutex.WorkflowSignalReleased.String(), mock.Anything).
+		Return(nil)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(mutex.WorkflowSignalAcquire.String(), handlerA)
+	}, 1*time.Second)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(mutex.WorkflowSignalAcquire.String(), handlerB)
+	}, 2*time.Second)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(mutex.WorkflowSignalCancel.String(), handlerB)
+	}, 3*time.Second)
+
+	s.env.RegisterDelayedCallback(func() {
+		val, err := s.env.QueryWorkflow(mutex.WorkflowQueryState.String())
+		s.Require().NoError(err)
+
+		info := &mutex.MutexInfo{}
+		s.Require().NoError(val.Get(info))
+		s.Empty(info.Waiters)
+	}, 4*time.Second)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(mutex.WorkflowSignalRelease.String(), handlerA)
+	}, 5*time.Second)
+
+	s.env.ExecuteWorkflow(mutex.MutexWorkflow, state)
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.Equal([]string{"A-Locked"}, eventLog)
+}
+
+func handler(resourceID, id string, priority mutex.Priority) *mutex.Handler {
+	return &mutex.Handler{
+		ResourceID: resourceID,
+		Info: &workflow.Info{
+			WorkflowExecution: workflow.Execution{ID: id, RunID: "run-" + id},
+		},
+		Timeout:  10 * time.Minute,
+		Priority: priority,
+	}
+}
+
+func (s *MutexTestSuite) TestMutexWorkflow_LeaseRenewal() {
+	// Scenario:
+	// 1. Client A acquires with a one minute lease, and renews it once.
+	// 2. Client B queues.
+	// 3. A stops renewing, the lease expires, A is told, and B gets the lock with the next fencing token.
+	resourceID := "test-resource-lease"
+	handlerA := handler(resourceID, "client-A", mutex.PriorityNormal)
+	handlerA.Timeout = time.Minute
+	handlerA.Request = "request-A"
+	handlerB := handler(resourceID, "client-B", mutex.PriorityNormal)
+	handlerB.Request = "request-B"
+
+	state := &mutex.MutexState{
+		Status:  mutex.MutexStatusReady,
+		Handler: handlerA,
+		Persist: true,
+	}
+
+	s.env.RegisterWorkflow(mutex.MutexWorkflow)
+
+	var eventLog []string
+
+	record := func(event string) func(mock.Arguments) {
+		return func(args mock.Arguments) {
+			eventLog = append(eventLog, fmt.Sprintf("%s-%d", event, args.Get(4)))
+		}
+	}
+
+	s.env.OnSignalExternalWorkflow(mock.Anything, "client-A", "run-client-A", "mutex__locked__request-A", mock.Anything).
+		Run(record("A-Locked")).Return(nil)
+	s.env.OnSignalExternalWorkflow(mock.Anything, "client-A", "run-client-A", "mutex__expired__request-A", mock.Anything).
+		Run(record("A-Expired")).Return(nil)
+	s.env.OnSignalExternalWorkflow(mock.Anything, "client-B", "run-client-B", "mutex__locked__request-B", mock.Anything).
+		Run(record("B-Locked")).Return(nil)
+	s.env.OnSignalExternalWorkflow(mock.Anything, "client-B", "run-client-B", "mutex__released__request-B", mock.Anything).
+		Return(nil)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(mutex.WorkflowSignalAcquire.String(), handlerA)
+	}, 1*time.Second)
+
+	s.env.RegisterDelayedCallback(func() {
+		renewal := *handlerA
+		renewal.Token = 1
+		s.env.SignalWorkflow(mutex.WorkflowSignalRenew.String(), &renewal)
+	}, 40*time.Second)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(mutex.WorkflowSignalAcquire.String(), handlerB)
+	}, 50*time.Second)
+
+	s.env.RegisterDelayedCallback(func() {
+		val, err := s.env.QueryWorkflow(mutex.WorkflowQueryState.String())
+		s.Require().NoError(err)
+
+		info := &mutex.MutexInfo{}
+		s.Require().NoError(val.Get(info))
+
+		s.Equal(mutex.MutexStatusLocked, info.Status, "renewed lease should outlive the original one")
+		s.Equal("client-A", info.Holder.WorkflowExecutionID())
+		s.Equal(uint64(1), info.Token)
+	}, 90*time.Second)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(mutex.WorkflowSignalRelease.String(), handlerB)
+	}, 120*time.Second)
+
+	s.env.ExecuteWorkflow(mutex.MutexWorkflow, state)
+
+	s.Equal([]string{"A-Locked-1", "A-Expired-1", "B-Locked-2"}, eventLog)
+}
+
+func (s *MutexTestSuite) TestClient_OnAcquire_LeaseLost() {
+	resourceID := "test-resource-lost"
+	mutexWorkflowID := "ai.ctrlplane.mutex.resource-v2." + resourceID
+
+	request := ""
+
+	s.env.OnActivity(mutex.AcquireMutexActivity, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { request = args.Get(1).(*mutex.Handler).Request }).
+		Return(&workflow.Execution{ID: mutexWorkflowID, RunID: "mutex-run-id"}, nil)
+
+	// Renewals are sent every third of the lease.
+	s.env.OnSignalExternalWorkflow(mock.Anything, mutexWorkflowID, "mutex-run-id", mutex.WorkflowSignalRenew.String(), mock.Anything).
+		Return(nil)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(mutex.Signal(mutex.WorkflowSignalLocked, request), uint64(7))
+	}, 1*time.Second)
+
+	// An expiry for an earlier grant is ignored.
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(mutex.Signal(mutex.WorkflowSignalExpired, request), uint64(6))
+	}, 30*time.Second)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(mutex.Signal(mutex.WorkflowSignalExpired, request), uint64(7))
+	}, 50*time.Second)
+
+	token := uint64(0)
+	cancelled := time.Duration(0)
+
+	lostWorkflow := func(ctx workflow.Context) error {
+		m, _ := mutex.New(ctx, mutex.WithResourceID(resourceID), mutex.WithTimeout(time.Minute))
+		start := workflow.Now(ctx)
+
+		return m.OnAcquire(ctx, func(c workflow.Context) {
+			token = mutex.FencingToken(c)
+			_ = workflow.Sleep(c, 10*time.Minute)
+			cancelled = workflow.Now(c).Sub(start)
+		})
+	}
+
+	s.env.ExecuteWorkflow(lostWorkflow)
+
+	s.True(s.env.IsWorkflowCompleted())
+	err := s.env.GetWorkflowError()
+	s.Require().Error(err)
+	s.Contains(err.Error(), "failed to keep lease")
+	s.Equal(uint64(7), token)
+	s.Equal(50*time.Second, cancelled, "critical section should be cancelled on lease loss")
+}
+
+func (s *MutexTestSuite) TestClient_OnAcquire_BeforeLease() {
+	// Scenario: the caller was recorded before ChangeLease, and the mutex grants the lock with true instead of a token.
+	// Expected: the lock is acquired without a token, and the lease is never renewed.
+	resourceID := "test-resource-before-lease"
+	mutexWorkflowID := "ai.ctrlplane.mutex.resource-v2." + resourceID
+
+	s.env.OnGetVersion(mutex.ChangeLease, workflow.DefaultVersion, 1).Return(workflow.DefaultVersion)
+
+	s.env.OnActivity(mutex.AcquireMutexActivity, mock.Anything, mock.Anything).Return(
+		&workflow.Execution{ID: mutexWorkflowID, RunID: "mutex-run-id"},
+		nil,
+	)
+
+	s.env.OnSignalExternalWorkflow(mock.Anything, mutexWorkflowID, "mutex-run-id", mutex.WorkflowSignalRenew.String(), mock.Anything).
+		Return(nil).Never()
+	s.env.OnSignalExternalWorkflow(mock.Anything, mutexWorkflowID, "mutex-run-id", mutex.WorkflowSignalRelease.String(), mock.Anything).
+		Return(nil).Once()
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(mutex.WorkflowSignalLocked.String(), true)
+	}, 1*time.Second)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(mutex.WorkflowSignalReleased.String(), true)
+	}, 32*time.Minute)
+
+	token := uint64(1)
+
+	beforeLease := func(ctx workflow.Context) error {
+		m, _ := mutex.New(ctx, mutex.WithResourceID(resourceID))
+
+		return m.OnAcquire(ctx, func(c workflow.Context) {
+			token = mutex.FencingToken(c)
+			_ = workflow.Sleep(c, 30*time.Minute)
+		})
+	}
+
+	s.env.ExecuteWorkflow(beforeLease)
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+	s.Zero(token)
+}
+
+func (s *MutexTestSuite) TestMutexWorkflow_BeforeLease() {
+	// Scenario: the mutex was recorded before ChangeLease, and the lease of client A expires.
+	// Expected: A is granted the lock with true, and is not told of the expiry.
+	resourceID := "test-resource-before-lease"
+	handlerA := handler(resourceID, "client-A", mutex.PriorityNormal)
+	handlerA.Timeout = time.Minute
+
+	state := &mutex.MutexState{
+		Status:  mutex.MutexStatusReady,
+		Handler: handlerA,
+		Persist: true,
+	}
+
+	s.env.RegisterWorkflow(mutex.MutexWorkflow)
+	s.env.OnGetVersion(mutex.ChangeLease, workflow.DefaultVersion, 1).Return(workflow.DefaultVersion)
+
+	s.env.OnSignalExternalWorkflow(mock.Anything, "client-A", "run-client-A", mutex.WorkflowSignalLocked.String(), true).
+		Return(nil).Once()
+	s.env.OnSignalExternalWorkflow(mock.Anything, "client-A", "run-client-A", mutex.WorkflowSignalExpired.String(), mock.Anything).
+		Return(nil).Never()
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(mutex.WorkflowSignalAcquire.String(), handlerA)
+	}, 1*time.Second)
+
+	s.env.ExecuteWorkflow(mutex.MutexWorkflow, state)
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *MutexTestSuite) TestClient_OnAcquire_StaleGrant() {
+	// Scenario:
+	// 1. The first request times out and is withdrawn, but the mutex granted it meanwhile.
+	// 2. The grant of the first request arrives while the second request waits.
+	// Expected: the stale grant is ignored, and the second request is granted with its own token.
+	resourceID := "test-resource-stale"
+	mutexWorkflowID := "ai.ctrlplane.mutex.resource-v2." + resourceID
+
+	requests := make([]string, 0)
+
+	s.env.OnActivity(mutex.AcquireMutexActivity, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { requests = append(requests, args.Get(1).(*mutex.Handler).Request) }).
+		Return(&workflow.Execution{ID: mutexWorkflowID, RunID: "mutex-run-id"}, nil)
+
+	s.env.OnSignalExternalWorkflow(mock.Anything, mutexWorkflowID, "mutex-run-id", mutex.WorkflowSignalCancel.String(), mock.Anything).
+		Return(nil).Once()
+	s.env.OnSignalExternalWorkflow(mock.Anything, mutexWorkflowID, "mutex-run-id", mutex.WorkflowSignalRelease.String(), mock.Anything).
+		Return(nil).Once()
+
+	s.env.RegisterDelayedCallback(func() {
+		s.Require().Len(requests, 2)
+		s.NotEqual(requests[0], requests[1])
+		s.env.SignalWorkflow(mutex.Signal(mutex.WorkflowSignalLocked, requests[0]), uint64(1))
+	}, mutex.MaxAcquireWait+1*time.Second)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(mutex.Signal(mutex.WorkflowSignalLocked, requests[1]), uint64(2))
+	}, mutex.MaxAcquireWait+2*time.Second)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(mutex.Signal(mutex.WorkflowSignalReleased, requests[1]), true)
+	}, mutex.MaxAcquireWait+time.Minute)
+
+	var first error
+
+	token := uint64(0)
+
+	staleWorkflow := func(ctx workflow.Context) error {
+		m, _ := mutex.New(ctx, mutex.WithResourceID(resourceID))
+
+		first = m.OnAcquire(ctx, func(_ workflow.Context) {})
+
+		return m.OnAcquire(ctx, func(c workflow.Context) {
+			token = mutex.FencingToken(c)
+			_ = workflow.Sleep(c, 30*time.Second)
+		})
+	}
+
+	s.env.ExecuteWorkflow(staleWorkflow)
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+	s.Error(first, "the first request should time out")
+	s.Equal(uint64(2), token, "the stale grant should be ignored")
+}
+
+func (s *MutexTestSuite) TestClient_OnAcquire_ExpiryOfAnotherMutex() {
+	// Scenario:
+	// 1. A workflow holds the outer mutex, and then the inner one. Both grants carry the fencing token 1.
+	// 2. The lease of the inner mutex expires.
+	// Expected: only the critical section of the inner mutex is cancelled, the outer one keeps its lease and releases.
+	requests := make(map[string]string)
+
+	s.env.OnActivity(mutex.AcquireMutexActivity, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			h := args.Get(1).(*mutex.Handler)
+			requests[h.ResourceID] = h.Request
+		}).
+		Return(func(_ context.Context, h *mutex.Handler) (*workflow.Execution, error) {
+			return &workflow.Execution{ID: "mutex-" + h.ResourceID, RunID: "run-" + h.ResourceID}, nil
+		})
+
+	s.env.OnSignalExternalWorkflow(mock.Anything, "mutex-outer", "run-outer", mutex.WorkflowSignalRelease.String(), mock.Anything).
+		Return(nil).Once()
+	s.env.OnSignalExternalWorkflow(mock.Anything, "mutex-inner", "run-inner", mutex.WorkflowSignalRelease.String(), mock.Anything).
+		Return(nil).Never()
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(mutex.Signal(mutex.WorkflowSignalLocked, requests["outer"]), uint64(1))
+	}, 1*time.Second)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(mutex.Signal(mutex.WorkflowSignalLocked, requests["inner"]), uint64(1))
+	}, 2*time.Second)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(mutex.Signal(mutex.WorkflowSignalExpired, requests["inner"]), uint64(1))
+	}, 30*time.Second)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(mutex.Signal(mutex.WorkflowSignalReleased, requests["outer"]), true)
+	}, 2*time.Minute)
+
+	var inner error
+
+	nestedWorkflow := func(ctx workflow.Context) error {
+		outer, _ := mutex.New(ctx, mutex.WithResourceID("outer"))
+		nested, _ := mutex.New(ctx, mutex.WithResourceID("inner"))
+
+		return outer.OnAcquire(ctx, func(c workflow.Context) {
+			inner = nested.OnAcquire(c, func(c workflow.Context) {
+				_ = workflow.Sleep(c, 10*time.Minute)
+			})
+
+			_ = workflow.Sleep(c, time.Minute)
+		})
+	}
+
+	s.env.ExecuteWorkflow(nestedWorkflow)
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError(), "the outer mutex should keep its lease")
+	s.Require().Error(inner)
+	s.Contains(inner.Error(), "inner: failed to keep lease")
+}
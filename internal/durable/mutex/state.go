@@ -30,14 +30,38 @@ type (
 	// MutexStatus represents the current state of the mutex.
 	MutexStatus string
 
-	// MutexState encapsulates the state of the mutex workflow.
+	// MutexState encapsulates the state of the mutex workflow. Handler is the holder of the lock while locked, and the
+	// last holder otherwise. Token is the fencing token of the last grant.
 	MutexState struct {
+		Status   MutexStatus   `json:"status"`
+		Handler  *Handler      `json:"handler"`
+		Timeout  time.Duration `json:"timeout"`
+		Persist  bool          `json:"persist"`
+		Waiters  Waiters       `json:"waiters"`
+		LockedAt time.Time     `json:"locked_at"`
+		Token    uint64        `json:"token"`
+
+		renewed bool
+		leased  bool // leased is false for the mutexes recorded before ChangeLease.
+		logger  *MutexLogger
+	}
+
+	// MutexInfo is the snapshot of the mutex returned by the state query.
+	MutexInfo struct {
 		Status  MutexStatus   `json:"status"`
-		Handler *Handler      `json:"handler"`
-		Timeout time.Duration `json:"timeout"`
-		Persist bool          `json:"persist"`
+		Holder  *Handler      `json:"holder"`   // Holder is the handler holding the lock, nil unless locked.
+		HeldFor time.Duration `json:"held_for"` // HeldFor is the time the lock has been held for.
+		Token   uint64        `json:"token"`    // Token is the fencing token of the last grant.
+		Waiters []WaiterInfo  `json:"waiters"`  // Waiters are the pending requests, in the order they will be granted.
+	}
 
-		logger *MutexLogger
+	// WaiterInfo describes a pending request for the lock.
+	WaiterInfo struct {
+		ID       string        `json:"id"`       // ID is the workflow ID of the waiter.
+		RunID    string        `json:"run_id"`   // RunID is the workflow run ID of the waiter.
+		Priority Priority      `json:"priority"` // Priority is the priority of the request.
+		Since    time.Time     `json:"since"`    // Since is the time the request was received.
+		Wait     time.Duration `json:"wait"`     // Wait is the time spent waiting so far.
 	}
 )
 
@@ -70,22 +94,50 @@ func NewMutexState(ctx workflow.Context, handler *Handler) *MutexState {
 	return state
 }
 
-// restore reinitializes the logger.
+// restore reinitializes the logger and the version of the mutex.
 func (s *MutexState) restore(ctx workflow.Context) {
 	s.logger = NewMutexControllerLogger(ctx, s.Handler.ResourceID)
+	s.leased = workflow.GetVersion(ctx, ChangeLease, workflow.DefaultVersion, 1) != workflow.DefaultVersion
 }
 
 /***
  * Query Handlers
  **/
 
-// set_query_state sets a query handler for the mutex workflow.
+// set_query_state sets a query handler for the mutex workflow, returning the holder and the ordered waiters.
 func (s *MutexState) set_query_state(ctx workflow.Context) error {
-	return workflow.SetQueryHandler(ctx, WorkflowQueryState.String(), func() (*MutexState, error) {
-		return s, nil
+	return workflow.SetQueryHandler(ctx, WorkflowQueryState.String(), func() (*MutexInfo, error) {
+		return s.info(workflow.Now(ctx)), nil
 	})
 }
 
+// info returns the snapshot of the mutex at the given time.
+func (s *MutexState) info(now time.Time) *MutexInfo {
+	info := &MutexInfo{Status: s.Status, Token: s.Token, Waiters: make([]WaiterInfo, 0, len(s.Waiters))}
+
+	if s.Status == MutexStatusLocked {
+		info.Holder = s.Handler
+		info.HeldFor = now.Sub(s.LockedAt)
+	}
+
+	for _, waiter := range s.Waiters {
+		info.Waiters = append(info.Waiters, WaiterInfo{
+			ID:       waiter.Handler.WorkflowExecutionID(),
+			RunID:    waiter.Handler.WorkflowRunID(),
+			Priority: waiter.Priority,
+			Since:    waiter.Since,
+			Wait:     now.Sub(waiter.Since),
+		})
+	}
+
+	return info
+}
+
+// waiting returns true if there are pending requests.
+func (s *MutexState) waiting() bool {
+	return len(s.Waiters) > 0
+}
+
 /***
  * State Transitions
  **/
@@ -136,13 +188,40 @@ func (s *MutexState) stop_persisting(ctx workflow.Context) {
  * Signal Handlers
  **/
 
-// on_aquire returns a callback for handling the acquire signal.
+// on_aquire returns a callback for handling the acquire signal. The request is queued, and granted once the lock is
+// free and there is no request ahead of it.
 func (s *MutexState) on_aquire(ctx workflow.Context) func(workflow.ReceiveChannel, bool) {
 	return func(c workflow.ReceiveChannel, _ bool) {
 		rx := &Handler{}
 		c.Receive(ctx, rx)
 
-		s.acquired(ctx, rx)
+		s.enqueue(ctx, rx)
+	}
+}
+
+// on_renew returns a callback for handling the renew signal. Only the holder of the current grant can extend the lease.
+func (s *MutexState) on_renew(ctx workflow.Context) func(workflow.ReceiveChannel, bool) {
+	return func(c workflow.ReceiveChannel, _ bool) {
+		rx := &Handler{}
+		c.Receive(ctx, rx)
+
+		if rx.WorkflowExecutionID() == s.Handler.WorkflowExecutionID() && rx.Token == s.Token {
+			s.renewed = true
+			s.logger.debug(s.Handler.WorkflowExecutionID(), "lease", "lease renewed", "token", s.Token)
+		} else {
+			s.logger.warn(s.Handler.WorkflowExecutionID(), "lease", "ignored renewal from non-holder", "sender", rx.WorkflowExecutionID())
+		}
+	}
+}
+
+// on_cancel returns a callback for handling the cancel signal. A pending request is removed from the queue. If the lock
+// was granted before the cancellation arrived, it is released.
+func (s *MutexState) on_cancel(ctx workflow.Context) func(workflow.ReceiveChannel, bool) {
+	return func(c workflow.ReceiveChannel, _ bool) {
+		rx := &Handler{}
+		c.Receive(ctx, rx)
+
+		s.cancelled(ctx, rx)
 	}
 }
 
@@ -173,6 +252,15 @@ func (s *MutexState) expire(ctx workflow.Context) func(workflow.Future) {
 	return func(_ workflow.Future) {
 		s.to_timeout(ctx)
 		s.logger.warn(s.Handler.WorkflowExecutionID(), "lease", "lock lease expired", "holder", s.Handler.WorkflowExecutionID())
+
+		if !s.leased {
+			return
+		}
+
+		// the holder is told, so it can stop its critical section. the lock is granted to the next waiter regardless.
+		_ = workflow.
+			SignalExternalWorkflow(ctx, s.Handler.WorkflowExecutionID(), s.Handler.WorkflowRunID(), s.signal(WorkflowSignalExpired), s.Token).
+			Get(ctx, nil)
 	}
 }
 
@@ -180,17 +268,71 @@ func (s *MutexState) expire(ctx workflow.Context) func(workflow.Future) {
  * Actions
  **/
 
+// enqueue adds the request to the waiters.
+func (s *MutexState) enqueue(ctx workflow.Context, rx *Handler) {
+	if !s.Waiters.push(rx, workflow.Now(ctx)) {
+		s.logger.warn(s.Handler.WorkflowExecutionID(), "acquire", "ignored duplicate request", "sender", rx.WorkflowExecutionID())
+		return
+	}
+
+	s.logger.info(
+		s.Handler.WorkflowExecutionID(), "acquire", "request queued",
+		"sender", rx.WorkflowExecutionID(), "priority", rx.Priority, "waiters", len(s.Waiters),
+	)
+}
+
+// cancelled withdraws the request of the sender.
+func (s *MutexState) cancelled(ctx workflow.Context, rx *Handler) {
+	if s.Status == MutexStatusLocked && rx.WorkflowExecutionID() == s.Handler.WorkflowExecutionID() {
+		s.logger.info(s.Handler.WorkflowExecutionID(), "cancel", "holder cancelled, releasing lock")
+		s.released(ctx)
+
+		return
+	}
+
+	if s.Waiters.remove(rx.WorkflowExecutionID()) {
+		s.logger.info(s.Handler.WorkflowExecutionID(), "cancel", "request withdrawn", "sender", rx.WorkflowExecutionID())
+	}
+}
+
+// extend restarts the lease once renewed. It returns true if the lease was renewed since the last call.
+func (s *MutexState) extend() bool {
+	renewed := s.renewed
+	s.renewed = false
+
+	return renewed
+}
+
+// grant hands the lock to the head of the queue and signals the client.
+func (s *MutexState) grant(ctx workflow.Context) {
+	waiter := s.Waiters.pop()
+	if waiter == nil {
+		return
+	}
+
+	s.acquired(ctx, waiter.Handler)
+}
+
 // acquired handles the lock acquisition and signals the client.
 func (s *MutexState) acquired(ctx workflow.Context, rx *Handler) {
+	s.Token++
+	rx.Token = s.Token
+
 	s.Handler = rx
 	s.Timeout = rx.Timeout
+	s.LockedAt = workflow.Now(ctx)
 	s.to_locked(ctx)
 
+	var payload any = s.Token
+	if !s.leased {
+		payload = true
+	}
+
 	_ = workflow.
-		SignalExternalWorkflow(ctx, s.Handler.WorkflowExecutionID(), s.Handler.WorkflowRunID(), WorkflowSignalLocked.String(), true).
+		SignalExternalWorkflow(ctx, s.Handler.WorkflowExecutionID(), s.Handler.WorkflowRunID(), s.signal(WorkflowSignalLocked), payload).
 		Get(ctx, nil)
 
-	s.logger.info(s.Handler.WorkflowExecutionID(), "main", "lock acquired", "holder", rx.WorkflowExecutionID())
+	s.logger.info(s.Handler.WorkflowExecutionID(), "main", "lock acquired", "holder", rx.WorkflowExecutionID(), "token", s.Token)
 }
 
 // released handles the lock release process.
@@ -198,13 +340,18 @@ func (s *MutexState) released(ctx workflow.Context) {
 	s.to_releasing(ctx)
 
 	_ = workflow.
-		SignalExternalWorkflow(ctx, s.Handler.WorkflowExecutionID(), s.Handler.WorkflowRunID(), WorkflowSignalReleased.String(), true).
+		SignalExternalWorkflow(ctx, s.Handler.WorkflowExecutionID(), s.Handler.WorkflowRunID(), s.signal(WorkflowSignalReleased), true).
 		Get(ctx, nil)
 
 	s.logger.info(s.Handler.WorkflowExecutionID(), "main", "lock released")
 	s.to_released(ctx)
 }
 
+// signal returns the name of the signal to the request of the handler.
+func (s *MutexState) signal(signal queues.Signal) string {
+	return Signal(signal, s.Handler.Request)
+}
+
 // ignore_release logs a warning for a release attempt from a non-holder.
 func (s *MutexState) ignore_release(ctx workflow.Context, senderID string) {
 	s.logger.warn(s.Handler.WorkflowExecutionID(), "release", "ignored release from non-holder", "sender", senderID)
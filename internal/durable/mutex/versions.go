@@ -0,0 +1,31 @@
+// Crafted with ❤ at Breu, Inc. <info@breu.io>, Copyright © 2024.
+//
+// Functional Source License, Version 1.1, Apache 2.0 Future License
+//
+// We hereby irrevocably grant you an additional license to use the Software under the Apache License, Version 2.0 that
+// is effective on the second anniversary of the date we make the Software available. On or after that date, you may use
+// the Software under the Apache License, Version 2.0, in which case the following will apply:
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+// the License.
+//
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package mutex
+
+// Change IDs for workflow.GetVersion.
+//
+// The mutex workflow and the workflows holding its locks are replayed against the latest code, so every change to the
+// commands they issue must be gated under a change ID declared here. A change ID must never be reused or renamed.
+const (
+	// ChangeLease queues the waiters, renews the lease of the holder and tells it when the lease expires. Before it, the
+	// mutex granted the requests as they arrived, signalled true instead of the fencing token, and never cancelled the
+	// lease timer, and the holder neither renewed the lease nor withdrew its request when cancelled.
+	ChangeLease = "mutex_lease"
+)
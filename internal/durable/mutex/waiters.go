@@ -0,0 +1,93 @@
+// Crafted with ❤ at Breu, Inc. <info@breu.io>, Copyright © 2024.
+//
+// Functional Source License, Version 1.1, Apache 2.0 Future License
+//
+// We hereby irrevocably grant you an additional license to use the Software under the Apache License, Version 2.0 that
+// is effective on the second anniversary of the date we make the Software available. On or after that date, you may use
+// the Software under the Apache License, Version 2.0, in which case the following will apply:
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+// the License.
+//
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package mutex
+
+import (
+	"slices"
+	"time"
+)
+
+type (
+	// Priority orders the waiters of the mutex. Waiters with a higher priority are granted the lock first, waiters with
+	// the same priority in the order they asked for it.
+	Priority int
+
+	// Waiter is a pending request for the lock.
+	Waiter struct {
+		Handler  *Handler  `json:"handler"`  // Handler is the handler of the workflow waiting for the lock.
+		Priority Priority  `json:"priority"` // Priority is the priority of the request.
+		Since    time.Time `json:"since"`    // Since is the time the request was received.
+	}
+
+	// Waiters is the queue of pending requests, ordered by priority and then by arrival.
+	Waiters []*Waiter
+)
+
+const (
+	PriorityLow    Priority = -10 // PriorityLow yields to every other request, e.g. housekeeping.
+	PriorityNormal Priority = 0   // PriorityNormal is the default priority.
+	PriorityHigh   Priority = 10  // PriorityHigh jumps ahead of the normal requests, e.g. hotfix merges.
+)
+
+// push adds the handler to the queue, behind the waiters with the same or a higher priority. A handler already waiting
+// keeps its place, and push returns false.
+func (w *Waiters) push(handler *Handler, since time.Time) bool {
+	if w.find(handler.WorkflowExecutionID()) >= 0 {
+		return false
+	}
+
+	idx := slices.IndexFunc(*w, func(waiter *Waiter) bool { return waiter.Priority < handler.Priority })
+	if idx < 0 {
+		idx = len(*w)
+	}
+
+	*w = slices.Insert(*w, idx, &Waiter{Handler: handler, Priority: handler.Priority, Since: since})
+
+	return true
+}
+
+// pop removes and returns the head of the queue. It returns nil if the queue is empty.
+func (w *Waiters) pop() *Waiter {
+	if len(*w) == 0 {
+		return nil
+	}
+
+	head := (*w)[0]
+	*w = slices.Delete(*w, 0, 1)
+
+	return head
+}
+
+// remove removes the waiter of the workflow execution. It returns false if the workflow is not waiting.
+func (w *Waiters) remove(id string) bool {
+	idx := w.find(id)
+	if idx < 0 {
+		return false
+	}
+
+	*w = slices.Delete(*w, idx, idx+1)
+
+	return true
+}
+
+// find returns the position of the waiter of the workflow execution, or -1.
+func (w Waiters) find(id string) int {
+	return slices.IndexFunc(w, func(waiter *Waiter) bool { return waiter.Handler.WorkflowExecutionID() == id })
+}
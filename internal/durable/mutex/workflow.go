@@ -35,10 +35,14 @@ const (
 // MutexWorkflow is the mutex workflow. It controls access to a resource.
 //
 // It operates as a serialized state machine:
-// 1. Wait for Acquire (or Idle Timeout).
-// 2. Lock Resource.
-// 3. Wait for Release (or Lease Timeout).
+// 1. Queue Acquire requests, ordered by priority (or Idle Timeout when none are pending).
+// 2. Lock Resource for the head of the queue.
+// 3. Wait for Release (or Lease Timeout), queueing the requests that arrive meanwhile. Renewals by the holder restart
+// the lease, and the holder is notified when the lease expires.
 // 4. Repeat.
+//
+// Pending requests can be withdrawn at any time with the cancel signal. The mutexes recorded before ChangeLease keep the
+// commands they were recorded with, see ChangeLease.
 func MutexWorkflow(ctx workflow.Context, state *MutexState) error {
 	state.restore(ctx)
 
@@ -56,29 +60,55 @@ func MutexWorkflow(ctx workflow.Context, state *MutexState) error {
 		tick.Send(ctx, true)
 	})
 
+	acquire := workflow.GetSignalChannel(ctx, WorkflowSignalAcquire.String())
+	release := workflow.GetSignalChannel(ctx, WorkflowSignalRelease.String())
+	cancel := workflow.GetSignalChannel(ctx, WorkflowSignalCancel.String())
+	renew := workflow.GetSignalChannel(ctx, WorkflowSignalRenew.String())
+
 	for state.Persist {
 		state.ready(ctx)
 		idle.Restart(ctx, IdleTimeout)
 
-		workflow.NewSelector(ctx).
-			AddReceive(workflow.GetSignalChannel(ctx, WorkflowSignalAcquire.String()), state.on_aquire(ctx)).
-			AddReceive(tick, state.stop(ctx)).
-			Select(ctx)
+		for !state.waiting() && state.Persist {
+			workflow.NewSelector(ctx).
+				AddReceive(acquire, state.on_aquire(ctx)).
+				AddReceive(cancel, state.on_cancel(ctx)).
+				AddReceive(tick, state.stop(ctx)).
+				Select(ctx)
+		}
+
+		if !state.Persist {
+			break
+		}
 
-		if state.Status == MutexStatusLocked {
-			idle.Restart(ctx, LongTimeout)
-			lease := workflow.NewTimer(ctx, state.Timeout)
+		state.grant(ctx)
 
-			for state.Status == MutexStatusLocked {
-				workflow.NewSelector(ctx).
-					AddReceive(workflow.GetSignalChannel(ctx, WorkflowSignalRelease.String()), state.on_release(ctx)).
-					AddFuture(lease, state.expire(ctx)).
-					Select(ctx)
+		idle.Restart(ctx, LongTimeout)
+
+		leasectx, stop := workflow.WithCancel(ctx)
+		lease := workflow.NewTimer(leasectx, state.Timeout)
+
+		for state.Status == MutexStatusLocked {
+			workflow.NewSelector(ctx).
+				AddReceive(release, state.on_release(ctx)).
+				AddReceive(acquire, state.on_aquire(ctx)).
+				AddReceive(cancel, state.on_cancel(ctx)).
+				AddReceive(renew, state.on_renew(ctx)).
+				AddFuture(lease, state.expire(ctx)).
+				Select(ctx)
+
+			// a renewal restarts the lease from now.
+			if state.extend() && state.Status == MutexStatusLocked {
+				stop()
+
+				leasectx, stop = workflow.WithCancel(ctx)
+				lease = workflow.NewTimer(leasectx, state.Timeout)
 			}
 		}
 
-		if !state.Persist {
-			break
+		// mutexes recorded before ChangeLease leave the lease timer running.
+		if state.leased {
+			stop()
 		}
 	}
 
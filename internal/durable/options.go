@@ -1,6 +1,8 @@
 package durable
 
 import (
+	"maps"
+	"slices"
 	"strings"
 
 	"go.breu.io/durex/workflows"
@@ -54,6 +56,8 @@ type (
 
 		MaximumAttempts *int32   `json:"maximum_attempts,omitempty"`
 		IgnoreErrors    []string `json:"ignored_errors,omitempty"`
+
+		keys []string // keys holds the meta keys in the order they were set, see IDSuffix.
 	}
 
 	WorkflowOptionBuilder func(*WorkflowOptions)
@@ -117,9 +121,20 @@ func (o *WorkflowOptions) IDSuffix() string {
 		parts = append(parts, *o.ActionID)
 	}
 
-	// Metadata
-	for k, v := range o.Meta {
-		parts = append(parts, k, v)
+	// Metadata, in the order it was set, so that the same options always yield the same ID. Keys set on the map
+	// directly follow, sorted.
+	keys := slices.Clone(o.keys)
+
+	for _, k := range slices.Sorted(maps.Keys(o.Meta)) {
+		if !slices.Contains(keys, k) {
+			keys = append(keys, k)
+		}
+	}
+
+	for _, k := range keys {
+		if v, ok := o.Meta[k]; ok {
+			parts = append(parts, k, v)
+		}
 	}
 
 	// Sanitization and joining
@@ -218,6 +233,10 @@ func WithMeta(k, v string) WorkflowOptionBuilder {
 			o.Meta = make(map[string]string)
 		}
 
+		if _, ok := o.Meta[k]; !ok {
+			o.keys = append(o.keys, k)
+		}
+
 		o.Meta[k] = v
 	}
 }
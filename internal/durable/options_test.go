@@ -0,0 +1,27 @@
+package durable_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.breu.io/quantm/internal/durable"
+)
+
+// TestWorkflowOptions_IDSuffix_Meta checks that the meta keys are joined in the order they were set, so that the
+// options of a branch always yield the same workflow ID.
+func TestWorkflowOptions_IDSuffix_Meta(t *testing.T) {
+	t.Parallel()
+
+	for range 32 {
+		opts := durable.NewWorkflowOptions(
+			durable.WithOrg("acme"),
+			durable.WithSubject("repos"),
+			durable.WithSubjectID("quantm"),
+			durable.WithMeta("name", "quantm"),
+			durable.WithMeta("branch", "feature"),
+		)
+
+		assert.Equal(t, "org.acme.repos.quantm.name.quantm.branch.feature", opts.IDSuffix())
+	}
+}
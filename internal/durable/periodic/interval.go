@@ -36,11 +36,28 @@
 //	// Restart with a 2-second interval (cancels current, starts new immediately).
 //	timer.Restart(ctx, 2*time.Second)
 //
+//	// Delay the next tick by an hour, once (cancels current, the ticks after it follow the interval again).
+//	timer.Delay(ctx, time.Hour)
+//
 //	// Stop the timer.
 //	timer.Stop(ctx)
+//
+// Besides fixed durations, intervals tick on schedules: cron expressions in a timezone, calendars limiting the ticks to
+// the working hours, and random jitter spreading the ticks of many workflows.
+//
+//	// Tick at 9am on weekdays in the timezone, plus up to 5 minutes.
+//	daily, _ := periodic.Cron("0 9 * * 1-5", location)
+//	timer := periodic.NewSchedule(ctx, daily, periodic.WithJitter(5*time.Minute))
+//
+//	// Tick every 4 hours, deferring the ticks outside of 9am to 5pm, Monday to Friday.
+//	timer := periodic.NewSchedule(ctx, periodic.BusinessHours(periodic.Every(4*time.Hour), location))
+//
+// Schedules are computed on the workflow clock, and the jitter is recorded as a side effect, keeping the intervals
+// deterministic on replay.
 package periodic
 
 import (
+	"math/rand/v2"
 	"time"
 
 	"go.temporal.io/sdk/workflow"
@@ -49,11 +66,29 @@ import (
 type (
 	interval struct {
 		running  bool             // Indicates if the interval is currently active.
-		duration time.Duration    // The duration of each interval.
+		duration time.Duration    // The duration of each interval, for fixed schedules.
+		schedule Schedule         // The schedule of the ticks.
+		jitter   time.Duration    // The maximum random delay added to each tick.
+		skew     time.Duration    // The random delay of the next tick.
 		until    time.Time        // The time when the next interval will expire.
-		channel  workflow.Channel // A channel for receiving new durations or stop signals.
+		delayed  bool             // Indicates if the next tick is delayed, see Delay.
+		channel  workflow.Channel // A channel for receiving new schedules or stop signals.
+	}
+
+	// command is sent to a running interval. A nil schedule stops the interval.
+	command struct {
+		schedule Schedule
 	}
 
+	// once ticks once at the given time, then hands over to the schedule it delays.
+	once struct {
+		at       time.Time
+		schedule Schedule
+	}
+
+	// Option configures an Interval.
+	Option func(*interval)
+
 	// Interval manages recurring intervals within Temporal workflows.
 	Interval interface {
 		// Tick blocks until the interval elapses. Best used with for loops.
@@ -65,6 +100,13 @@ type (
 		// Restart immediately cancels the current interval and begins a new one.
 		Restart(ctx workflow.Context, duration time.Duration)
 
+		// Reschedule immediately cancels the current interval and begins a new one on the schedule.
+		Reschedule(ctx workflow.Context, schedule Schedule)
+
+		// Delay immediately cancels the current interval and delays the next tick by the duration, once. The ticks after
+		// it follow the schedule again, unless the interval is rescheduled in the meantime.
+		Delay(ctx workflow.Context, duration time.Duration)
+
 		// Reset restarts the interval with its initial duration.
 		Reset(ctx workflow.Context)
 
@@ -73,57 +115,78 @@ type (
 	}
 )
 
+// WithJitter delays each tick by a random duration up to max, e.g. to spread the notifications of many workflows due at
+// the same time.
+func WithJitter(max time.Duration) Option {
+	return func(t *interval) {
+		t.jitter = max
+	}
+}
+
 func (t *interval) Adjust(ctx workflow.Context, duration time.Duration) {
-	t.running = true
-	t.wait(ctx)
-	t.update(ctx, duration)
-	t.running = false
+	t.adjust(ctx, Every(duration))
 }
 
 func (t *interval) Restart(ctx workflow.Context, duration time.Duration) {
+	t.Reschedule(ctx, Every(duration))
+}
+
+func (t *interval) Reschedule(ctx workflow.Context, schedule Schedule) {
 	if t.running {
-		t.channel.Send(ctx, duration)
+		t.channel.Send(ctx, &command{schedule: schedule})
 	} else {
-		t.update(ctx, duration)
+		t.update(ctx, schedule)
 	}
 }
 
+func (t *interval) Delay(ctx workflow.Context, duration time.Duration) {
+	t.Reschedule(ctx, &once{at: workflow.Now(ctx).Add(duration), schedule: t.schedule})
+}
+
 func (t *interval) Tick(ctx workflow.Context) {
-	t.Adjust(ctx, t.duration)
+	t.adjust(ctx, t.schedule)
 }
 
 func (t *interval) Reset(ctx workflow.Context) {
-	t.Restart(ctx, t.duration)
+	t.Reschedule(ctx, t.schedule)
 }
 
 func (t *interval) Stop(ctx workflow.Context) {
 	if t.running {
-		t.channel.Send(ctx, time.Duration(0))
+		t.channel.Send(ctx, &command{})
 	}
 }
 
-// wait manages the execution loop of the interval, waiting for either the timer to expire or a new duration to be
+// adjust blocks until the current interval elapses, then updates the schedule.
+func (t *interval) adjust(ctx workflow.Context, schedule Schedule) {
+	t.running = true
+	t.wait(ctx)
+	t.update(ctx, schedule)
+	t.running = false
+}
+
+// wait manages the execution loop of the interval, waiting for either the timer to expire or a new schedule to be
 // received on the channel.
 //
-//   - If a new duration is received, it updates the interval's duration and resets the time until the next tick.
-//   - If a 0 duration is received, it stops the loop, effectively canceling the interval.
+//   - If a new schedule is received, it updates the interval's schedule and resets the time until the next tick.
+//   - If a stop is received, it stops the loop, effectively canceling the interval.
 func (t *interval) wait(ctx workflow.Context) {
 	done := false
 
 	for !done && ctx.Err() == nil {
 		_ctx, cancel := workflow.WithCancel(ctx)
-		duration := time.Duration(0)
-		timer := workflow.NewTimer(_ctx, t.duration)
+		cmd := &command{}
+		timer := workflow.NewTimer(_ctx, t.delay(_ctx))
 		selector := workflow.NewSelector(_ctx)
 
 		selector.AddReceive(t.channel, func(channel workflow.ReceiveChannel, more bool) {
-			channel.Receive(_ctx, &duration)
+			channel.Receive(_ctx, &cmd)
 			cancel()
 
-			if duration == 0 {
+			if cmd.schedule == nil {
 				done = true
 			} else {
-				t.update(_ctx, duration)
+				t.update(_ctx, cmd.schedule)
 			}
 		})
 
@@ -137,9 +200,54 @@ func (t *interval) wait(ctx workflow.Context) {
 	}
 }
 
-func (t *interval) update(ctx workflow.Context, duration time.Duration) {
-	t.duration = duration
-	t.until = Now(ctx).Add(duration)
+// delay returns the time to wait for the next tick. Fixed durations wait the full duration from the start of the wait,
+// other schedules, and delayed ticks, wait until their next tick is due.
+func (t *interval) delay(ctx workflow.Context) time.Duration {
+	if _, ok := t.schedule.(Every); ok && !t.delayed {
+		return t.duration + t.skew
+	}
+
+	return max(t.until.Sub(workflow.Now(ctx)), 0)
+}
+
+// update sets the schedule, and computes the time of the next tick. Schedules are computed on the workflow clock, the
+// same clock the timers run on. A delayed tick keeps the schedule it delays, so that the tick after it is computed on
+// the schedule again.
+func (t *interval) update(ctx workflow.Context, schedule Schedule) {
+	if delayed, ok := schedule.(*once); ok {
+		t.schedule = delayed.schedule
+		t.skew = 0
+		t.until = delayed.at
+		t.delayed = true
+
+		return
+	}
+
+	t.schedule = schedule
+	t.delayed = false
+	t.skew = t.offset(ctx)
+
+	if every, ok := schedule.(Every); ok {
+		t.duration = time.Duration(every)
+		t.until = Now(ctx).Add(t.duration + t.skew)
+
+		return
+	}
+
+	t.until = schedule.Next(workflow.Now(ctx)).Add(t.skew)
+}
+
+// offset returns the random delay of the next tick, recorded as a side effect so the replays see the same value.
+func (t *interval) offset(ctx workflow.Context) time.Duration {
+	if t.jitter <= 0 {
+		return 0
+	}
+
+	var offset time.Duration
+
+	_ = workflow.SideEffect(ctx, func(_ctx workflow.Context) any { return time.Duration(rand.Int64N(int64(t.jitter))) }).Get(&offset)
+
+	return offset
 }
 
 // Now returns the current time using a side effect.
@@ -159,9 +267,22 @@ func Now(ctx workflow.Context) time.Time {
 //
 //	timer := periodic.New(ctx, 5 * time.Second) // Create a new interval timer with a 5-second duration
 func New(ctx workflow.Context, duration time.Duration) Interval {
-	return &interval{
-		duration: duration,
-		until:    Now(ctx).Add(duration),
-		channel:  workflow.NewChannel(ctx),
+	return NewSchedule(ctx, Every(duration))
+}
+
+// NewSchedule creates a new Interval ticking on the schedule.
+//
+// Example:
+//
+//	daily, _ := periodic.Cron("0 9 * * 1-5", location)
+//	timer := periodic.NewSchedule(ctx, daily, periodic.WithJitter(5*time.Minute)) // Weekdays, 9am to 9:05am
+func NewSchedule(ctx workflow.Context, schedule Schedule, opts ...Option) Interval {
+	t := &interval{channel: workflow.NewChannel(ctx)}
+	for _, opt := range opts {
+		opt(t)
 	}
+
+	t.update(ctx, schedule)
+
+	return t
 }
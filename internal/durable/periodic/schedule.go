@@ -0,0 +1,130 @@
+// Crafted with ❤ at Breu, Inc. <info@breu.io>, Copyright © 2024.
+//
+// Functional Source License, Version 1.1, Apache 2.0 Future License
+//
+// We hereby irrevocably grant you an additional license to use the Software under the Apache License, Version 2.0 that
+// is effective on the second anniversary of the date we make the Software available. On or after that date, you may use
+// the Software under the Apache License, Version 2.0, in which case the following will apply:
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+// the License.
+//
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package periodic
+
+import (
+	"slices"
+	"time"
+
+	"github.com/robfig/cron"
+)
+
+type (
+	// Schedule computes when the next tick of an interval is due.
+	Schedule interface {
+		// Next returns the time of the next tick after the given time.
+		Next(after time.Time) time.Time
+	}
+
+	// Every ticks at a fixed duration after the start of each interval.
+	Every time.Duration
+
+	// Calendar defers the ticks of a schedule falling outside of the working hours to the next opening.
+	Calendar struct {
+		Schedule Schedule       // Schedule is the underlying schedule.
+		Location *time.Location // Location is the timezone of the working hours. nil means UTC.
+		Days     []time.Weekday // Days are the working days. Empty means every day.
+		Open     time.Duration  // Open is the start of the working hours, as the offset from midnight.
+		Close    time.Duration  // Close is the end of the working hours, as the offset from midnight.
+	}
+
+	// crontab ticks at the times of a cron expression, in a timezone.
+	crontab struct {
+		spec     string
+		schedule cron.Schedule
+		location *time.Location
+	}
+)
+
+func (e Every) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(e))
+}
+
+func (o *once) Next(_ time.Time) time.Time {
+	return o.at
+}
+
+func (c *crontab) Next(after time.Time) time.Time {
+	return c.schedule.Next(after.In(c.location))
+}
+
+func (c *crontab) String() string {
+	return c.spec + " " + c.location.String()
+}
+
+func (c *Calendar) Next(after time.Time) time.Time {
+	return c.within(c.Schedule.Next(after))
+}
+
+// within returns t if it falls within the working hours, otherwise the next opening.
+func (c *Calendar) within(t time.Time) time.Time {
+	location := c.Location
+	if location == nil {
+		location = time.UTC
+	}
+
+	t = t.In(location)
+
+	for range 8 {
+		y, m, d := t.Date()
+
+		// the offsets are applied to the wall clock, so 9am stays 9am on the days the clocks change.
+		if len(c.Days) == 0 || slices.Contains(c.Days, t.Weekday()) {
+			if open := time.Date(y, m, d, 0, 0, 0, int(c.Open), location); t.Before(open) {
+				return open
+			}
+
+			if t.Before(time.Date(y, m, d, 0, 0, 0, int(c.Close), location)) {
+				return t
+			}
+		}
+
+		t = time.Date(y, m, d+1, 0, 0, 0, 0, location)
+	}
+
+	return t
+}
+
+// Cron returns the schedule of a standard cron expression, e.g. "0 9 * * 1-5", or a descriptor, e.g. "@daily",
+// evaluated in the timezone. nil means UTC.
+func Cron(spec string, location *time.Location) (Schedule, error) {
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if location == nil {
+		location = time.UTC
+	}
+
+	return &crontab{spec: spec, schedule: schedule, location: location}, nil
+}
+
+// BusinessHours returns the calendar deferring the ticks of the schedule to the business hours, 9am to 5pm from Monday
+// to Friday, in the timezone.
+func BusinessHours(schedule Schedule, location *time.Location) *Calendar {
+	return &Calendar{
+		Schedule: schedule,
+		Location: location,
+		Days:     []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+		Open:     9 * time.Hour,
+		Close:    17 * time.Hour,
+	}
+}
@@ -0,0 +1,160 @@
+package periodic_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+
+	"go.breu.io/quantm/internal/durable/periodic"
+)
+
+func TestCron(t *testing.T) {
+	t.Parallel()
+
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	require.NoError(t, err)
+
+	daily, err := periodic.Cron("0 9 * * 1-5", kolkata)
+	require.NoError(t, err)
+
+	// Friday, 2024-03-08 04:00 UTC is 09:30 in Kolkata, the next tick is on Monday.
+	next := daily.Next(time.Date(2024, 3, 8, 4, 0, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2024, 3, 11, 9, 0, 0, 0, kolkata), next)
+
+	_, err = periodic.Cron("not a cron", nil)
+	assert.Error(t, err)
+}
+
+func TestBusinessHours(t *testing.T) {
+	t.Parallel()
+
+	newyork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	hours := periodic.BusinessHours(periodic.Every(4*time.Hour), newyork)
+
+	tests := []struct {
+		name  string
+		after time.Time
+		want  time.Time
+	}{
+		{"within", time.Date(2024, 3, 5, 9, 30, 0, 0, newyork), time.Date(2024, 3, 5, 13, 30, 0, 0, newyork)},
+		{"after close", time.Date(2024, 3, 5, 15, 0, 0, 0, newyork), time.Date(2024, 3, 6, 9, 0, 0, 0, newyork)},
+		{"before open", time.Date(2024, 3, 5, 2, 0, 0, 0, newyork), time.Date(2024, 3, 5, 9, 0, 0, 0, newyork)},
+		{"weekend", time.Date(2024, 3, 8, 16, 0, 0, 0, newyork), time.Date(2024, 3, 11, 9, 0, 0, 0, newyork)},
+		{"clocks change", time.Date(2024, 3, 9, 12, 0, 0, 0, newyork), time.Date(2024, 3, 11, 9, 0, 0, 0, newyork)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, tt.want.Equal(hours.Next(tt.after)), "want %s, got %s", tt.want, hours.Next(tt.after).In(newyork))
+		})
+	}
+}
+
+func TestInterval_Schedule(t *testing.T) {
+	t.Parallel()
+
+	suite := &testsuite.WorkflowTestSuite{}
+	env := suite.NewTestWorkflowEnvironment()
+	start := time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)
+	env.SetStartTime(start)
+
+	ticks := make([]time.Time, 0)
+
+	env.ExecuteWorkflow(func(ctx workflow.Context) error {
+		daily, _ := periodic.Cron("0 9 * * *", time.UTC)
+		timer := periodic.NewSchedule(ctx, daily)
+
+		for range 2 {
+			timer.Tick(ctx)
+			ticks = append(ticks, workflow.Now(ctx))
+		}
+
+		// restarting with a duration keeps the fixed interval semantics.
+		timer.Restart(ctx, time.Hour)
+		timer.Tick(ctx)
+		ticks = append(ticks, workflow.Now(ctx))
+
+		return nil
+	})
+
+	require.NoError(t, env.GetWorkflowError())
+	assert.Equal(t, []time.Time{
+		time.Date(2024, 3, 6, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 7, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 7, 10, 0, 0, 0, time.UTC),
+	}, utc(ticks))
+}
+
+func TestInterval_Delay(t *testing.T) {
+	t.Parallel()
+
+	suite := &testsuite.WorkflowTestSuite{}
+	env := suite.NewTestWorkflowEnvironment()
+	start := time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)
+	env.SetStartTime(start)
+
+	ticks := make([]time.Time, 0)
+
+	env.ExecuteWorkflow(func(ctx workflow.Context) error {
+		daily, _ := periodic.Cron("0 9 * * *", time.UTC)
+		timer := periodic.NewSchedule(ctx, daily)
+
+		workflow.Go(ctx, func(ctx workflow.Context) {
+			for range 3 {
+				timer.Tick(ctx)
+				ticks = append(ticks, workflow.Now(ctx))
+			}
+		})
+
+		// the delay applies to the pending tick only, the daily schedule is restored once it fires.
+		_ = workflow.Sleep(ctx, time.Hour)
+		timer.Delay(ctx, 3*24*time.Hour)
+
+		return workflow.Await(ctx, func() bool { return len(ticks) == 3 })
+	})
+
+	require.NoError(t, env.GetWorkflowError())
+	assert.Equal(t, []time.Time{
+		time.Date(2024, 3, 8, 11, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 9, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC),
+	}, utc(ticks))
+}
+
+func TestInterval_Jitter(t *testing.T) {
+	t.Parallel()
+
+	suite := &testsuite.WorkflowTestSuite{}
+	env := suite.NewTestWorkflowEnvironment()
+	start := time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)
+	env.SetStartTime(start)
+
+	var tick time.Time
+
+	env.ExecuteWorkflow(func(ctx workflow.Context) error {
+		timer := periodic.NewSchedule(ctx, periodic.Every(time.Hour), periodic.WithJitter(10*time.Minute))
+		timer.Tick(ctx)
+		tick = workflow.Now(ctx)
+
+		return nil
+	})
+
+	require.NoError(t, env.GetWorkflowError())
+	assert.False(t, tick.Before(start.Add(time.Hour)))
+	assert.True(t, tick.Before(start.Add(time.Hour+10*time.Minute)))
+}
+
+func utc(times []time.Time) []time.Time {
+	out := make([]time.Time, 0, len(times))
+	for _, t := range times {
+		out = append(out, t.UTC())
+	}
+
+	return out
+}
@@ -0,0 +1,75 @@
+// Crafted with ❤ at Breu, Inc. <info@breu.io>, Copyright © 2024.
+//
+// Functional Source License, Version 1.1, Apache 2.0 Future License
+//
+// We hereby irrevocably grant you an additional license to use the Software under the Apache License, Version 2.0 that
+// is effective on the second anniversary of the date we make the Software available. On or after that date, you may use
+// the Software under the Apache License, Version 2.0, in which case the following will apply:
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+// the License.
+//
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package rwlock
+
+import (
+	"context"
+	"sync"
+
+	"go.breu.io/durex/queues"
+	"go.breu.io/durex/workflows"
+	"go.temporal.io/sdk/workflow"
+)
+
+var (
+	_q     queues.Queue
+	_qonce sync.Once
+)
+
+// Queue returns the singleton instance of the queues.Queue.
+//
+// Like the mutex queue, it must be instantiated during application startup, prior to any workflow execution logic
+// accessing it.
+func Queue(opts ...queues.QueueOption) queues.Queue {
+	_qonce.Do(func() {
+		_q = queues.New(opts...)
+	})
+
+	return _q
+}
+
+// RWLockWorkflowOptions returns workflow options for the rwlock of the resource.
+func RWLockWorkflowOptions(resource_id string) workflows.Options {
+	opts, _ := workflows.NewOptions(
+		workflows.WithBlock("rwlock"),
+		workflows.WithBlockID(resource_id),
+	)
+
+	return opts
+}
+
+// AcquireRWLockActivity signals the rwlock workflow to acquire the lock.
+//
+// If the workflow is not already running, it is started.
+func AcquireRWLockActivity(ctx context.Context, payload *Handler) (*workflow.Execution, error) {
+	exe, err := Queue().SignalWithStartWorkflow(
+		ctx,
+		RWLockWorkflowOptions(payload.ResourceID),
+		WorkflowSignalAcquire,
+		payload,
+		RWLockWorkflow,
+		NewState(payload),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &workflow.Execution{ID: exe.GetID(), RunID: exe.GetRunID()}, nil
+}
@@ -0,0 +1,45 @@
+// Crafted with ❤ at Breu, Inc. <info@breu.io>, Copyright © 2024.
+//
+// Functional Source License, Version 1.1, Apache 2.0 Future License
+//
+// We hereby irrevocably grant you an additional license to use the Software under the Apache License, Version 2.0 that
+// is effective on the second anniversary of the date we make the Software available. On or after that date, you may use
+// the Software under the Apache License, Version 2.0, in which case the following will apply:
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+// the License.
+//
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+// Package rwlock provides a distributed, durable read/write lock for Temporal workflows.
+//
+// It follows the workflow-per-resource design of the mutex package. Many workflows can read at the same time, while a
+// writer holds the resource exclusively. Requests are granted in arrival order, so a waiting writer holds back the
+// readers that arrive after it and is never starved.
+//
+// Usage:
+//
+//	lock, err := rwlock.New(ctx, rwlock.WithResourceID(repoID))
+//	if err != nil {
+//	    return err
+//	}
+//
+//	err = lock.OnRead(ctx, func(readCtx workflow.Context) {
+//	    // Shared with the other readers.
+//	})
+//
+//	err = lock.OnWrite(ctx, func(writeCtx workflow.Context) {
+//	    // Exclusive.
+//	})
+//
+// The lease of a lock runs for the timeout, and is renewed while the function runs. If it expires nonetheless, e.g. the
+// holder stopped making progress, the context passed to the function is cancelled, the lock is handed to the next
+// waiters, and the call returns an error. The lock continues as new when the server suggests it, and shuts down when
+// idle. The machinery is shared with the semaphore package, see the grants package.
+package rwlock
@@ -0,0 +1,172 @@
+// Crafted with ❤ at Breu, Inc. <info@breu.io>, Copyright © 2024.
+//
+// Functional Source License, Version 1.1, Apache 2.0 Future License
+//
+// We hereby irrevocably grant you an additional license to use the Software under the Apache License, Version 2.0 that
+// is effective on the second anniversary of the date we make the Software available. On or after that date, you may use
+// the Software under the Apache License, Version 2.0, in which case the following will apply:
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+// the License.
+//
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package rwlock
+
+import (
+	"errors"
+	"time"
+
+	"go.breu.io/durex/queues"
+	"go.temporal.io/sdk/log"
+	"go.temporal.io/sdk/workflow"
+
+	"go.breu.io/quantm/internal/durable/grants"
+)
+
+const (
+	DefaultTimeout = 10 * time.Minute      // DefaultTimeout is the default lease timeout of a lock.
+	SignalTimeout  = grants.SignalTimeout  // SignalTimeout is the timeout for signal handshakes.
+	MaxAcquireWait = grants.MaxAcquireWait // MaxAcquireWait is the maximum time to wait for a lock.
+	IdleTimeout    = grants.IdleTimeout    // IdleTimeout shuts the lock down once nobody holds or waits for it.
+	LongTimeout    = grants.LongTimeout    // Effectively infinite for "pausing" the idle timer
+)
+
+const (
+	ModeRead  Mode = "read"  // ModeRead shares the lock with the other readers.
+	ModeWrite Mode = "write" // ModeWrite holds the lock exclusively.
+)
+
+const (
+	WorkflowSignalAcquire  queues.Signal = "rwlock__acquire"
+	WorkflowSignalAcquired queues.Signal = "rwlock__acquired"
+	WorkflowSignalRelease  queues.Signal = "rwlock__release"
+	WorkflowSignalReleased queues.Signal = "rwlock__released"
+	WorkflowSignalCancel   queues.Signal = "rwlock__cancel"
+	WorkflowSignalRenew    queues.Signal = "rwlock__renew"
+	WorkflowSignalExpired  queues.Signal = "rwlock__expired"
+)
+
+var (
+	ErrNilContext   = errors.New("contexts not initialized")
+	ErrNoResourceID = errors.New("no resource ID provided")
+)
+
+type (
+	Option func(*Handler)
+
+	// Mode is the mode a lock is requested in.
+	Mode string
+
+	// RWLock defines the signature for the workflow read/write lock.
+	RWLock interface {
+		// OnRead blocks until the lock is acquired for reading, executes fn, and then releases the lock.
+		// It returns an error if the lock cannot be acquired, the context is cancelled, or the lock expires while fn
+		// runs. The context passed to fn is cancelled when the lock expires.
+		OnRead(ctx workflow.Context, fn func(workflow.Context)) error
+
+		// OnWrite is like OnRead, but holds the lock exclusively.
+		OnWrite(ctx workflow.Context, fn func(workflow.Context)) error
+	}
+
+	// Handler is the RWLock handler.
+	Handler struct {
+		grants.Ticket
+
+		Mode   Mode `json:"mode"` // Mode is the mode of the current request.
+		logger log.Logger
+	}
+)
+
+// WithResourceID sets the resource ID for the rwlock workflow.
+func WithResourceID(id string) Option {
+	return func(h *Handler) {
+		h.ResourceID = id
+	}
+}
+
+// WithTimeout sets the lease timeout of the lock.
+func WithTimeout(timeout time.Duration) Option {
+	return func(h *Handler) {
+		h.Timeout = timeout
+	}
+}
+
+// New returns a new RWLock.
+func New(ctx workflow.Context, opts ...Option) (RWLock, error) {
+	h := &Handler{Ticket: grants.Ticket{Timeout: DefaultTimeout}}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	h.Info = workflow.GetInfo(ctx)
+	h.logger = log.With(workflow.GetLogger(ctx), "rwlock_id", h.ResourceID, "handler_id", h.WorkflowExecutionID())
+
+	if err := h.validate(); err != nil {
+		h.logger.Error("rwlock: validate error", "error", err)
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// OnRead blocks until the lock is acquired for reading (or timeout), executes the closure, and releases the lock. The
+// lock is renewed while the closure runs.
+func (h *Handler) OnRead(ctx workflow.Context, fn func(workflow.Context)) error {
+	rx := h.with(ModeRead)
+	return grants.Hold(ctx, spec(), rx, rx.logger, fn)
+}
+
+// OnWrite blocks until the lock is acquired for writing (or timeout), executes the closure, and releases the lock. The
+// lock is renewed while the closure runs.
+func (h *Handler) OnWrite(ctx workflow.Context, fn func(workflow.Context)) error {
+	rx := h.with(ModeWrite)
+	return grants.Hold(ctx, spec(), rx, rx.logger, fn)
+}
+
+// with returns a copy of the handler requesting the lock in the mode.
+func (h *Handler) with(mode Mode) *Handler {
+	rx := *h
+	rx.Mode = mode
+	rx.logger = log.With(h.logger, "mode", mode)
+
+	return &rx
+}
+
+// validate checks if the lock is properly configured.
+func (h *Handler) validate() error {
+	if h.ResourceID == "" {
+		return ErrNoResourceID
+	}
+
+	if h.Info == nil {
+		return ErrNilContext
+	}
+
+	return nil
+}
+
+// spec describes the lock to the grants package.
+func spec() *grants.Spec {
+	return &grants.Spec{
+		Name: "rwlock",
+		Noun: "lock",
+		Signals: grants.Signals{
+			Acquire:  WorkflowSignalAcquire,
+			Acquired: WorkflowSignalAcquired,
+			Release:  WorkflowSignalRelease,
+			Released: WorkflowSignalReleased,
+			Cancel:   WorkflowSignalCancel,
+			Renew:    WorkflowSignalRenew,
+			Expired:  WorkflowSignalExpired,
+		},
+		Activity: AcquireRWLockActivity,
+		Timeout:  DefaultTimeout,
+	}
+}
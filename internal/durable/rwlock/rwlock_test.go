@@ -0,0 +1,196 @@
+package rwlock_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+
+	"go.breu.io/quantm/internal/durable/grants"
+	"go.breu.io/quantm/internal/durable/rwlock"
+)
+
+type (
+	RWLockTestSuite struct {
+		suite.Suite
+		testsuite.WorkflowTestSuite
+		env *testsuite.TestWorkflowEnvironment
+	}
+)
+
+func TestRWLockTestSuite(t *testing.T) {
+	suite.Run(t, new(RWLockTestSuite))
+}
+
+func (s *RWLockTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+}
+
+func (s *RWLockTestSuite) TearDownTest() {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *RWLockTestSuite) TestRWLockWorkflow_Order() {
+	// Scenario:
+	// 1. Readers R1 and R2 share the lock.
+	// 2. Writer W waits for them, and reader R3, arriving after W, waits for W.
+	// 3. R1 and R2 release, W gets the lock; W releases, R3 gets the lock.
+	resourceID := "test-resource-order"
+	r1 := handler(resourceID, "reader-1", rwlock.ModeRead)
+	r2 := handler(resourceID, "reader-2", rwlock.ModeRead)
+	w := handler(resourceID, "writer", rwlock.ModeWrite)
+	r3 := handler(resourceID, "reader-3", rwlock.ModeRead)
+
+	s.env.RegisterWorkflow(rwlock.RWLockWorkflow)
+
+	var eventLog []string
+
+	for _, h := range []*rwlock.Handler{r1, r2, w, r3} {
+		id := h.WorkflowExecutionID()
+
+		s.env.OnSignalExternalWorkflow(mock.Anything, id, "run-"+id, rwlock.WorkflowSignalAcquired.String(), mock.Anything).
+			Run(func(args mock.Arguments) {
+				eventLog = append(eventLog, fmt.Sprintf("%s-%d", id, args.Get(4)))
+			}).Return(nil)
+		s.env.OnSignalExternalWorkflow(mock.Anything, id, "run-"+id, rwlock.WorkflowSignalReleased.String(), mock.Anything).
+			Return(nil)
+	}
+
+	for i, h := range []*rwlock.Handler{r1, r2, w, r3} {
+		s.env.RegisterDelayedCallback(func() {
+			s.env.SignalWorkflow(rwlock.WorkflowSignalAcquire.String(), h)
+		}, time.Duration(i+1)*time.Second)
+	}
+
+	s.env.RegisterDelayedCallback(func() {
+		val, err := s.env.QueryWorkflow(rwlock.WorkflowQueryState.String())
+		s.Require().NoError(err)
+
+		info := &rwlock.Info{}
+		s.Require().NoError(val.Get(info))
+
+		s.Require().Len(info.Holders, 2)
+		s.Equal(rwlock.ModeRead, info.Holders[0].Mode)
+		s.Equal(rwlock.ModeRead, info.Holders[1].Mode)
+		s.Require().Len(info.Waiters, 2)
+		s.Equal("writer", info.Waiters[0].ID)
+		s.Equal(rwlock.ModeWrite, info.Waiters[0].Mode)
+		s.Equal("reader-3", info.Waiters[1].ID)
+	}, 5*time.Second)
+
+	for i, h := range []*rwlock.Handler{r1, r2, w, r3} {
+		s.env.RegisterDelayedCallback(func() {
+			released := *h
+			released.Token = uint64(i + 1)
+			s.env.SignalWorkflow(rwlock.WorkflowSignalRelease.String(), &released)
+		}, time.Duration(i+6)*time.Second)
+	}
+
+	s.env.ExecuteWorkflow(rwlock.RWLockWorkflow, rwlock.NewState(r1))
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+	s.Equal([]string{"reader-1-1", "reader-2-2", "writer-3", "reader-3-4"}, eventLog)
+}
+
+func (s *RWLockTestSuite) TestClient_OnWrite_Success() {
+	resourceID := "test-resource-client"
+	lockWorkflowID := "rwlock." + resourceID
+
+	request := ""
+
+	s.env.OnActivity(
+		rwlock.AcquireRWLockActivity,
+		mock.Anything,
+		mock.MatchedBy(func(h *rwlock.Handler) bool { return h.Mode == rwlock.ModeWrite }),
+	).
+		Run(func(args mock.Arguments) { request = args.Get(1).(*rwlock.Handler).Request }).
+		Return(&workflow.Execution{ID: lockWorkflowID, RunID: "rwlock-run-id"}, nil)
+
+	s.env.OnSignalExternalWorkflow(mock.Anything, lockWorkflowID, "rwlock-run-id", rwlock.WorkflowSignalRelease.String(), mock.Anything).
+		Return(nil)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(grants.Signal(rwlock.WorkflowSignalAcquired, request), uint64(1))
+	}, 1*time.Second)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(grants.Signal(rwlock.WorkflowSignalReleased, request), true)
+	}, 7*time.Second)
+
+	ran := false
+
+	consumer := func(ctx workflow.Context) error {
+		lock, err := rwlock.New(ctx, rwlock.WithResourceID(resourceID))
+		if err != nil {
+			return err
+		}
+
+		return lock.OnWrite(ctx, func(c workflow.Context) {
+			_ = workflow.Sleep(c, 5*time.Second)
+			ran = true
+		})
+	}
+
+	s.env.ExecuteWorkflow(consumer)
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+	s.True(ran)
+}
+
+func (s *RWLockTestSuite) TestRWLockWorkflow_ContinueAsNew() {
+	// Scenario: the server suggests to continue as new while writer W and reader R wait for the lock.
+	// Expected: W is granted, and the workflow continues as new with W holding and R waiting.
+	resourceID := "test-resource-continue"
+	w := handler(resourceID, "writer", rwlock.ModeWrite)
+	r := handler(resourceID, "reader", rwlock.ModeRead)
+
+	state := rwlock.NewState(w)
+	state.Waiters = append(state.Waiters, &rwlock.Grant{Handler: w}, &rwlock.Grant{Handler: r})
+
+	s.env.RegisterWorkflow(rwlock.RWLockWorkflow)
+	s.env.SetContinueAsNewSuggested(true)
+
+	s.env.OnSignalExternalWorkflow(mock.Anything, "writer", "run-writer", rwlock.WorkflowSignalAcquired.String(), uint64(1)).
+		Return(nil).Once()
+
+	s.env.ExecuteWorkflow(rwlock.RWLockWorkflow, state)
+
+	s.True(s.env.IsWorkflowCompleted())
+
+	err := s.env.GetWorkflowError()
+	s.Require().Error(err)
+
+	can := &workflow.ContinueAsNewError{}
+	s.Require().True(errors.As(err, &can))
+
+	next := &rwlock.State{}
+	s.Require().NoError(converter.GetDefaultDataConverter().FromPayloads(can.Input, next))
+
+	s.Equal(uint64(1), next.Token)
+	s.Require().Len(next.Holders, 1)
+	s.Equal(rwlock.ModeWrite, next.Holders[0].Handler.Mode)
+	s.Require().Len(next.Waiters, 1)
+	s.Equal("reader", next.Waiters[0].Handler.WorkflowExecutionID())
+	s.Equal(rwlock.ModeRead, next.Waiters[0].Handler.Mode)
+}
+
+func handler(resourceID, id string, mode rwlock.Mode) *rwlock.Handler {
+	return &rwlock.Handler{
+		Ticket: grants.Ticket{
+			ResourceID: resourceID,
+			Info: &workflow.Info{
+				WorkflowExecution: workflow.Execution{ID: id, RunID: "run-" + id},
+			},
+			Timeout: 10 * time.Minute,
+		},
+		Mode: mode,
+	}
+}
@@ -0,0 +1,118 @@
+// Crafted with ❤ at Breu, Inc. <info@breu.io>, Copyright © 2024.
+//
+// Functional Source License, Version 1.1, Apache 2.0 Future License
+//
+// We hereby irrevocably grant you an additional license to use the Software under the Apache License, Version 2.0 that
+// is effective on the second anniversary of the date we make the Software available. On or after that date, you may use
+// the Software under the Apache License, Version 2.0, in which case the following will apply:
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+// the License.
+//
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package rwlock
+
+import (
+	"time"
+
+	"go.breu.io/durex/queues"
+	"go.temporal.io/sdk/log"
+	"go.temporal.io/sdk/workflow"
+
+	"go.breu.io/quantm/internal/durable/grants"
+)
+
+type (
+	// Grant is a request for the lock, either holding it or waiting for it.
+	Grant = grants.Entry[*Handler]
+
+	// State encapsulates the state of the rwlock workflow. The holders are either readers, or a single writer.
+	State struct {
+		grants.Table[*Handler]
+	}
+
+	// Info is the snapshot of the lock returned by the state query.
+	Info struct {
+		Token   uint64      `json:"token"`   // Token is the token of the last grant.
+		Holders []GrantInfo `json:"holders"` // Holders are the holders, in the order they were granted.
+		Waiters []GrantInfo `json:"waiters"` // Waiters are the pending requests, in the order they will be granted.
+	}
+
+	// GrantInfo describes a holder or a waiter.
+	GrantInfo struct {
+		ID      string        `json:"id"`      // ID is the workflow ID.
+		RunID   string        `json:"run_id"`  // RunID is the workflow run ID.
+		Mode    Mode          `json:"mode"`    // Mode is the mode of the request.
+		Since   time.Time     `json:"since"`   // Since is the time the lock was granted or requested.
+		Elapsed time.Duration `json:"elapsed"` // Elapsed is the time held, or waited, so far.
+	}
+)
+
+const (
+	WorkflowQueryState queues.Signal = "query__rwlock__state"
+)
+
+/***
+ * Initialization
+ **/
+
+// NewState creates the initial state of the lock.
+func NewState(handler *Handler) *State {
+	return &State{Table: grants.NewTable[*Handler](handler.ResourceID)}
+}
+
+// restore reinitializes the logger and the lease timers.
+func (s *State) restore(ctx workflow.Context) {
+	s.Restore(ctx, spec(), log.With(workflow.GetLogger(ctx), "rwlock_id", s.ResourceID))
+}
+
+/***
+ * Query Handlers
+ **/
+
+// set_query_state sets a query handler for the rwlock workflow.
+func (s *State) set_query_state(ctx workflow.Context) error {
+	return workflow.SetQueryHandler(ctx, WorkflowQueryState.String(), func() (*Info, error) {
+		return s.info(workflow.Now(ctx)), nil
+	})
+}
+
+// info returns the snapshot of the lock at the given time.
+func (s *State) info(now time.Time) *Info {
+	describe := func(grants []*Grant) []GrantInfo {
+		infos := make([]GrantInfo, 0, len(grants))
+		for _, p := range grants {
+			infos = append(infos, GrantInfo{
+				ID:      p.Handler.WorkflowExecutionID(),
+				RunID:   p.Handler.WorkflowRunID(),
+				Mode:    p.Handler.Mode,
+				Since:   p.Since,
+				Elapsed: now.Sub(p.Since),
+			})
+		}
+
+		return infos
+	}
+
+	return &Info{Token: s.Token, Holders: describe(s.Holders), Waiters: describe(s.Waiters)}
+}
+
+/***
+ * Admission
+ **/
+
+// admit returns true if the request can be granted alongside the current holders.
+func (s *State) admit(rx *Handler) bool {
+	if len(s.Holders) == 0 {
+		return true
+	}
+
+	return rx.Mode == ModeRead && s.Holders[0].Handler.Mode == ModeRead
+}
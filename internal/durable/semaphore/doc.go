@@ -0,0 +1,41 @@
+// Crafted with ❤ at Breu, Inc. <info@breu.io>, Copyright © 2024.
+//
+// Functional Source License, Version 1.1, Apache 2.0 Future License
+//
+// We hereby irrevocably grant you an additional license to use the Software under the Apache License, Version 2.0 that
+// is effective on the second anniversary of the date we make the Software available. On or after that date, you may use
+// the Software under the Apache License, Version 2.0, in which case the following will apply:
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+// the License.
+//
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+// Package semaphore provides a distributed, durable counting semaphore for Temporal workflows.
+//
+// It follows the workflow-per-resource design of the mutex package, allowing up to Limit workflows to hold the resource
+// at the same time. Requests beyond the limit wait in arrival order.
+//
+// Usage:
+//
+//	sem, err := semaphore.New(ctx, semaphore.WithResourceID("clones-"+repoID), semaphore.WithLimit(4))
+//	if err != nil {
+//	    return err
+//	}
+//
+//	err = sem.OnAcquire(ctx, func(semCtx workflow.Context) {
+//	    // At most 4 workflows run this at the same time.
+//	    // The permit is released when this function returns.
+//	})
+//
+// The lease of a permit runs for the timeout, and is renewed while the function runs. If it expires nonetheless, e.g.
+// the holder stopped making progress, the context passed to the function is cancelled, the permit is handed to the next
+// waiter, and OnAcquire returns an error. The semaphore continues as new when the server suggests it, and shuts down
+// when idle. The machinery is shared with the rwlock package, see the grants package.
+package semaphore
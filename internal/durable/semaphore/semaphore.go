@@ -0,0 +1,159 @@
+// Crafted with ❤ at Breu, Inc. <info@breu.io>, Copyright © 2024.
+//
+// Functional Source License, Version 1.1, Apache 2.0 Future License
+//
+// We hereby irrevocably grant you an additional license to use the Software under the Apache License, Version 2.0 that
+// is effective on the second anniversary of the date we make the Software available. On or after that date, you may use
+// the Software under the Apache License, Version 2.0, in which case the following will apply:
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+// the License.
+//
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package semaphore
+
+import (
+	"errors"
+	"time"
+
+	"go.breu.io/durex/queues"
+	"go.temporal.io/sdk/log"
+	"go.temporal.io/sdk/workflow"
+
+	"go.breu.io/quantm/internal/durable/grants"
+)
+
+const (
+	DefaultTimeout = 10 * time.Minute      // DefaultTimeout is the default lease timeout of a permit.
+	DefaultLimit   = 1                     // DefaultLimit is the default number of concurrent holders.
+	SignalTimeout  = grants.SignalTimeout  // SignalTimeout is the timeout for signal handshakes.
+	MaxAcquireWait = grants.MaxAcquireWait // MaxAcquireWait is the maximum time to wait for a permit.
+	IdleTimeout    = grants.IdleTimeout    // IdleTimeout shuts the semaphore down once nobody holds or waits for it.
+	LongTimeout    = grants.LongTimeout    // Effectively infinite for "pausing" the idle timer
+)
+
+const (
+	WorkflowSignalAcquire  queues.Signal = "semaphore__acquire"
+	WorkflowSignalAcquired queues.Signal = "semaphore__acquired"
+	WorkflowSignalRelease  queues.Signal = "semaphore__release"
+	WorkflowSignalReleased queues.Signal = "semaphore__released"
+	WorkflowSignalCancel   queues.Signal = "semaphore__cancel"
+	WorkflowSignalRenew    queues.Signal = "semaphore__renew"
+	WorkflowSignalExpired  queues.Signal = "semaphore__expired"
+)
+
+var (
+	ErrNilContext   = errors.New("contexts not initialized")
+	ErrNoResourceID = errors.New("no resource ID provided")
+	ErrInvalidLimit = errors.New("limit must be at least 1")
+)
+
+type (
+	Option func(*Handler)
+
+	// Semaphore defines the signature for the workflow semaphore.
+	Semaphore interface {
+		// OnAcquire blocks until a permit is acquired, executes fn, and then releases the permit.
+		// It returns an error if the permit cannot be acquired, the context is cancelled, or the permit expires while fn
+		// runs. The context passed to fn is cancelled when the permit expires.
+		OnAcquire(ctx workflow.Context, fn func(workflow.Context)) error
+	}
+
+	// Handler is the Semaphore handler.
+	Handler struct {
+		grants.Ticket
+
+		Limit  int `json:"limit"` // Limit is the number of concurrent holders.
+		logger log.Logger
+	}
+)
+
+// WithResourceID sets the resource ID for the semaphore workflow.
+func WithResourceID(id string) Option {
+	return func(h *Handler) {
+		h.ResourceID = id
+	}
+}
+
+// WithLimit sets the number of concurrent holders. The limit of the first request starts the semaphore, later requests
+// share it.
+func WithLimit(limit int) Option {
+	return func(h *Handler) {
+		h.Limit = limit
+	}
+}
+
+// WithTimeout sets the lease timeout of the permit.
+func WithTimeout(timeout time.Duration) Option {
+	return func(h *Handler) {
+		h.Timeout = timeout
+	}
+}
+
+// New returns a new Semaphore.
+func New(ctx workflow.Context, opts ...Option) (Semaphore, error) {
+	h := &Handler{Ticket: grants.Ticket{Timeout: DefaultTimeout}, Limit: DefaultLimit}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	h.Info = workflow.GetInfo(ctx)
+	h.logger = log.With(workflow.GetLogger(ctx), "semaphore_id", h.ResourceID, "handler_id", h.WorkflowExecutionID())
+
+	if err := h.validate(); err != nil {
+		h.logger.Error("semaphore: validate error", "error", err)
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// OnAcquire blocks until a permit is acquired (or timeout), executes the closure, and releases the permit. If the
+// context is cancelled while waiting, the pending request is withdrawn and the context error is returned. The permit is
+// renewed while the closure runs.
+func (h *Handler) OnAcquire(ctx workflow.Context, fn func(workflow.Context)) error {
+	return grants.Hold(ctx, spec(), h, h.logger, fn)
+}
+
+// validate checks if the semaphore is properly configured.
+func (h *Handler) validate() error {
+	if h.ResourceID == "" {
+		return ErrNoResourceID
+	}
+
+	if h.Limit < 1 {
+		return ErrInvalidLimit
+	}
+
+	if h.Info == nil {
+		return ErrNilContext
+	}
+
+	return nil
+}
+
+// spec describes the semaphore to the grants package.
+func spec() *grants.Spec {
+	return &grants.Spec{
+		Name: "semaphore",
+		Noun: "permit",
+		Signals: grants.Signals{
+			Acquire:  WorkflowSignalAcquire,
+			Acquired: WorkflowSignalAcquired,
+			Release:  WorkflowSignalRelease,
+			Released: WorkflowSignalReleased,
+			Cancel:   WorkflowSignalCancel,
+			Renew:    WorkflowSignalRenew,
+			Expired:  WorkflowSignalExpired,
+		},
+		Activity: AcquireSemaphoreActivity,
+		Timeout:  DefaultTimeout,
+	}
+}
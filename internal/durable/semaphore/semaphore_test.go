@@ -0,0 +1,343 @@
+package semaphore_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.breu.io/durex/queues"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+
+	"go.breu.io/quantm/internal/durable/grants"
+	"go.breu.io/quantm/internal/durable/semaphore"
+)
+
+type (
+	SemaphoreTestSuite struct {
+		suite.Suite
+		testsuite.WorkflowTestSuite
+		env *testsuite.TestWorkflowEnvironment
+	}
+)
+
+func TestSemaphoreTestSuite(t *testing.T) {
+	suite.Run(t, new(SemaphoreTestSuite))
+}
+
+func (s *SemaphoreTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+}
+
+func (s *SemaphoreTestSuite) TearDownTest() {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *SemaphoreTestSuite) TestSemaphoreWorkflow_Limit() {
+	// Scenario:
+	// 1. A, B and C acquire a semaphore with a limit of 2; A and B hold, C waits.
+	// 2. A releases, C gets the permit.
+	// 3. B's lease expires, B is told.
+	// 4. C releases, the semaphore shuts down when idle.
+	resourceID := "test-resource-limit"
+	handlerA := handler(resourceID, "client-A", 10*time.Minute)
+	handlerB := handler(resourceID, "client-B", time.Minute)
+	handlerC := handler(resourceID, "client-C", 10*time.Minute)
+
+	state := semaphore.NewState(handlerA)
+
+	s.env.RegisterWorkflow(semaphore.SemaphoreWorkflow)
+
+	var eventLog []string
+
+	for _, id := range []string{"client-A", "client-B", "client-C"} {
+		for _, signal := range []string{semaphore.WorkflowSignalAcquired.String(), semaphore.WorkflowSignalExpired.String()} {
+			s.env.OnSignalExternalWorkflow(mock.Anything, id, "run-"+id, signal, mock.Anything).
+				Run(func(args mock.Arguments) {
+					eventLog = append(eventLog, fmt.Sprintf("%s-%s-%d", id, signal, args.Get(4)))
+				}).Return(nil).Maybe()
+		}
+
+		s.env.OnSignalExternalWorkflow(mock.Anything, id, "run-"+id, semaphore.WorkflowSignalReleased.String(), mock.Anything).
+			Return(nil).Maybe()
+	}
+
+	for i, h := range []*semaphore.Handler{handlerA, handlerB, handlerC} {
+		s.env.RegisterDelayedCallback(func() {
+			s.env.SignalWorkflow(semaphore.WorkflowSignalAcquire.String(), h)
+		}, time.Duration(i+1)*time.Second)
+	}
+
+	s.env.RegisterDelayedCallback(func() {
+		val, err := s.env.QueryWorkflow(semaphore.WorkflowQueryState.String())
+		s.Require().NoError(err)
+
+		info := &semaphore.Info{}
+		s.Require().NoError(val.Get(info))
+
+		s.Equal(2, info.Limit)
+		s.Require().Len(info.Holders, 2)
+		s.Equal("client-A", info.Holders[0].ID)
+		s.Equal("client-B", info.Holders[1].ID)
+		s.Require().Len(info.Waiters, 1)
+		s.Equal("client-C", info.Waiters[0].ID)
+		s.Equal(time.Second, info.Waiters[0].Elapsed)
+	}, 4*time.Second)
+
+	s.env.RegisterDelayedCallback(func() {
+		released := *handlerA
+		released.Token = 1
+		s.env.SignalWorkflow(semaphore.WorkflowSignalRelease.String(), &released)
+	}, 5*time.Second)
+
+	s.env.RegisterDelayedCallback(func() {
+		released := *handlerC
+		released.Token = 3
+		s.env.SignalWorkflow(semaphore.WorkflowSignalRelease.String(), &released)
+	}, 2*time.Minute)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.False(s.env.IsWorkflowCompleted(), "Workflow should be running before idle timeout")
+	}, 2*time.Minute+semaphore.IdleTimeout-time.Second)
+
+	s.env.ExecuteWorkflow(semaphore.SemaphoreWorkflow, state)
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+	s.Equal([]string{
+		"client-A-semaphore__acquired-1",
+		"client-B-semaphore__acquired-2",
+		"client-C-semaphore__acquired-3",
+		"client-B-semaphore__expired-2",
+	}, eventLog)
+}
+
+func (s *SemaphoreTestSuite) TestClient_OnAcquire_Success() {
+	resourceID := "test-resource-client"
+	semaphoreWorkflowID := "semaphore." + resourceID
+
+	request := ""
+
+	s.env.OnActivity(semaphore.AcquireSemaphoreActivity, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { request = args.Get(1).(*semaphore.Handler).Request }).
+		Return(&workflow.Execution{ID: semaphoreWorkflowID, RunID: "semaphore-run-id"}, nil)
+
+	s.env.
+		OnSignalExternalWorkflow(mock.Anything, semaphoreWorkflowID, "semaphore-run-id", semaphore.WorkflowSignalRelease.String(), mock.Anything).
+		Return(nil)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(grants.Signal(semaphore.WorkflowSignalAcquired, request), uint64(1))
+	}, 1*time.Second)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(grants.Signal(semaphore.WorkflowSignalReleased, request), true)
+	}, 7*time.Second)
+
+	ran := false
+
+	consumer := func(ctx workflow.Context) error {
+		sem, err := semaphore.New(ctx, semaphore.WithResourceID(resourceID), semaphore.WithLimit(2))
+		if err != nil {
+			return err
+		}
+
+		return sem.OnAcquire(ctx, func(c workflow.Context) {
+			_ = workflow.Sleep(c, 5*time.Second)
+			ran = true
+		})
+	}
+
+	s.env.ExecuteWorkflow(consumer)
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+	s.True(ran)
+}
+
+func (s *SemaphoreTestSuite) TestSemaphoreWorkflow_Renew() {
+	// Scenario:
+	// 1. A holds the only permit with a one minute lease, and renews it at 40s and 80s. B waits.
+	// 2. A stops renewing, the lease expires a minute after the last renewal, and B gets the permit.
+	resourceID := "test-resource-renew"
+	handlerA := handler(resourceID, "client-A", time.Minute)
+	handlerA.Limit = 1
+	handlerB := handler(resourceID, "client-B", time.Minute)
+
+	s.env.RegisterWorkflow(semaphore.SemaphoreWorkflow)
+
+	var eventLog []string
+
+	for _, id := range []string{"client-A", "client-B"} {
+		for _, signal := range []string{semaphore.WorkflowSignalAcquired.String(), semaphore.WorkflowSignalExpired.String()} {
+			s.env.OnSignalExternalWorkflow(mock.Anything, id, "run-"+id, signal, mock.Anything).
+				Run(func(args mock.Arguments) {
+					eventLog = append(eventLog, fmt.Sprintf("%s-%s-%d", id, signal, args.Get(4)))
+				}).Return(nil).Maybe()
+		}
+	}
+
+	s.env.OnSignalExternalWorkflow(mock.Anything, "client-B", "run-client-B", semaphore.WorkflowSignalReleased.String(), mock.Anything).
+		Return(nil)
+
+	for i, h := range []*semaphore.Handler{handlerA, handlerB} {
+		s.env.RegisterDelayedCallback(func() {
+			s.env.SignalWorkflow(semaphore.WorkflowSignalAcquire.String(), h)
+		}, time.Duration(i+1)*time.Second)
+	}
+
+	for _, at := range []time.Duration{40 * time.Second, 80 * time.Second} {
+		s.env.RegisterDelayedCallback(func() {
+			renewal := *handlerA
+			renewal.Token = 1
+			s.env.SignalWorkflow(semaphore.WorkflowSignalRenew.String(), &renewal)
+		}, at)
+	}
+
+	s.env.RegisterDelayedCallback(func() {
+		val, err := s.env.QueryWorkflow(semaphore.WorkflowQueryState.String())
+		s.Require().NoError(err)
+
+		info := &semaphore.Info{}
+		s.Require().NoError(val.Get(info))
+
+		s.Require().Len(info.Holders, 1)
+		s.Equal("client-A", info.Holders[0].ID, "renewed lease should outlive the original one")
+	}, 130*time.Second)
+
+	s.env.RegisterDelayedCallback(func() {
+		released := *handlerB
+		released.Token = 2
+		s.env.SignalWorkflow(semaphore.WorkflowSignalRelease.String(), &released)
+	}, 3*time.Minute)
+
+	s.env.ExecuteWorkflow(semaphore.SemaphoreWorkflow, semaphore.NewState(handlerA))
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+	s.Equal([]string{
+		"client-A-semaphore__acquired-1",
+		"client-A-semaphore__expired-1",
+		"client-B-semaphore__acquired-2",
+	}, eventLog)
+}
+
+func (s *SemaphoreTestSuite) TestSemaphoreWorkflow_ContinueAsNew() {
+	// Scenario: the server suggests to continue as new while A and B wait for the only permit.
+	// Expected: A is granted, and the workflow continues as new with A holding and B waiting.
+	resourceID := "test-resource-continue"
+	handlerA := handler(resourceID, "client-A", time.Minute)
+	handlerA.Limit = 1
+	handlerB := handler(resourceID, "client-B", time.Minute)
+
+	state := semaphore.NewState(handlerA)
+	state.Waiters = append(state.Waiters, &semaphore.Permit{Handler: handlerA}, &semaphore.Permit{Handler: handlerB})
+
+	s.env.RegisterWorkflow(semaphore.SemaphoreWorkflow)
+	s.env.SetContinueAsNewSuggested(true)
+
+	s.env.OnSignalExternalWorkflow(mock.Anything, "client-A", "run-client-A", semaphore.WorkflowSignalAcquired.String(), uint64(1)).
+		Return(nil).Once()
+
+	s.env.ExecuteWorkflow(semaphore.SemaphoreWorkflow, state)
+
+	s.True(s.env.IsWorkflowCompleted())
+
+	err := s.env.GetWorkflowError()
+	s.Require().Error(err)
+
+	can := &workflow.ContinueAsNewError{}
+	s.Require().True(errors.As(err, &can))
+
+	next := &semaphore.State{}
+	s.Require().NoError(converter.GetDefaultDataConverter().FromPayloads(can.Input, next))
+
+	s.Equal(uint64(1), next.Token)
+	s.Require().Len(next.Holders, 1)
+	s.Equal("client-A", next.Holders[0].Handler.WorkflowExecutionID())
+	s.Equal(next.Holders[0].Since.Add(time.Minute), next.Holders[0].Expiry)
+	s.Require().Len(next.Waiters, 1)
+	s.Equal("client-B", next.Waiters[0].Handler.WorkflowExecutionID())
+}
+
+func (s *SemaphoreTestSuite) TestClient_OnAcquire_RenewAndStaleGrant() {
+	// Scenario:
+	// 1. The first request times out and is withdrawn, but the semaphore granted it meanwhile.
+	// 2. The grant of the first request arrives while the second request waits.
+	// Expected: the stale grant is ignored, the second request is granted, and its lease is renewed every 20 seconds
+	// while the closure runs for 110 seconds.
+	resourceID := "test-resource-stale"
+	semaphoreWorkflowID := "semaphore." + resourceID
+
+	requests := make([]string, 0)
+
+	s.env.OnActivity(semaphore.AcquireSemaphoreActivity, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { requests = append(requests, args.Get(1).(*semaphore.Handler).Request) }).
+		Return(&workflow.Execution{ID: semaphoreWorkflowID, RunID: "semaphore-run-id"}, nil)
+
+	s.env.
+		OnSignalExternalWorkflow(mock.Anything, semaphoreWorkflowID, "semaphore-run-id", semaphore.WorkflowSignalCancel.String(), mock.Anything).
+		Return(nil).Once()
+
+	// the renewals and the release carry the token of the second grant.
+	granted := mock.MatchedBy(func(h *semaphore.Handler) bool { return h.Token == 2 })
+
+	for signal, times := range map[queues.Signal]int{semaphore.WorkflowSignalRenew: 5, semaphore.WorkflowSignalRelease: 1} {
+		s.env.OnSignalExternalWorkflow(mock.Anything, semaphoreWorkflowID, "semaphore-run-id", signal.String(), granted).
+			Return(nil).Times(times)
+	}
+
+	s.env.RegisterDelayedCallback(func() {
+		s.Require().Len(requests, 2)
+		s.env.SignalWorkflow(grants.Signal(semaphore.WorkflowSignalAcquired, requests[0]), uint64(1))
+	}, semaphore.MaxAcquireWait+1*time.Second)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(grants.Signal(semaphore.WorkflowSignalAcquired, requests[1]), uint64(2))
+	}, semaphore.MaxAcquireWait+2*time.Second)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(grants.Signal(semaphore.WorkflowSignalReleased, requests[1]), true)
+	}, semaphore.MaxAcquireWait+2*time.Minute)
+
+	var first error
+
+	ran := false
+
+	consumer := func(ctx workflow.Context) error {
+		sem, err := semaphore.New(ctx, semaphore.WithResourceID(resourceID), semaphore.WithTimeout(time.Minute))
+		if err != nil {
+			return err
+		}
+
+		first = sem.OnAcquire(ctx, func(_ workflow.Context) {})
+
+		return sem.OnAcquire(ctx, func(c workflow.Context) {
+			ran = workflow.Sleep(c, 110*time.Second) == nil
+		})
+	}
+
+	s.env.ExecuteWorkflow(consumer)
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+	s.Error(first, "the first request should time out")
+	s.True(ran, "the stale grant should be ignored, and the lease kept")
+}
+
+func handler(resourceID, id string, timeout time.Duration) *semaphore.Handler {
+	return &semaphore.Handler{
+		Ticket: grants.Ticket{
+			ResourceID: resourceID,
+			Info: &workflow.Info{
+				WorkflowExecution: workflow.Execution{ID: id, RunID: "run-" + id},
+			},
+			Timeout: timeout,
+		},
+		Limit: 2,
+	}
+}
@@ -0,0 +1,115 @@
+// Crafted with ❤ at Breu, Inc. <info@breu.io>, Copyright © 2024.
+//
+// Functional Source License, Version 1.1, Apache 2.0 Future License
+//
+// We hereby irrevocably grant you an additional license to use the Software under the Apache License, Version 2.0 that
+// is effective on the second anniversary of the date we make the Software available. On or after that date, you may use
+// the Software under the Apache License, Version 2.0, in which case the following will apply:
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+// the License.
+//
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package semaphore
+
+import (
+	"time"
+
+	"go.breu.io/durex/queues"
+	"go.temporal.io/sdk/log"
+	"go.temporal.io/sdk/workflow"
+
+	"go.breu.io/quantm/internal/durable/grants"
+)
+
+type (
+	// Permit is a request for the semaphore, either holding it or waiting for it.
+	Permit = grants.Entry[*Handler]
+
+	// State encapsulates the state of the semaphore workflow.
+	State struct {
+		grants.Table[*Handler]
+
+		Limit int `json:"limit"`
+	}
+
+	// Info is the snapshot of the semaphore returned by the state query.
+	Info struct {
+		Limit   int          `json:"limit"`
+		Token   uint64       `json:"token"`   // Token is the token of the last grant.
+		Holders []PermitInfo `json:"holders"` // Holders are the holders, in the order they were granted.
+		Waiters []PermitInfo `json:"waiters"` // Waiters are the pending requests, in the order they will be granted.
+	}
+
+	// PermitInfo describes a holder or a waiter.
+	PermitInfo struct {
+		ID      string        `json:"id"`      // ID is the workflow ID.
+		RunID   string        `json:"run_id"`  // RunID is the workflow run ID.
+		Since   time.Time     `json:"since"`   // Since is the time the permit was granted or requested.
+		Elapsed time.Duration `json:"elapsed"` // Elapsed is the time held, or waited, so far.
+	}
+)
+
+const (
+	WorkflowQueryState queues.Signal = "query__semaphore__state"
+)
+
+/***
+ * Initialization
+ **/
+
+// NewState creates the initial state of the semaphore, with the limit of the first request.
+func NewState(handler *Handler) *State {
+	return &State{Table: grants.NewTable[*Handler](handler.ResourceID), Limit: handler.Limit}
+}
+
+// restore reinitializes the logger and the lease timers.
+func (s *State) restore(ctx workflow.Context) {
+	s.Restore(ctx, spec(), log.With(workflow.GetLogger(ctx), "semaphore_id", s.ResourceID))
+}
+
+/***
+ * Query Handlers
+ **/
+
+// set_query_state sets a query handler for the semaphore workflow.
+func (s *State) set_query_state(ctx workflow.Context) error {
+	return workflow.SetQueryHandler(ctx, WorkflowQueryState.String(), func() (*Info, error) {
+		return s.info(workflow.Now(ctx)), nil
+	})
+}
+
+// info returns the snapshot of the semaphore at the given time.
+func (s *State) info(now time.Time) *Info {
+	describe := func(permits []*Permit) []PermitInfo {
+		infos := make([]PermitInfo, 0, len(permits))
+		for _, p := range permits {
+			infos = append(infos, PermitInfo{
+				ID:      p.Handler.WorkflowExecutionID(),
+				RunID:   p.Handler.WorkflowRunID(),
+				Since:   p.Since,
+				Elapsed: now.Sub(p.Since),
+			})
+		}
+
+		return infos
+	}
+
+	return &Info{Limit: s.Limit, Token: s.Token, Holders: describe(s.Holders), Waiters: describe(s.Waiters)}
+}
+
+/***
+ * Admission
+ **/
+
+// admit returns true while there are fewer holders than the limit.
+func (s *State) admit(_ *Handler) bool {
+	return len(s.Holders) < s.Limit
+}
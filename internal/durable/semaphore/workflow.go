@@ -0,0 +1,46 @@
+// Crafted with ❤ at Breu, Inc. <info@breu.io>, Copyright © 2024.
+//
+// Functional Source License, Version 1.1, Apache 2.0 Future License
+//
+// We hereby irrevocably grant you an additional license to use the Software under the Apache License, Version 2.0 that
+// is effective on the second anniversary of the date we make the Software available. On or after that date, you may use
+// the Software under the Apache License, Version 2.0, in which case the following will apply:
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+// the License.
+//
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package semaphore
+
+import (
+	"go.temporal.io/sdk/workflow"
+)
+
+// SemaphoreWorkflow is the semaphore workflow. It controls concurrent access to a resource.
+//
+// It operates as a serialized state machine:
+// 1. Grant permits to the waiters, in arrival order, while there are fewer holders than the limit.
+// 2. Wait for Acquire, Release, Cancel, Renew or a lease expiry (or Idle Timeout when there are neither holders nor
+// waiters).
+// 3. Repeat.
+//
+// Once the server suggests it, the workflow continues as new with its holders and waiters.
+func SemaphoreWorkflow(ctx workflow.Context, state *State) error {
+	state.restore(ctx)
+
+	// Setup Query Handler
+	_ = state.set_query_state(ctx)
+
+	if state.Run(ctx, state.admit) {
+		return workflow.NewContinueAsNewError(ctx, SemaphoreWorkflow, state)
+	}
+
+	return nil
+}
@@ -10,4 +10,5 @@ const (
 	HooksModule       int = 400
 	HooksGithubModule int = 401
 	HooksSlackModule  int = 402
+	InsightsModule    int = 500
 )
@@ -19,6 +19,11 @@ const (
 	EventActionAdded   Action = "added"     // EventActionAdded indicates something was added to something else.
 	EventActionRemoved Action = "removed"   // EventActionRemoved indicates something was removed from something else.
 	ActionRequested    Action = "requested" // ActionRequested indicates a request for an action, approval, or resource was initiated.
+	ActionEnqueued     Action = "enqueued"  // ActionEnqueued indicates an item was added to the back of a queue.
+	ActionPromoted     Action = "promoted"  // ActionPromoted indicates an item was added to the front of a queue.
+	ActionTesting      Action = "testing"   // ActionTesting indicates an item left the queue and is being tested.
+	ActionEvicted      Action = "evicted"   // ActionEvicted indicates an item was removed from a queue without completing.
+	ActionMerged       Action = "merged"    // ActionMerged indicates an item was merged.
 )
 
 // String returns the string representation of the EventAction.
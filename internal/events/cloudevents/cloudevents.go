@@ -0,0 +1,244 @@
+// Package cloudevents converts quantm events to CloudEvents 1.0.
+//
+// The core attributes are derived from the event, i.e. the type from the scope and the action, the source from the
+// source of the context, and the subject from the subject name and ID. The rest of the context and the subject is
+// carried in extension attributes prefixed with "quantm", so that no part of the event is lost.
+//
+// See https://github.com/cloudevents/spec/blob/v1.0.2/cloudevents/spec.md
+package cloudevents
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"go.breu.io/quantm/internal/events"
+)
+
+type (
+	// Event is a CloudEvents 1.0 event with a json payload.
+	Event struct {
+		SpecVersion     string            `json:"specversion"`
+		ID              string            `json:"id"`
+		Source          string            `json:"source"`
+		Type            string            `json:"type"`
+		Subject         string            `json:"subject,omitempty"`
+		Time            time.Time         `json:"time"`
+		DataContentType string            `json:"datacontenttype"`
+		Extensions      map[string]string `json:"-"`
+		Data            json.RawMessage   `json:"data,omitempty"`
+	}
+)
+
+const (
+	SpecVersion = "1.0"            // SpecVersion is the version of the CloudEvents specification.
+	TypePrefix  = "io.breu.quantm" // TypePrefix is the prefix of the type of every quantm event.
+	SourceNone  = "/quantm"        // SourceNone is the source of the events without one.
+
+	ContentTypeJSON       = "application/json"             // ContentTypeJSON is the content type of the data.
+	ContentTypeStructured = "application/cloudevents+json" // ContentTypeStructured is the content type of structured mode.
+
+	header_prefix = "ce-"
+)
+
+const (
+	ExtensionVersion     = "quantmversion"     // ExtensionVersion is the version of the quantm event.
+	ExtensionHook        = "quantmhook"        // ExtensionHook is the hook of the context.
+	ExtensionScope       = "quantmscope"       // ExtensionScope is the scope of the context.
+	ExtensionAction      = "quantmaction"      // ExtensionAction is the action of the context.
+	ExtensionParents     = "quantmparents"     // ExtensionParents is the comma separated parents of the context.
+	ExtensionSubjectName = "quantmsubjectname" // ExtensionSubjectName is the name of the subject.
+	ExtensionSubjectID   = "quantmsubjectid"   // ExtensionSubjectID is the ID of the subject.
+	ExtensionOrgID       = "quantmorgid"       // ExtensionOrgID is the org of the subject.
+	ExtensionTeamID      = "quantmteamid"      // ExtensionTeamID is the team of the subject.
+	ExtensionUserID      = "quantmuserid"      // ExtensionUserID is the user of the subject.
+)
+
+// From converts the quantm event to a CloudEvent.
+func From[H events.Hook, P events.Payload](event *events.Event[H, P]) *Event {
+	return FromFlat(*event.Flatten())
+}
+
+// FromFlat converts the flat quantm event to a CloudEvent.
+func FromFlat[H events.Hook](flat events.Flat[H]) *Event {
+	source := flat.Source
+	if source == "" {
+		source = SourceNone
+	}
+
+	parents := make([]string, 0, len(flat.Parents))
+	for _, parent := range flat.Parents {
+		parents = append(parents, parent.String())
+	}
+
+	data := json.RawMessage(flat.Payload)
+	if !json.Valid(data) {
+		data = json.RawMessage("{}")
+	}
+
+	return &Event{
+		SpecVersion:     SpecVersion,
+		ID:              flat.ID.String(),
+		Source:          source,
+		Type:            fmt.Sprintf("%s.%s.%s", TypePrefix, flat.Scope, flat.Action),
+		Subject:         fmt.Sprintf("%s/%s", flat.SubjectName, flat.SubjectID),
+		Time:            flat.Timestamp.UTC(),
+		DataContentType: ContentTypeJSON,
+		Extensions: map[string]string{
+			ExtensionVersion:     flat.Version.String(),
+			ExtensionHook:        hook(flat.Hook),
+			ExtensionScope:       flat.Scope.String(),
+			ExtensionAction:      flat.Action.String(),
+			ExtensionParents:     strings.Join(parents, ","),
+			ExtensionSubjectName: flat.SubjectName,
+			ExtensionSubjectID:   flat.SubjectID.String(),
+			ExtensionOrgID:       flat.OrgID.String(),
+			ExtensionTeamID:      id(flat.TeamID),
+			ExtensionUserID:      id(flat.UserID),
+		},
+		Data: data,
+	}
+}
+
+// MarshalJSON encodes the event in the structured json format, with the extensions as top level attributes.
+func (e *Event) MarshalJSON() ([]byte, error) {
+	attrs := e.attributes()
+	out := make(map[string]any, len(attrs)+1)
+
+	for key, value := range attrs {
+		out[key] = value
+	}
+
+	if len(e.Data) > 0 {
+		out["data"] = e.Data
+	}
+
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes the event from the structured json format. Every attribute other than the core attributes is
+// kept as an extension.
+func (e *Event) UnmarshalJSON(data []byte) error {
+	in := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	*e = Event{Extensions: make(map[string]string)}
+
+	for key, raw := range in {
+		if key == "data" {
+			e.Data = raw
+			continue
+		}
+
+		value := ""
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return fmt.Errorf("cloudevents: attribute %s: %w", key, err)
+		}
+
+		switch key {
+		case "specversion":
+			e.SpecVersion = value
+		case "id":
+			e.ID = value
+		case "source":
+			e.Source = value
+		case "type":
+			e.Type = value
+		case "subject":
+			e.Subject = value
+		case "datacontenttype":
+			e.DataContentType = value
+		case "time":
+			ts, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return fmt.Errorf("cloudevents: attribute time: %w", err)
+			}
+
+			e.Time = ts
+		default:
+			e.Extensions[key] = value
+		}
+	}
+
+	return nil
+}
+
+// Structured returns the headers and the body of the event in the structured http mode.
+func (e *Event) Structured() (http.Header, []byte, error) {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", ContentTypeStructured)
+
+	return header, body, nil
+}
+
+// Binary returns the headers and the body of the event in the binary http mode, where the attributes are headers and
+// the body is the data.
+func (e *Event) Binary() (http.Header, []byte) {
+	header := http.Header{}
+
+	for key, value := range e.attributes() {
+		if key == "datacontenttype" {
+			continue
+		}
+
+		header.Set(header_prefix+key, value)
+	}
+
+	header.Set("Content-Type", e.DataContentType)
+
+	return header, e.Data
+}
+
+// attributes returns the context attributes of the event, including the extensions. Empty attributes are omitted.
+func (e *Event) attributes() map[string]string {
+	attrs := map[string]string{
+		"specversion":     e.SpecVersion,
+		"id":              e.ID,
+		"source":          e.Source,
+		"type":            e.Type,
+		"subject":         e.Subject,
+		"time":            e.Time.Format(time.RFC3339Nano),
+		"datacontenttype": e.DataContentType,
+	}
+
+	for key, value := range e.Extensions {
+		attrs[key] = value
+	}
+
+	for key, value := range attrs {
+		if value == "" {
+			delete(attrs, key)
+		}
+	}
+
+	return attrs
+}
+
+// hook returns the name of the hook.
+func hook[H events.Hook](h H) string {
+	if s, ok := any(h).(fmt.Stringer); ok {
+		return s.String()
+	}
+
+	return fmt.Sprint(int32(h))
+}
+
+// id returns the string representation of the id, or empty for the null uuid.
+func id(v uuid.UUID) string {
+	if v == uuid.Nil {
+		return ""
+	}
+
+	return v.String()
+}
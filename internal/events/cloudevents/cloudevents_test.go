@@ -0,0 +1,93 @@
+package cloudevents_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.breu.io/quantm/internal/events"
+	"go.breu.io/quantm/internal/events/cloudevents"
+	eventsv1 "go.breu.io/quantm/internal/proto/ctrlplane/events/v1"
+)
+
+func flat() events.Flat[eventsv1.RepoHook] {
+	return events.Flat[eventsv1.RepoHook]{
+		Version:     events.Version_0_1_0,
+		ID:          uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+		Parents:     []uuid.UUID{uuid.MustParse("00000000-0000-0000-0000-000000000002")},
+		Hook:        eventsv1.RepoHook_REPO_HOOK_GITHUB,
+		Scope:       events.ScopePush,
+		Action:      events.ActionCreated,
+		SubjectID:   uuid.MustParse("00000000-0000-0000-0000-000000000003"),
+		SubjectName: "repos",
+		OrgID:       uuid.MustParse("00000000-0000-0000-0000-000000000004"),
+		Timestamp:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Payload:     `{"ref":"refs/heads/main"}`,
+	}
+}
+
+func TestFromFlat(t *testing.T) {
+	t.Parallel()
+
+	event := cloudevents.FromFlat(flat())
+
+	assert.Equal(t, "1.0", event.SpecVersion)
+	assert.Equal(t, "io.breu.quantm.push.created", event.Type)
+	assert.Equal(t, cloudevents.SourceNone, event.Source)
+	assert.Equal(t, "repos/00000000-0000-0000-0000-000000000003", event.Subject)
+	assert.Equal(t, "REPO_HOOK_GITHUB", event.Extensions[cloudevents.ExtensionHook])
+	assert.Equal(t, "00000000-0000-0000-0000-000000000002", event.Extensions[cloudevents.ExtensionParents])
+	assert.Empty(t, event.Extensions[cloudevents.ExtensionTeamID])
+}
+
+func TestStructured(t *testing.T) {
+	t.Parallel()
+
+	header, body, err := cloudevents.FromFlat(flat()).Structured()
+	require.NoError(t, err)
+
+	out := make(map[string]any)
+	require.NoError(t, json.Unmarshal(body, &out))
+
+	assert.Equal(t, cloudevents.ContentTypeStructured, header.Get("Content-Type"))
+	assert.Equal(t, "1.0", out["specversion"])
+	assert.Equal(t, "2024-01-01T00:00:00Z", out["time"])
+	assert.Equal(t, "push", out["quantmscope"])
+	assert.Equal(t, map[string]any{"ref": "refs/heads/main"}, out["data"])
+	assert.NotContains(t, out, "quantmteamid")
+}
+
+func TestBinary(t *testing.T) {
+	t.Parallel()
+
+	header, body := cloudevents.FromFlat(flat()).Binary()
+
+	assert.Equal(t, cloudevents.ContentTypeJSON, header.Get("Content-Type"))
+	assert.Equal(t, "1.0", header.Get("ce-specversion"))
+	assert.Equal(t, "00000000-0000-0000-0000-000000000001", header.Get("ce-id"))
+	assert.Equal(t, "created", header.Get("ce-quantmaction"))
+	assert.Empty(t, header.Get("ce-datacontenttype"))
+	assert.JSONEq(t, `{"ref":"refs/heads/main"}`, string(body))
+}
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	event := cloudevents.FromFlat(flat())
+
+	data, err := json.Marshal(event)
+	require.NoError(t, err)
+
+	decoded := &cloudevents.Event{}
+	require.NoError(t, json.Unmarshal(data, decoded))
+
+	assert.Equal(t, event.ID, decoded.ID)
+	assert.Equal(t, event.Type, decoded.Type)
+	assert.True(t, event.Time.Equal(decoded.Time))
+	assert.Equal(t, event.Extensions[cloudevents.ExtensionSubjectID], decoded.Extensions[cloudevents.ExtensionSubjectID])
+	assert.JSONEq(t, string(event.Data), string(decoded.Data))
+}
@@ -1,9 +1,12 @@
 package events
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 )
 
 type (
@@ -16,8 +19,22 @@ type (
 		Subject   Subject      `json:"subject"`   // Subject is the subject of the event.
 		Payload   *P           `json:"payload"`   // Payload is the payload of the event.
 	}
+
+	// plain is the event without its methods, to decode it without recursing into UnmarshalJSON.
+	plain[H Hook, P Payload] Event[H, P]
 )
 
+// UnmarshalJSON decodes the event, upcasting it to the current version first. The workflows receive the events as json,
+// so the events signaled before a payload change are decoded to the current shape.
+func (e *Event[H, P]) UnmarshalJSON(data []byte) error {
+	data, err := UpcastJSON(data)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, (*plain[H, P])(e))
+}
+
 // SetParents sets the adds the given id to parents.
 func (e *Event[H, P]) SetParents(id ...uuid.UUID) *Event[H, P] {
 	e.Context.Parents = append(e.Context.Parents, id...)
@@ -110,9 +127,26 @@ func (e *Event[H, P]) Flatten() *Flat[H] {
 		OrgID:       e.Subject.OrgID,
 		TeamID:      e.Subject.TeamID,
 		UserID:      e.Subject.UserID,
+		Payload:     e.MarshalPayload(),
 	}
 }
 
+// MarshalPayload returns the protojson encoding of the payload. Returns an empty object if the payload is not set or
+// cannot be encoded.
+func (e *Event[H, P]) MarshalPayload() string {
+	msg, ok := any(e.Payload).(proto.Message)
+	if !ok || e.Payload == nil {
+		return "{}"
+	}
+
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(msg)
+	if err != nil {
+		return "{}"
+	}
+
+	return string(data)
+}
+
 // Next creates a new event based on the provided event, scope, and action.
 func Next[H Hook, F Payload, T Payload](event *Event[H, F], scope Scope, action Action) *Event[H, T] {
 	return NextWithHook[H, H, F, T](event, event.Context.Hook, scope, action)
@@ -4,11 +4,13 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 )
 
 type (
-	// Flat is the flat structure of an event for time series databases. It does not
-	// contain payload data, only metadata.
+	// Flat is the flat structure of an event for time series databases. The payload is kept as protojson, so that it
+	// can be queried with the json functions of the database.
 	Flat[H Hook] struct {
 		Version     EventVersion `json:"version"`      // Version is the version of the event.
 		ID          uuid.UUID    `json:"id"`           // ID is the ID of the event.
@@ -23,5 +25,40 @@ type (
 		TeamID      uuid.UUID    `json:"team_id"`      // TeamID is the ID of the team that the subject belongs to. Can be empty.
 		OrgID       uuid.UUID    `json:"org_id"`       // OrgID is the ID of the organization that the subject belongs to.
 		Timestamp   time.Time    `json:"timestamp"`    // Timestamp is the timestamp of the event.
+		Payload     string       `json:"payload"`      // Payload is the protojson encoded payload of the event.
 	}
 )
+
+// Unflatten restores the event from its flat structure, decoding the protojson payload.
+func Unflatten[H Hook, P Payload](flat *Flat[H]) (*Event[H, P], error) {
+	payload := new(P)
+
+	if msg, ok := any(payload).(proto.Message); ok && flat.Payload != "" {
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal([]byte(flat.Payload), msg); err != nil {
+			return nil, err
+		}
+	}
+
+	event := &Event[H, P]{
+		Version:   flat.Version,
+		ID:        flat.ID,
+		Timestamp: flat.Timestamp,
+		Context: Context[H]{
+			Parents: flat.Parents,
+			Hook:    flat.Hook,
+			Scope:   flat.Scope,
+			Action:  flat.Action,
+			Source:  flat.Source,
+		},
+		Subject: Subject{
+			ID:     flat.SubjectID,
+			Name:   flat.SubjectName,
+			OrgID:  flat.OrgID,
+			TeamID: flat.TeamID,
+			UserID: flat.UserID,
+		},
+		Payload: payload,
+	}
+
+	return event, nil
+}
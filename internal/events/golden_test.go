@@ -0,0 +1,252 @@
+package events_test
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"go.breu.io/quantm/internal/events"
+	eventsv1 "go.breu.io/quantm/internal/proto/ctrlplane/events/v1"
+)
+
+type (
+	// golden is a pinned event. For every version in testdata/golden, the event is kept as the workflows receive it, i.e.
+	// <name>.json, and as it is stored in clickhouse, i.e. <name>.payload.json. Decoding any version must result in the
+	// shape of the current version.
+	golden struct {
+		name    string
+		scope   events.Scope
+		sample  func() (event any, payload string)
+		signal  func(data []byte) ([]byte, error)
+		payload func(scope events.Scope, version events.EventVersion, payload string) (string, error)
+	}
+)
+
+var (
+	update = flag.Bool("update", false, "rewrite the golden files of the current version")
+
+	epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// goldens returns the pinned events of every scope.
+func goldens() []golden {
+	return []golden{
+		{
+			name:  "push",
+			scope: events.ScopePush,
+			sample: sample(events.ScopePush, &eventsv1.Push{
+				Ref:        "refs/heads/feature",
+				Before:     "4b825dc642cb6eb9a060e54bf8d69288fbee4904",
+				After:      "9c2e3b1f9d1a5c5e1f3b6a7d8e9f0a1b2c3d4e5f",
+				Repository: "breuhq/quantm",
+				SenderId:   9007199254740993,
+				Commits: []*eventsv1.Commit{{
+					Sha:       "9c2e3b1f9d1a5c5e1f3b6a7d8e9f0a1b2c3d4e5f",
+					Message:   "fix: handle empty diff",
+					Modified:  []string{"internal/core/repos/states/branch.go"},
+					Author:    &eventsv1.Author{Name: "octocat", Email: "octocat@github.com"},
+					Timestamp: timestamppb.New(epoch),
+				}},
+				Timestamp: timestamppb.New(epoch),
+			}),
+			signal:  signal[eventsv1.Push],
+			payload: payload[eventsv1.Push],
+		},
+		{
+			name:    "branch",
+			scope:   events.ScopeBranch,
+			sample:  sample(events.ScopeBranch, &eventsv1.GitRef{Ref: "feature", Kind: "branch"}),
+			signal:  signal[eventsv1.GitRef],
+			payload: payload[eventsv1.GitRef],
+		},
+		{
+			name:  "pr",
+			scope: events.ScopePr,
+			sample: sample(events.ScopePr, &eventsv1.PullRequest{
+				Number:     42,
+				Title:      "fix: handle empty diff",
+				Author:     "octocat",
+				HeadBranch: "feature",
+				BaseBranch: "main",
+				Timestamp:  timestamppb.New(epoch),
+			}),
+			signal:  signal[eventsv1.PullRequest],
+			payload: payload[eventsv1.PullRequest],
+		},
+		{
+			name:  "merge_queue",
+			scope: events.ScopeMergeQueue,
+			sample: sample(events.ScopeMergeQueue, &eventsv1.MergeQueue{
+				Number:     42,
+				Branch:     "feature",
+				IsPriority: true,
+				Timestamp:  timestamppb.New(epoch),
+			}),
+			signal:  signal[eventsv1.MergeQueue],
+			payload: payload[eventsv1.MergeQueue],
+		},
+		{
+			name:    "rebase",
+			scope:   events.ScopeRebase,
+			sample:  sample(events.ScopeRebase, &eventsv1.Rebase{Base: "main", Head: "feature", Repository: "breuhq/quantm"}),
+			signal:  signal[eventsv1.Rebase],
+			payload: payload[eventsv1.Rebase],
+		},
+	}
+}
+
+func TestGolden(t *testing.T) {
+	goldens := goldens()
+	current := filepath.Join("testdata", "golden", events.EventVersionDefault.String())
+
+	if *update {
+		require.NoError(t, os.MkdirAll(current, 0o750))
+
+		for _, g := range goldens {
+			event, payload := g.sample()
+
+			data, err := json.MarshalIndent(event, "", "  ")
+			require.NoError(t, err)
+
+			require.NoError(t, os.WriteFile(filepath.Join(current, g.name+".json"), append(data, '\n'), 0o600))
+			require.NoError(t, os.WriteFile(filepath.Join(current, g.name+".payload.json"), []byte(payload+"\n"), 0o600))
+		}
+	}
+
+	versions, err := os.ReadDir(filepath.Join("testdata", "golden"))
+	require.NoError(t, err)
+
+	for _, dir := range versions {
+		version := events.EventVersion(dir.Name())
+
+		for _, g := range goldens {
+			t.Run(version.String()+"/"+g.name, func(t *testing.T) {
+				path := filepath.Join("testdata", "golden", version.String(), g.name)
+
+				if _, err := os.Stat(path + ".json"); os.IsNotExist(err) {
+					t.Skipf("no golden file for %s at %s", g.name, version)
+				}
+
+				data, err := os.ReadFile(path + ".json")
+				require.NoError(t, err)
+
+				want, err := os.ReadFile(filepath.Join(current, g.name+".json"))
+				require.NoError(t, err)
+
+				got, err := g.signal(data)
+				require.NoError(t, err)
+				assert.JSONEq(t, string(want), string(got), "signal")
+
+				data, err = os.ReadFile(path + ".payload.json")
+				require.NoError(t, err)
+
+				want, err = os.ReadFile(filepath.Join(current, g.name+".payload.json"))
+				require.NoError(t, err)
+
+				stored, err := g.payload(g.scope, version, string(data))
+				require.NoError(t, err)
+				assert.JSONEq(t, string(want), stored, "payload")
+			})
+		}
+	}
+}
+
+// TestGolden_RoundTrip upcasts the events of 0.1.0 to 0.1.1, the current version, and checks that the upcast events are
+// stable, i.e. decoding and encoding them again gives the same events.
+func TestGolden_RoundTrip(t *testing.T) {
+	require.Equal(t, events.Version_0_1_1, events.EventVersionDefault)
+
+	for _, g := range goldens() {
+		t.Run(g.name, func(t *testing.T) {
+			from := filepath.Join("testdata", "golden", events.Version_0_1_0.String(), g.name)
+			to := filepath.Join("testdata", "golden", events.Version_0_1_1.String(), g.name)
+
+			data, err := os.ReadFile(from + ".json")
+			require.NoError(t, err)
+
+			want, err := os.ReadFile(to + ".json")
+			require.NoError(t, err)
+
+			once, err := g.signal(data)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(want), string(once), "0.1.0 -> 0.1.1")
+
+			twice, err := g.signal(once)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(once), string(twice), "0.1.1 -> current")
+
+			data, err = os.ReadFile(from + ".payload.json")
+			require.NoError(t, err)
+
+			version, stored, err := events.UpcastPayload(g.scope, events.Version_0_1_0, string(data))
+			require.NoError(t, err)
+			assert.Equal(t, events.Version_0_1_1, version)
+
+			want, err = os.ReadFile(to + ".payload.json")
+			require.NoError(t, err)
+
+			upcast, err := g.payload(g.scope, version, stored)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(want), upcast, "payload")
+		})
+	}
+}
+
+// sample returns the sample event of the current version, along with its protojson payload.
+func sample[P events.Payload](scope events.Scope, p *P) func() (any, string) {
+	return func() (any, string) {
+		event := events.
+			New[eventsv1.RepoHook, P]().
+			SetHook(eventsv1.RepoHook_REPO_HOOK_GITHUB).
+			SetScope(scope).
+			SetAction(events.ActionCreated).
+			SetSource("https://github.com/breuhq/quantm").
+			SetOrg(uuid.MustParse("0191c5a6-2b24-7a6e-8d3c-0a1b2c3d4e5f")).
+			SetSubjectName(events.SubjectNameRepos).
+			SetSubjectID(uuid.MustParse("0191c5a6-2b24-7a6e-8d3c-0a1b2c3d4e60")).
+			SetParents(uuid.MustParse("0191c5a6-2b24-7a6e-8d3c-0a1b2c3d4e61")).
+			SetPayload(p)
+
+		event.ID = uuid.MustParse("0191c5a6-2b24-7a6e-8d3c-0a1b2c3d4e62")
+		event.Timestamp = epoch
+
+		return event, event.MarshalPayload()
+	}
+}
+
+// signal decodes the event as the workflows receive it, and encodes it back.
+func signal[P events.Payload](data []byte) ([]byte, error) {
+	event := &events.Event[eventsv1.RepoHook, P]{}
+	if err := json.Unmarshal(data, event); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(event)
+}
+
+// payload upcasts the stored payload, decodes it strictly, and encodes it back.
+func payload[P events.Payload](scope events.Scope, version events.EventVersion, stored string) (string, error) {
+	_, stored, err := events.UpcastPayload(scope, version, stored)
+	if err != nil {
+		return "", err
+	}
+
+	msg := any(new(P)).(proto.Message)
+	if err := protojson.Unmarshal([]byte(stored), msg); err != nil {
+		return "", err
+	}
+
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(msg)
+
+	return string(data), err
+}
@@ -0,0 +1,196 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+type (
+	// Upcaster transforms the payload of an event from one version to the next. The payload is the json object of the
+	// payload, keyed by the proto names of the fields. The same upcaster is applied to the protojson payloads read back from
+	// clickhouse, and to the json payloads of the events received by the workflows, so it must only rename, add or remove
+	// fields, and not rely on the encoding of the values.
+	Upcaster func(payload map[string]any) (map[string]any, error)
+
+	// Upcasters is the registry of the upcasters, keyed by the scope and the version the upcaster transforms from.
+	Upcasters struct {
+		mutex sync.RWMutex
+		steps map[upcast_key]upcast_step
+	}
+
+	upcast_key struct {
+		scope   Scope
+		version EventVersion
+	}
+
+	upcast_step struct {
+		to EventVersion
+		fn Upcaster
+	}
+
+	// envelope holds the fields of the event, required to upcast it.
+	envelope struct {
+		Version EventVersion `json:"version"`
+		Context struct {
+			Scope Scope `json:"scope"`
+		} `json:"context"`
+	}
+)
+
+var (
+	registry = NewUpcasters()
+)
+
+// NewUpcasters creates an empty registry of upcasters.
+func NewUpcasters() *Upcasters {
+	return &Upcasters{steps: make(map[upcast_key]upcast_step)}
+}
+
+// RegisterUpcaster registers the upcaster of the scope, transforming the payload from one version to the next, with the
+// default registry. It is meant to be called from init, and panics if an upcaster is already registered for the scope and
+// the version.
+func RegisterUpcaster(scope Scope, from, to EventVersion, fn Upcaster) {
+	registry.Register(scope, from, to, fn)
+}
+
+// UpcastPayload upcasts the protojson payload of the scope and the version with the default registry.
+func UpcastPayload(scope Scope, version EventVersion, payload string) (EventVersion, string, error) {
+	return registry.Payload(scope, version, payload)
+}
+
+// UpcastJSON upcasts the json encoded event with the default registry.
+func UpcastJSON(data []byte) ([]byte, error) {
+	return registry.JSON(data)
+}
+
+// Register registers the upcaster of the scope, transforming the payload from one version to the next.
+func (u *Upcasters) Register(scope Scope, from, to EventVersion, fn Upcaster) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	key := upcast_key{scope, from}
+	if _, ok := u.steps[key]; ok {
+		panic(fmt.Sprintf("events: upcaster already registered for %s@%s", scope, from))
+	}
+
+	u.steps[key] = upcast_step{to, fn}
+}
+
+// Upcast applies the upcasters of the scope to the payload, starting from the version, until there is no upcaster left.
+// It returns the version the payload was upcast to.
+func (u *Upcasters) Upcast(scope Scope, version EventVersion, payload map[string]any) (EventVersion, map[string]any, error) {
+	u.mutex.RLock()
+	defer u.mutex.RUnlock()
+
+	// every step moves to a different version, so a chain longer than the registry is a cycle.
+	for range len(u.steps) + 1 {
+		step, ok := u.steps[upcast_key{scope, version}]
+		if !ok {
+			return version, payload, nil
+		}
+
+		upcasted, err := step.fn(payload)
+		if err != nil {
+			return version, payload, fmt.Errorf("events: unable to upcast %s@%s: %w", scope, version, err)
+		}
+
+		version, payload = step.to, upcasted
+	}
+
+	return version, payload, fmt.Errorf("events: upcasters of %s form a cycle", scope)
+}
+
+// Payload upcasts the protojson payload of the scope and the version. The payload is returned as is, if there is no
+// upcaster for the scope and the version.
+func (u *Upcasters) Payload(scope Scope, version EventVersion, payload string) (EventVersion, string, error) {
+	if !u.has(scope, version) {
+		return version, payload, nil
+	}
+
+	decoded, err := decode([]byte(payload))
+	if err != nil {
+		return version, payload, err
+	}
+
+	upcasted, decoded, err := u.Upcast(scope, version, decoded)
+	if err != nil {
+		return version, payload, err
+	}
+
+	encoded, err := json.Marshal(decoded)
+	if err != nil {
+		return version, payload, err
+	}
+
+	return upcasted, string(encoded), nil
+}
+
+// JSON upcasts the json encoded event, i.e. the payload and the version, keeping the rest of the event as is. The data
+// is returned as is, if it is not an event or there is no upcaster for its scope and version.
+func (u *Upcasters) JSON(data []byte) ([]byte, error) {
+	env := envelope{}
+
+	// data that is not an event is left to the caller to decode.
+	if json.Unmarshal(data, &env) != nil || !u.has(env.Context.Scope, env.Version) {
+		return data, nil
+	}
+
+	fields := make(map[string]json.RawMessage)
+	err := json.Unmarshal(data, &fields)
+	if err != nil {
+		return data, err
+	}
+
+	payload := make(map[string]any)
+	raw, present := fields["payload"]
+	present = present && string(raw) != "null"
+
+	if present {
+		if payload, err = decode(raw); err != nil {
+			return data, err
+		}
+	}
+
+	version, payload, err := u.Upcast(env.Context.Scope, env.Version, payload)
+	if err != nil {
+		return data, err
+	}
+
+	if fields["version"], err = json.Marshal(version); err != nil {
+		return data, err
+	}
+
+	// an event without a payload is kept without one, unless the upcasters add fields to it.
+	if present || len(payload) > 0 {
+		if fields["payload"], err = json.Marshal(payload); err != nil {
+			return data, err
+		}
+	}
+
+	return json.Marshal(fields)
+}
+
+// has returns true if there is an upcaster for the scope and the version.
+func (u *Upcasters) has(scope Scope, version EventVersion) bool {
+	u.mutex.RLock()
+	defer u.mutex.RUnlock()
+
+	_, ok := u.steps[upcast_key{scope, version}]
+
+	return ok
+}
+
+// decode decodes the json object, keeping the numbers as is, so that the 64 bit integers survive the round trip.
+func decode(data []byte) (map[string]any, error) {
+	decoded := make(map[string]any)
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, err
+	}
+
+	return decoded, nil
+}
@@ -0,0 +1,84 @@
+package events_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.breu.io/quantm/internal/events"
+)
+
+// rename returns an upcaster renaming the field of the payload.
+func rename(from, to string) events.Upcaster {
+	return func(payload map[string]any) (map[string]any, error) {
+		if value, ok := payload[from]; ok {
+			payload[to] = value
+			delete(payload, from)
+		}
+
+		return payload, nil
+	}
+}
+
+func registry() *events.Upcasters {
+	u := events.NewUpcasters()
+	u.Register(events.ScopePush, "0.0.8", "0.0.9", rename("sha", "after"))
+	u.Register(events.ScopePush, "0.0.9", events.Version_0_1_0, rename("repo", "repository"))
+
+	return u
+}
+
+func TestUpcasters_Payload(t *testing.T) {
+	t.Parallel()
+
+	u := registry()
+
+	version, payload, err := u.Payload(events.ScopePush, "0.0.8", `{"sha":"abc","repo":"breuhq/quantm","sender_id":"9007199254740993"}`)
+	require.NoError(t, err)
+
+	assert.Equal(t, events.Version_0_1_0, version)
+	assert.JSONEq(t, `{"after":"abc","repository":"breuhq/quantm","sender_id":"9007199254740993"}`, payload)
+
+	// the other scopes and the current version are left as is.
+	version, payload, err = u.Payload(events.ScopeBranch, "0.0.8", `{"sha":"abc"}`)
+	require.NoError(t, err)
+
+	assert.Equal(t, events.EventVersion("0.0.8"), version)
+	assert.Equal(t, `{"sha":"abc"}`, payload)
+}
+
+func TestUpcasters_JSON(t *testing.T) {
+	t.Parallel()
+
+	u := registry()
+
+	data, err := u.JSON([]byte(`{"version":"0.0.9","id":"x","context":{"scope":"push"},"payload":{"repo":"r","sender_id":9007199254740993}}`))
+	require.NoError(t, err)
+
+	out := make(map[string]json.RawMessage)
+	require.NoError(t, json.Unmarshal(data, &out))
+
+	assert.JSONEq(t, `"0.1.0"`, string(out["version"]))
+	assert.JSONEq(t, `"x"`, string(out["id"]))
+	assert.JSONEq(t, `{"repository":"r","sender_id":9007199254740993}`, string(out["payload"]))
+
+	// not an event.
+	data, err = u.JSON([]byte(`"approve"`))
+	require.NoError(t, err)
+	assert.Equal(t, `"approve"`, string(data))
+}
+
+func TestUpcasters_Cycle(t *testing.T) {
+	t.Parallel()
+
+	u := events.NewUpcasters()
+	u.Register(events.ScopePush, "a", "b", rename("x", "y"))
+	u.Register(events.ScopePush, "b", "a", rename("y", "x"))
+
+	_, _, err := u.Upcast(events.ScopePush, "a", map[string]any{})
+	assert.Error(t, err)
+
+	assert.Panics(t, func() { u.Register(events.ScopePush, "a", "c", rename("x", "z")) })
+}
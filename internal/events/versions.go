@@ -16,5 +16,17 @@ const (
 
 const (
 	// EventVersionDefault alias for the default version. This allows for easy versioning without chaniging the code base.
-	EventVersionDefault = Version_0_1_0
+	EventVersionDefault = Version_0_1_1
 )
+
+// init registers the upcasters from 0.1.0 to 0.1.1. The payloads of 0.1.1 are the same as 0.1.0, so the upcasters only
+// move the events to the current version.
+func init() {
+	for _, scope := range []Scope{
+		ScopeBranch, ScopeTag, ScopePush, ScopeRebase, ScopeDiff, ScopePr, ScopePrLabel, ScopeMerge, ScopeMergeQueue,
+	} {
+		RegisterUpcaster(scope, Version_0_1_0, Version_0_1_1, func(payload map[string]any) (map[string]any, error) {
+			return payload, nil
+		})
+	}
+}
@@ -4,9 +4,13 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	ghi "github.com/bradleyfalzon/ghinstallation/v2"
+	gh "github.com/google/go-github/v62/github"
 
+	"go.breu.io/quantm/internal/core/kernel"
 	"go.breu.io/quantm/internal/db"
 	"go.breu.io/quantm/internal/db/entities"
 	"go.breu.io/quantm/internal/events"
@@ -19,6 +23,13 @@ type (
 	//
 	// Please note that this must never be called from the workflows.
 	Kernel struct{}
+
+	// target holds the authenticated client along with the owner and name of the github repository.
+	target struct {
+		client *gh.Client
+		owner  string
+		name   string
+	}
 )
 
 func (k *Kernel) TokenizedCloneUrl(ctx context.Context, repo *entities.Repo) (string, error) {
@@ -45,6 +56,226 @@ func (k *Kernel) TokenizedCloneUrl(ctx context.Context, repo *entities.Repo) (st
 	return fmt.Sprintf("https://git:%s@github.com/%s.git", token, ghrepo.FullName), nil
 }
 
+func (k *Kernel) AddComment(ctx context.Context, repo *entities.Repo, number int64, body string) error {
+	t, err := k.resolve(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = t.client.Issues.CreateComment(ctx, t.owner, t.name, int(number), &gh.IssueComment{Body: gh.String(body)})
+
+	return err
+}
+
+func (k *Kernel) SetCommitStatus(ctx context.Context, repo *entities.Repo, status *kernel.CommitStatus) error {
+	t, err := k.resolve(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	rs := &gh.RepoStatus{
+		State:       gh.String(status.State.String()),
+		Context:     gh.String(status.Context),
+		Description: gh.String(status.Description),
+	}
+
+	if status.TargetURL != "" {
+		rs.TargetURL = gh.String(status.TargetURL)
+	}
+
+	_, _, err = t.client.Repositories.CreateStatus(ctx, t.owner, t.name, status.SHA, rs)
+
+	return err
+}
+
+func (k *Kernel) CreateCheckRun(ctx context.Context, repo *entities.Repo, run *kernel.CheckRun) (int64, error) {
+	t, err := k.resolve(ctx, repo)
+	if err != nil {
+		return 0, err
+	}
+
+	opts := gh.CreateCheckRunOptions{Name: run.Name, HeadSHA: run.SHA, Output: check_run_output(run)}
+
+	if run.Status != "" {
+		opts.Status = gh.String(run.Status.String())
+	}
+
+	if run.Conclusion != "" {
+		opts.Conclusion = gh.String(run.Conclusion.String())
+		opts.CompletedAt = &gh.Timestamp{Time: time.Now()}
+	}
+
+	if run.DetailsURL != "" {
+		opts.DetailsURL = gh.String(run.DetailsURL)
+	}
+
+	result, _, err := t.client.Checks.CreateCheckRun(ctx, t.owner, t.name, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.GetID(), nil
+}
+
+func (k *Kernel) UpdateCheckRun(ctx context.Context, repo *entities.Repo, run *kernel.CheckRun) error {
+	t, err := k.resolve(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	opts := gh.UpdateCheckRunOptions{Name: run.Name, Output: check_run_output(run)}
+
+	if run.Status != "" {
+		opts.Status = gh.String(run.Status.String())
+	}
+
+	if run.Conclusion != "" {
+		opts.Conclusion = gh.String(run.Conclusion.String())
+		opts.CompletedAt = &gh.Timestamp{Time: time.Now()}
+	}
+
+	if run.DetailsURL != "" {
+		opts.DetailsURL = gh.String(run.DetailsURL)
+	}
+
+	_, _, err = t.client.Checks.UpdateCheckRun(ctx, t.owner, t.name, run.ID, opts)
+
+	return err
+}
+
+func (k *Kernel) AddLabels(ctx context.Context, repo *entities.Repo, number int64, labels ...string) error {
+	t, err := k.resolve(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = t.client.Issues.AddLabelsToIssue(ctx, t.owner, t.name, int(number), labels)
+
+	return err
+}
+
+func (k *Kernel) RemoveLabel(ctx context.Context, repo *entities.Repo, number int64, label string) error {
+	t, err := k.resolve(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	_, err = t.client.Issues.RemoveLabelForIssue(ctx, t.owner, t.name, int(number), label)
+
+	return err
+}
+
+func (k *Kernel) MergePullRequest(
+	ctx context.Context, repo *entities.Repo, number int64, opts *kernel.MergeOptions,
+) (string, error) {
+	t, err := k.resolve(ctx, repo)
+	if err != nil {
+		return "", err
+	}
+
+	if opts == nil {
+		opts = &kernel.MergeOptions{Strategy: kernel.MergeStrategyMerge}
+	}
+
+	options := &gh.PullRequestOptions{
+		CommitTitle: opts.Title,
+		SHA:         opts.SHA,
+		MergeMethod: opts.Strategy.String(),
+	}
+
+	result, _, err := t.client.PullRequests.Merge(ctx, t.owner, t.name, int(number), opts.Message, options)
+	if err != nil {
+		return "", err
+	}
+
+	return result.GetSHA(), nil
+}
+
+func (k *Kernel) CreateBranch(ctx context.Context, repo *entities.Repo, branch, sha string) error {
+	t, err := k.resolve(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	ref := &gh.Reference{Ref: gh.String("refs/heads/" + branch), Object: &gh.GitObject{SHA: gh.String(sha)}}
+	_, _, err = t.client.Git.CreateRef(ctx, t.owner, t.name, ref)
+
+	return err
+}
+
+func (k *Kernel) DeleteBranch(ctx context.Context, repo *entities.Repo, branch string) error {
+	t, err := k.resolve(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	_, err = t.client.Git.DeleteRef(ctx, t.owner, t.name, "heads/"+branch)
+
+	return err
+}
+
+func (k *Kernel) ListBranches(ctx context.Context, repo *entities.Repo) ([]string, error) {
+	t, err := k.resolve(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	branches := make([]string, 0)
+	opts := &gh.BranchListOptions{ListOptions: gh.ListOptions{PerPage: 100}}
+
+	for {
+		page, response, err := t.client.Repositories.ListBranches(ctx, t.owner, t.name, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, branch := range page {
+			branches = append(branches, branch.GetName())
+		}
+
+		if response.NextPage == 0 {
+			return branches, nil
+		}
+
+		opts.Page = response.NextPage
+	}
+}
+
 func (k *Kernel) DetectChanges(ctx context.Context, event *events.Event[eventsv1.RepoHook, eventsv1.Push]) error {
 	return nil
 }
+
+// check_run_output returns the output of the check run, or nil if it has no title. Github requires both the title and
+// the summary of an output.
+func check_run_output(run *kernel.CheckRun) *gh.CheckRunOutput {
+	if run.Title == "" {
+		return nil
+	}
+
+	return &gh.CheckRunOutput{Title: gh.String(run.Title), Summary: gh.String(run.Summary)}
+}
+
+// resolve resolves the github repository and installation for the given repo and returns an authenticated client.
+func (k *Kernel) resolve(ctx context.Context, repo *entities.Repo) (*target, error) {
+	ghrepo, err := db.Queries().GetGithubRepoByID(ctx, repo.HookID)
+	if err != nil {
+		return nil, err
+	}
+
+	install, err := db.Queries().GetGithubInstallation(ctx, ghrepo.InstallationID)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := config.Instance().GetClientForInstallationID(install.InstallationID)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, name, found := strings.Cut(ghrepo.FullName, "/")
+	if !found {
+		return nil, fmt.Errorf("github: invalid full name %q", ghrepo.FullName)
+	}
+
+	return &target{client: client, owner: owner, name: name}, nil
+}
@@ -105,6 +105,10 @@ func (pr *PR) GetNumber() int64 {
 	return pr.Number
 }
 
+func (pr *PR) GetMerged() bool {
+	return pr.PullRequest.Merged
+}
+
 func (pr *PR) GetBody() string {
 	return pr.PullRequest.Body
 }
@@ -0,0 +1,341 @@
+// Package replay re-drives the repo workflow from the stored events.
+//
+// The events of a repo are read for a time range, either from clickhouse or from an NDJSON export of CloudEvents, and
+// signaled again to the repo workflow, in the order they happened. Only the events coming from the hook are replayed,
+// i.e. the pushes, the branch refs, the pull requests and the merge queue labels. The events derived by the workflows,
+// e.g. the rebases, the diffs and the merge conflicts, are recomputed by the workflows as the replayed events are
+// processed.
+package replay
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.breu.io/durex/queues"
+	"golang.org/x/time/rate"
+
+	"go.breu.io/quantm/internal/core/repos"
+	"go.breu.io/quantm/internal/db"
+	"go.breu.io/quantm/internal/events"
+	"go.breu.io/quantm/internal/events/cloudevents"
+	"go.breu.io/quantm/internal/hooks/github/activities"
+	"go.breu.io/quantm/internal/hooks/github/defs"
+	eventsv1 "go.breu.io/quantm/internal/proto/ctrlplane/events/v1"
+	"go.breu.io/quantm/internal/pulse"
+)
+
+type (
+	// Options configures the replay.
+	Options struct {
+		Org    uuid.UUID // Org is the org of the repo, required to read from clickhouse.
+		Repo   uuid.UUID // Repo is the repo to replay.
+		Start  time.Time // Start is the start of the time range, inclusive.
+		End    time.Time // End is the end of the time range, exclusive. Zero means now.
+		DryRun bool      // DryRun logs the events that would be signaled, without signaling them.
+		Rate   float64   // Rate is the number of events signaled per second. Zero or less means no limit.
+
+		// Include opts in to replaying the scopes of OptInScopes, on top of DefaultScopes.
+		Include []events.Scope
+	}
+
+	// Summary is the outcome of the replay.
+	Summary struct {
+		Read     int `json:"read"`     // Read is the number of events read.
+		Replayed int `json:"replayed"` // Replayed is the number of events signaled, or that would be on a dry run.
+		Skipped  int `json:"skipped"`  // Skipped is the number of events not replayable.
+		Failed   int `json:"failed"`   // Failed is the number of events that couldn't be signaled.
+	}
+
+	// Signal signals the event to the repo workflow.
+	Signal func(ctx context.Context, record pulse.Record) error
+)
+
+var (
+	// DefaultScopes are the scopes replayed by default. Replaying a push or a branch event again only recomputes the
+	// state of the branch.
+	DefaultScopes = []events.Scope{events.ScopePush, events.ScopeBranch}
+
+	// OptInScopes are the scopes only replayed when included explicitly. The pull request and the label events add and
+	// remove pull requests from the merge queue, so replaying them may queue a pull request that has been merged since.
+	OptInScopes = []events.Scope{events.ScopePr, events.ScopeMergeQueue}
+
+	// review_actions are the actions of the reviews and the review comments, sharing the pr scope with the pull requests.
+	// They only drive notifications, so they are not replayed.
+	review_actions = []events.Action{events.ActionCreated, events.ActionUpdated, events.ActionDismissed, events.ActionDeleted}
+
+	// label_actions are the actions of the merge queue events coming from the labels. The rest of the merge queue events
+	// are emitted by the trunk.
+	label_actions = []events.Action{events.EventActionAdded, events.EventActionRemoved}
+)
+
+// Scopes returns the scopes replayed with the options, i.e. DefaultScopes and the included OptInScopes. Included scopes
+// outside of OptInScopes are ignored.
+func (o *Options) Scopes() []events.Scope {
+	scopes := slices.Clone(DefaultScopes)
+
+	for _, scope := range OptInScopes {
+		if slices.Contains(o.Include, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return scopes
+}
+
+// FromClickhouse reads the events of the repo in the time range from the events table of the org.
+func FromClickhouse(ctx context.Context, opts *Options) ([]pulse.Record, error) {
+	filter := &pulse.Filter{SubjectID: opts.Repo, Scopes: opts.Scopes(), Start: opts.Start, End: opts.End}
+
+	return pulse.Query(ctx, opts.Org, filter)
+}
+
+// FromFile reads the events of the repo in the time range from an NDJSON export of CloudEvents, ordered by timestamp.
+// Lines that are not quantm events are skipped. Like the events read from clickhouse, the events exported before a
+// payload change are upcast to the current shape.
+func FromFile(reader io.Reader, opts *Options) ([]pulse.Record, error) {
+	records := make([]pulse.Record, 0)
+	scopes := opts.Scopes()
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+
+		event := &cloudevents.Event{}
+		if err := json.Unmarshal(line, event); err != nil {
+			return nil, err
+		}
+
+		record, err := decode(event)
+		if err != nil {
+			slog.Warn("replay: skipping event", "id", event.ID, "error", err.Error())
+			continue
+		}
+
+		record.Version, record.Payload, err = events.UpcastPayload(record.Scope, record.Version, record.Payload)
+		if err != nil {
+			slog.Warn("replay: skipping event", "id", event.ID, "error", err.Error())
+			continue
+		}
+
+		if record.SubjectID != opts.Repo || !slices.Contains(scopes, record.Scope) {
+			continue
+		}
+
+		if record.Timestamp.Before(opts.Start) || (!opts.End.IsZero() && !record.Timestamp.Before(opts.End)) {
+			continue
+		}
+
+		records = append(records, record)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Timestamp.Before(records[j].Timestamp) })
+
+	return records, nil
+}
+
+// Run signals the replayable records in order, at the rate of the options. A failing record is logged and counted, and
+// the replay moves on. Run only returns an error if the context is done.
+func Run(ctx context.Context, records []pulse.Record, opts *Options, signal Signal) (*Summary, error) {
+	summary := &Summary{Read: len(records)}
+
+	limit := rate.Inf
+	if opts.Rate > 0 {
+		limit = rate.Limit(opts.Rate)
+	}
+
+	limiter := rate.NewLimiter(limit, 1)
+
+	for _, record := range records {
+		if !Replayable(record, opts) {
+			summary.Skipped++
+			continue
+		}
+
+		attrs := []any{"id", record.ID.String(), "scope", record.Scope, "action", record.Action, "timestamp", record.Timestamp}
+
+		if opts.DryRun {
+			slog.Info("replay: dry run, skipping signal", attrs...)
+
+			summary.Replayed++
+
+			continue
+		}
+
+		if err := limiter.Wait(ctx); err != nil {
+			return summary, err
+		}
+
+		if err := signal(ctx, record); err != nil {
+			slog.Warn("replay: unable to signal", append(attrs, "error", err.Error())...)
+
+			summary.Failed++
+
+			continue
+		}
+
+		slog.Info("replay: signaled", attrs...)
+
+		summary.Replayed++
+	}
+
+	return summary, nil
+}
+
+// Replayable returns true if the record came from the github hook, drives the state of the repo workflow, and its scope
+// is replayed with the options.
+func Replayable(record pulse.Record, opts *Options) bool {
+	if record.Hook != int32(eventsv1.RepoHook_REPO_HOOK_GITHUB) || !slices.Contains(opts.Scopes(), record.Scope) {
+		return false
+	}
+
+	switch {
+	case record.Scope == events.ScopePush, record.Scope == events.ScopeBranch:
+		return true
+	case record.Scope == events.ScopePr:
+		return !slices.Contains(review_actions, record.Action)
+	case record.Scope == events.ScopeMergeQueue:
+		return slices.Contains(label_actions, record.Action)
+	default:
+		return false
+	}
+}
+
+// Signaler returns the signal for the repo, hydrated once with the repo and its chat link.
+func Signaler(ctx context.Context, id uuid.UUID) (Signal, error) {
+	repo, err := db.Queries().GetRepoByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := &defs.HydratedRepoEvent{Repo: &repo, ChatLinks: &defs.ChatLinks{}}
+
+	link, err := db.Queries().GetChatLink(ctx, repo.ID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	if err == nil {
+		meta.ChatLinks.Repo = &link
+	}
+
+	return func(ctx context.Context, record pulse.Record) error {
+		switch {
+		case record.Scope == events.ScopePush:
+			return send[eventsv1.Push](ctx, record, meta, repos.SignalPush)
+		case record.Scope == events.ScopeBranch:
+			return send[eventsv1.GitRef](ctx, record, meta, repos.SignalRef)
+		case record.Scope == events.ScopePr:
+			return send[eventsv1.PullRequest](ctx, record, meta, repos.SignalPullRequest)
+		case record.Scope == events.ScopeMergeQueue:
+			return send[eventsv1.MergeQueue](ctx, record, meta, repos.SignalMergeQueue)
+		default:
+			return nil
+		}
+	}, nil
+}
+
+// send restores the event of the record with its payload, and signals it to the repo workflow.
+func send[P events.Payload](ctx context.Context, record pulse.Record, meta *defs.HydratedRepoEvent, signal queues.Signal) error {
+	flat := &events.Flat[eventsv1.RepoHook]{
+		Version:     record.Version,
+		ID:          record.ID,
+		Parents:     record.Parents,
+		Hook:        eventsv1.RepoHook(record.Hook),
+		Scope:       record.Scope,
+		Action:      record.Action,
+		Source:      record.Source,
+		SubjectID:   record.SubjectID,
+		SubjectName: record.SubjectName,
+		UserID:      record.UserID,
+		TeamID:      record.TeamID,
+		OrgID:       record.OrgID,
+		Timestamp:   record.Timestamp,
+		Payload:     record.Payload,
+	}
+
+	event, err := events.Unflatten[eventsv1.RepoHook, P](flat)
+	if err != nil {
+		return err
+	}
+
+	return activities.SignalRepo(ctx, &defs.HydratedQuantmEvent[P]{Event: event, Meta: meta, Signal: signal})
+}
+
+// decode restores the record from the extension attributes of the CloudEvent.
+func decode(event *cloudevents.Event) (pulse.Record, error) {
+	ext := event.Extensions
+	record := pulse.Record{
+		Version:     events.EventVersion(ext[cloudevents.ExtensionVersion]),
+		Scope:       events.Scope(ext[cloudevents.ExtensionScope]),
+		Action:      events.Action(ext[cloudevents.ExtensionAction]),
+		SubjectName: ext[cloudevents.ExtensionSubjectName],
+		Timestamp:   event.Time,
+		Payload:     string(event.Data),
+		Parents:     make([]uuid.UUID, 0),
+	}
+
+	if event.Source != cloudevents.SourceNone {
+		record.Source = event.Source
+	}
+
+	if hook, ok := eventsv1.RepoHook_value[ext[cloudevents.ExtensionHook]]; ok {
+		record.Hook = hook
+	} else if hook, ok := eventsv1.ChatHook_value[ext[cloudevents.ExtensionHook]]; ok {
+		record.Hook = hook
+	}
+
+	ids := []struct {
+		value string
+		dst   *uuid.UUID
+	}{
+		{event.ID, &record.ID},
+		{ext[cloudevents.ExtensionSubjectID], &record.SubjectID},
+		{ext[cloudevents.ExtensionOrgID], &record.OrgID},
+		{ext[cloudevents.ExtensionTeamID], &record.TeamID},
+		{ext[cloudevents.ExtensionUserID], &record.UserID},
+	}
+
+	for _, id := range ids {
+		if id.value == "" {
+			continue
+		}
+
+		parsed, err := uuid.Parse(id.value)
+		if err != nil {
+			return record, err
+		}
+
+		*id.dst = parsed
+	}
+
+	if parents := ext[cloudevents.ExtensionParents]; parents != "" {
+		for _, value := range strings.Split(parents, ",") {
+			id, err := uuid.Parse(value)
+			if err != nil {
+				return record, err
+			}
+
+			record.Parents = append(record.Parents, id)
+		}
+	}
+
+	return record, nil
+}
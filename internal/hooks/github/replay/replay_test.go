@@ -0,0 +1,191 @@
+package replay_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.breu.io/quantm/internal/events"
+	"go.breu.io/quantm/internal/events/cloudevents"
+	"go.breu.io/quantm/internal/hooks/github/replay"
+	eventsv1 "go.breu.io/quantm/internal/proto/ctrlplane/events/v1"
+	"go.breu.io/quantm/internal/pulse"
+)
+
+var (
+	epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+const (
+	// legacy is the version of the push events exported before the ref of the push was renamed, for the tests only.
+	legacy events.EventVersion = "0.0.1"
+)
+
+func init() {
+	events.RegisterUpcaster(events.ScopePush, legacy, events.EventVersionDefault, func(payload map[string]any) (map[string]any, error) {
+		payload["ref"] = payload["branch"]
+		delete(payload, "branch")
+
+		return payload, nil
+	})
+}
+
+func TestFromFile(t *testing.T) {
+	t.Parallel()
+
+	repo := uuid.New()
+	parent := uuid.New()
+
+	buf := &bytes.Buffer{}
+	write(t, buf, flat(repo, events.ScopePush, events.ActionCreated, epoch.Add(2*time.Hour), parent))
+	write(t, buf, flat(repo, events.ScopeBranch, events.ActionCreated, epoch.Add(time.Hour)))
+	write(t, buf, flat(uuid.New(), events.ScopePush, events.ActionCreated, epoch.Add(time.Hour)))
+	write(t, buf, flat(repo, events.ScopeRebase, events.ActionRequested, epoch.Add(time.Hour)))
+	write(t, buf, flat(repo, events.ScopePr, "opened", epoch.Add(time.Hour)))
+	write(t, buf, flat(repo, events.ScopePush, events.ActionCreated, epoch.Add(48*time.Hour)))
+
+	opts := &replay.Options{Repo: repo, Start: epoch, End: epoch.Add(24 * time.Hour)}
+
+	records, err := replay.FromFile(buf, opts)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	assert.Equal(t, events.ScopeBranch, records[0].Scope)
+	assert.Equal(t, events.ScopePush, records[1].Scope)
+	assert.Equal(t, []uuid.UUID{parent}, records[1].Parents)
+	assert.Equal(t, int32(eventsv1.RepoHook_REPO_HOOK_GITHUB), records[1].Hook)
+	assert.JSONEq(t, `{"ref":"refs/heads/main"}`, records[1].Payload)
+}
+
+func TestFromFile_Upcast(t *testing.T) {
+	t.Parallel()
+
+	repo := uuid.New()
+
+	old := flat(repo, events.ScopePush, events.ActionCreated, epoch.Add(time.Hour))
+	old.Version = legacy
+	old.Payload = `{"branch":"refs/heads/main"}`
+
+	buf := &bytes.Buffer{}
+	write(t, buf, old)
+
+	records, err := replay.FromFile(buf, &replay.Options{Repo: repo, Start: epoch, End: epoch.Add(24 * time.Hour)})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+
+	assert.Equal(t, events.EventVersionDefault, records[0].Version)
+	assert.JSONEq(t, `{"ref":"refs/heads/main"}`, records[0].Payload)
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	github := int32(eventsv1.RepoHook_REPO_HOOK_GITHUB)
+	records := []pulse.Record{
+		{ID: uuid.New(), Hook: github, Scope: events.ScopePush, Action: events.ActionCreated},
+		{ID: uuid.New(), Hook: github, Scope: events.ScopePr, Action: "opened"},
+		{ID: uuid.New(), Hook: github, Scope: events.ScopePr, Action: events.ActionCreated},
+		{ID: uuid.New(), Hook: github, Scope: events.ScopeMergeQueue, Action: events.EventActionAdded},
+		{ID: uuid.New(), Hook: github, Scope: events.ScopeMergeQueue, Action: events.ActionEnqueued},
+		{ID: uuid.New(), Hook: github, Scope: events.ScopeBranch, Action: events.ActionDeleted},
+	}
+
+	signaled := make([]uuid.UUID, 0)
+	signal := func(_ context.Context, record pulse.Record) error {
+		if record.Scope == events.ScopeBranch {
+			return errors.New("workflow unavailable")
+		}
+
+		signaled = append(signaled, record.ID)
+
+		return nil
+	}
+
+	opts := &replay.Options{Rate: 1000, Include: replay.OptInScopes}
+
+	summary, err := replay.Run(context.Background(), records, opts, signal)
+	require.NoError(t, err)
+
+	assert.Equal(t, &replay.Summary{Read: 6, Replayed: 3, Skipped: 2, Failed: 1}, summary)
+	assert.Equal(t, []uuid.UUID{records[0].ID, records[1].ID, records[3].ID}, signaled)
+}
+
+func TestRun_DefaultScopes(t *testing.T) {
+	t.Parallel()
+
+	github := int32(eventsv1.RepoHook_REPO_HOOK_GITHUB)
+	records := []pulse.Record{
+		{ID: uuid.New(), Hook: github, Scope: events.ScopePush, Action: events.ActionCreated},
+		{ID: uuid.New(), Hook: github, Scope: events.ScopePr, Action: "opened"},
+		{ID: uuid.New(), Hook: github, Scope: events.ScopeMergeQueue, Action: events.EventActionAdded},
+		{ID: uuid.New(), Hook: github, Scope: events.ScopeBranch, Action: events.ActionDeleted},
+	}
+
+	signaled := make([]uuid.UUID, 0)
+	signal := func(_ context.Context, record pulse.Record) error {
+		signaled = append(signaled, record.ID)
+		return nil
+	}
+
+	// the pull request and the label events are only replayed when included.
+	summary, err := replay.Run(context.Background(), records, &replay.Options{}, signal)
+	require.NoError(t, err)
+
+	assert.Equal(t, &replay.Summary{Read: 4, Replayed: 2, Skipped: 2}, summary)
+	assert.Equal(t, []uuid.UUID{records[0].ID, records[3].ID}, signaled)
+
+	// included scopes outside of the opt-in scopes are ignored.
+	assert.Equal(t, replay.DefaultScopes, (&replay.Options{Include: []events.Scope{events.ScopeRebase}}).Scopes())
+}
+
+func TestRun_DryRun(t *testing.T) {
+	t.Parallel()
+
+	records := []pulse.Record{
+		{ID: uuid.New(), Hook: int32(eventsv1.RepoHook_REPO_HOOK_GITHUB), Scope: events.ScopePush},
+		{ID: uuid.New(), Hook: int32(eventsv1.ChatHook_CHAT_HOOK_SLACK), Scope: events.ScopePush},
+	}
+
+	signal := func(context.Context, pulse.Record) error {
+		t.Fatal("signaled on a dry run")
+		return nil
+	}
+
+	summary, err := replay.Run(context.Background(), records, &replay.Options{DryRun: true}, signal)
+	require.NoError(t, err)
+
+	assert.Equal(t, &replay.Summary{Read: 2, Replayed: 1, Skipped: 1}, summary)
+}
+
+func flat(repo uuid.UUID, scope events.Scope, action events.Action, ts time.Time, parents ...uuid.UUID) events.Flat[eventsv1.RepoHook] {
+	return events.Flat[eventsv1.RepoHook]{
+		Version:     events.EventVersionDefault,
+		ID:          uuid.New(),
+		Parents:     parents,
+		Hook:        eventsv1.RepoHook_REPO_HOOK_GITHUB,
+		Scope:       scope,
+		Action:      action,
+		Source:      "https://github.com/breuhq/quantm",
+		SubjectID:   repo,
+		SubjectName: events.SubjectNameRepos,
+		OrgID:       uuid.New(),
+		Timestamp:   ts,
+		Payload:     `{"ref":"refs/heads/main"}`,
+	}
+}
+
+func write(t *testing.T, buf *bytes.Buffer, flat events.Flat[eventsv1.RepoHook]) {
+	t.Helper()
+
+	line, err := json.Marshal(cloudevents.FromFlat(flat))
+	require.NoError(t, err)
+
+	buf.Write(append(line, '\n'))
+}
@@ -55,7 +55,7 @@ func handle_pr(ctx workflow.Context, pr *defs.PR, repo_evt *defs.HydratedRepoEve
 		New[eventsv1.RepoHook, eventsv1.PullRequest]().
 		SetHook(eventsv1.RepoHook_REPO_HOOK_GITHUB).
 		SetScope(events.ScopePr).
-		SetAction(events.Action(pr.GetAction())). // TODO - handle the PR actions
+		SetAction(pr_action(pr)). // TODO - handle the PR actions
 		SetSource(repo_evt.GetRepoUrl()).
 		SetOrg(repo_evt.GetOrgID()).
 		SetSubjectName(events.SubjectNameRepos).
@@ -83,6 +83,16 @@ func handle_pr(ctx workflow.Context, pr *defs.PR, repo_evt *defs.HydratedRepoEve
 	return workflow.ExecuteActivity(ctx, acts.SignalRepoWithGithubPR, hevent).Get(ctx, nil)
 }
 
+// pr_action returns the action of the pull request event. GitHub closes the merged and the unmerged pull requests alike,
+// a merged one is recorded as merged.
+func pr_action(pr *defs.PR) events.Action {
+	if pr.GetAction() == defs.PullRequestClosed && pr.GetMerged() {
+		return events.ActionMerged
+	}
+
+	return events.Action(pr.GetAction())
+}
+
 // handle_label processes a pull request label event, creating a QuantmEvent and signaling the merge queue, if applicable.
 func handle_label(ctx workflow.Context, pr *defs.PR, repo_evt *defs.HydratedRepoEvent) error {
 	acts := &activities.PullRequest{}
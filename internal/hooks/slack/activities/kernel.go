@@ -3,15 +3,17 @@ package activities
 import (
 	"context"
 	"encoding/json"
-	"fmt"
-	"strconv"
-	"time"
+	"errors"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/slack-go/slack"
 
+	"go.breu.io/quantm/internal/core/kernel"
 	"go.breu.io/quantm/internal/db"
+	"go.breu.io/quantm/internal/db/entities"
 	"go.breu.io/quantm/internal/events"
+	"go.breu.io/quantm/internal/hooks/slack/blocks"
 	"go.breu.io/quantm/internal/hooks/slack/cast"
 	"go.breu.io/quantm/internal/hooks/slack/config"
 	"go.breu.io/quantm/internal/hooks/slack/fns"
@@ -23,90 +25,185 @@ type (
 	Kernel struct{}
 )
 
-const (
-	footer = "Powered by quantm.io"
-)
+// Notify renders the notification to Block Kit and sends it to the recipient. Users are messaged directly, while
+// channels and teams are messaged on the channel connected with the chat link.
+//
+// Threaded notifications are posted as the root of the thread the first time. Afterwards, they are posted as replies
+// and the root is updated to show the latest status. Status updates are only posted on existing threads. A final
+// notification closes the thread, so that the next notification on the subject starts a new one.
+func (k *Kernel) Notify(ctx context.Context, notification *kernel.Notification) error {
+	token, target, err := k.resolve(ctx, notification.Recipient)
+	if err != nil {
+		return err
+	}
 
-var (
-	ts = json.Number(strconv.FormatInt(time.Now().Unix(), 10))
-)
+	client, err := config.GetSlackClient(token)
+	if err != nil {
+		return err
+	}
+
+	if notification.Thread == nil {
+		return fns.SendBlocks(client, target, blocks.Fallback(notification), blocks.Notification(notification)...)
+	}
+
+	return k.thread(ctx, client, target, notification)
+}
 
 func (k *Kernel) NotifyLinesExceed(
 	ctx context.Context, event *events.Event[eventsv1.ChatHook, eventsv1.Diff],
 ) error {
-	var err error
+	return k.Notify(ctx, kernel.LinesExceededNotification(event))
+}
 
-	token := ""
-	target := ""
+func (k *Kernel) NotifyMergeConflict(
+	ctx context.Context, event *events.Event[eventsv1.ChatHook, eventsv1.Merge],
+) error {
+	return k.Notify(ctx, kernel.MergeConflictNotification(event))
+}
 
-	if event.Subject.UserID != uuid.Nil {
-		token, target, err = k.to_user(ctx, event.Subject.UserID)
-		if err != nil {
-			return err
+// thread posts the notification on the thread for its subject, starting the thread if it doesn't exist. Threads are
+// kept per recipient, so the same subject notified to a user and to a channel gets a thread on each.
+func (k *Kernel) thread(ctx context.Context, client *slack.Client, target string, notification *kernel.Notification) error {
+	status := k.status(notification)
+
+	thread, err := db.Queries().GetChatThread(ctx, entities.GetChatThreadParams{
+		Hook:      int32(eventsv1.ChatHook_CHAT_HOOK_SLACK),
+		SubjectID: notification.Thread.SubjectID,
+		Ref:       notification.Thread.Ref,
+		Recipient: notification.Recipient.String(),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		if notification.Kind == kernel.NotificationKindStatus {
+			return nil
 		}
-	} else {
-		token, target, err = k.to_repo(ctx, event.Subject.ID)
-		if err != nil {
+
+		thread, err = k.save_thread(ctx, target, notification, status)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// the root is saved before it is posted, so that a retry posts the root saved by the first attempt rather than
+	// starting a second thread. The notifications of a subject are sent one after the other, so the pending root is the
+	// notification being sent.
+	if thread.Ts == "" {
+		if err := k.start_thread(ctx, client, &thread); err != nil {
 			return err
 		}
+
+		return k.close_thread(ctx, notification)
 	}
 
-	attachment := slack.Attachment{
-		Color:      "warning",
-		Pretext:    "The number of lines in this pull request exceeds the allowed threshold. Please review and adjust accordingly.",
-		Fallback:   "Line Exceed Detected",
-		MarkdownIn: []string{"fields"},
-		Footer:     footer,
-		Fields:     fields_lines_exceeded(event),
-		Ts:         ts,
+	reply := blocks.Notification(notification)
+	if notification.Kind == kernel.NotificationKindStatus {
+		reply = blocks.Status(notification)
 	}
 
-	client, err := config.GetSlackClient(token)
-	if err != nil {
+	if _, _, err := fns.PostBlocks(client, thread.ChannelID, thread.Ts, blocks.Fallback(notification), reply...); err != nil {
 		return err
 	}
 
-	return fns.SendMessage(client, target, attachment)
+	root := &kernel.Notification{}
+	if err := json.Unmarshal(thread.Data, root); err != nil {
+		return err
+	}
+
+	root.SetStatus(status)
+
+	if err := fns.UpdateBlocks(client, thread.ChannelID, thread.Ts, blocks.Fallback(root), k.root(&thread, root)...); err != nil {
+		return err
+	}
+
+	if notification.Final {
+		return k.close_thread(ctx, notification)
+	}
+
+	return db.Queries().UpdateChatThreadStatus(ctx, entities.UpdateChatThreadStatusParams{ID: thread.ID, Status: status})
 }
 
-func (k *Kernel) NotifyMergeConflict(
-	ctx context.Context, event *events.Event[eventsv1.ChatHook, eventsv1.Merge],
-) error {
-	var err error
+// close_thread forgets the thread once the notification is final. The messages are kept on slack.
+func (k *Kernel) close_thread(ctx context.Context, notification *kernel.Notification) error {
+	if !notification.Final {
+		return nil
+	}
 
-	token := ""
-	target := ""
+	return db.Queries().DeleteChatThread(ctx, entities.DeleteChatThreadParams{
+		Hook:      int32(eventsv1.ChatHook_CHAT_HOOK_SLACK),
+		SubjectID: notification.Thread.SubjectID,
+		Ref:       notification.Thread.Ref,
+		Recipient: notification.Recipient.String(),
+	})
+}
 
-	if event.Subject.UserID != uuid.Nil {
-		token, target, err = k.to_user(ctx, event.Subject.UserID)
-		if err != nil {
-			return err
-		}
-	} else {
-		token, target, err = k.to_repo(ctx, event.Subject.ID)
-		if err != nil {
-			return err
-		}
+// save_thread saves the notification as the root of a new thread, before it is posted.
+func (k *Kernel) save_thread(
+	ctx context.Context, target string, notification *kernel.Notification, status string,
+) (entities.ChatThread, error) {
+	root := *notification
+	root.SetStatus(status)
+
+	data, err := json.Marshal(&root)
+	if err != nil {
+		return entities.ChatThread{}, err
 	}
 
-	client, err := config.GetSlackClient(token)
+	return db.Queries().CreateChatThread(ctx, entities.CreateChatThreadParams{
+		Hook:      int32(eventsv1.ChatHook_CHAT_HOOK_SLACK),
+		SubjectID: notification.Thread.SubjectID,
+		Ref:       notification.Thread.Ref,
+		Recipient: notification.Recipient.String(),
+		ChannelID: target,
+		Status:    status,
+		Data:      data,
+	})
+}
+
+// start_thread posts the saved root of the thread, and records where it was posted for later replies. Users are
+// messaged on their direct message channel, which is only known once posted.
+func (k *Kernel) start_thread(ctx context.Context, client *slack.Client, thread *entities.ChatThread) error {
+	root := &kernel.Notification{}
+	if err := json.Unmarshal(thread.Data, root); err != nil {
+		return err
+	}
+
+	channel, ts, err := fns.PostBlocks(client, thread.ChannelID, "", blocks.Fallback(root), blocks.Notification(root)...)
 	if err != nil {
 		return err
 	}
 
-	attachment := slack.Attachment{
-		Color: "warning",
-		Pretext: fmt.Sprintf(`We've detected a merge conflict in your feature branch, <%s/tree/%s|%s>.
-    This means there are changes in your branch that clash with recent updates on the main branch (trunk).`,
-			event.Context.Source, event.Payload.HeadBranch, event.Payload.HeadBranch),
-		Fallback:   "Merge Conflict Detected",
-		MarkdownIn: []string{"fields"},
-		Footer:     footer,
-		Fields:     fields_merge_conflict(event),
-		Ts:         ts,
+	return db.Queries().UpdateChatThreadRoot(ctx, entities.UpdateChatThreadRootParams{
+		ID:        thread.ID,
+		ChannelID: channel,
+		Ts:        ts,
+	})
+}
+
+// root renders the root of the thread, keeping it dismissed once a user dismissed it.
+func (k *Kernel) root(thread *entities.ChatThread, root *kernel.Notification) []slack.Block {
+	if thread.DismissedBy != "" {
+		return blocks.Dismissed(blocks.Notification(root), thread.DismissedBy)
+	}
+
+	return blocks.Notification(root)
+}
+
+// status returns the status shown on the root of the thread, falling back to the title of the notification.
+func (k *Kernel) status(notification *kernel.Notification) string {
+	if notification.Status != "" {
+		return notification.Status
+	}
+
+	return notification.Title
+}
+
+// resolve returns the token and the target channel for the recipient.
+func (k *Kernel) resolve(ctx context.Context, recipient kernel.Recipient) (string, string, error) {
+	if recipient.Kind == kernel.RecipientUser {
+		return k.to_user(ctx, recipient.ID)
 	}
 
-	return fns.SendMessage(client, target, attachment)
+	return k.to_channel(ctx, recipient.ID)
 }
 
 func (k *Kernel) to_user(ctx context.Context, link_to uuid.UUID) (string, string, error) {
@@ -128,7 +225,8 @@ func (k *Kernel) to_user(ctx context.Context, link_to uuid.UUID) (string, string
 	return token, d.ProviderUserID, nil
 }
 
-func (k *Kernel) to_repo(ctx context.Context, link_to uuid.UUID) (string, string, error) {
+// to_channel resolves the channel linked to a repo or a team.
+func (k *Kernel) to_channel(ctx context.Context, link_to uuid.UUID) (string, string, error) {
 	msg, err := db.Queries().GetChatLink(ctx, link_to)
 	if err != nil {
 		return "", "", err
@@ -4,12 +4,15 @@ import (
 	"go.breu.io/quantm/internal/hooks/slack/activities"
 	"go.breu.io/quantm/internal/hooks/slack/config"
 	"go.breu.io/quantm/internal/hooks/slack/nomad"
+	"go.breu.io/quantm/internal/hooks/slack/web"
 )
 
 type (
 	Config = config.Config
 
 	KernelImpl = activities.Kernel
+
+	Webhook = web.Webhook
 )
 
 var (
@@ -0,0 +1,101 @@
+package blocks
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/slack-go/slack"
+
+	"go.breu.io/quantm/internal/core/repos"
+	eventsv1 "go.breu.io/quantm/internal/proto/ctrlplane/events/v1"
+)
+
+const (
+	max_queue_items = 50                    // the merge queue lists at most 50 items per section, the rest are counted.
+	max_queue_text  = max_section_text - 16 // the items leave room for the title of their section.
+)
+
+// Text renders a plain reply to a command.
+func Text(text string) []slack.Block {
+	return []slack.Block{slack.NewSectionBlock(markdown(text), nil, nil)}
+}
+
+// Queue renders the merge queue of the repo.
+func Queue(repo string, status *repos.QueueStatus) []slack.Block {
+	title := fmt.Sprintf("Merge queue for %s", repo)
+	if status.Frozen {
+		title += " (frozen)"
+	}
+
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, truncate(title, max_header_text), true, false)),
+	}
+
+	if len(status.InFlight) > 0 {
+		blocks = append(blocks, slack.NewSectionBlock(markdown("*Merging*\n"+items(status.InFlight)), nil, nil))
+	}
+
+	if len(status.Items) == 0 {
+		blocks = append(blocks, slack.NewSectionBlock(markdown("The queue is empty."), nil, nil))
+	} else {
+		blocks = append(blocks, slack.NewSectionBlock(markdown("*Waiting*\n"+items(status.Items)), nil, nil))
+	}
+
+	return append(blocks, context(nil))
+}
+
+// BranchStatus renders the status of the branch along with its position in the merge queue.
+func BranchStatus(repo string, status *repos.BranchStatus, queue *repos.QueueStatus) []slack.Block {
+	title := fmt.Sprintf("%s on %s", status.Branch, repo)
+	short := make([]*slack.TextBlockObject, 0)
+
+	if commit := status.LatestCommit; commit != nil {
+		sha := commit.GetSha()
+		if len(sha) > 7 {
+			sha = sha[:7]
+		}
+
+		short = append(short, markdown(fmt.Sprintf("*Latest Commit*\n<%s|%s>", commit.GetUrl(), sha)))
+	}
+
+	position := "not queued"
+	if p := queue.Position(status.Branch); p > 0 {
+		position = fmt.Sprintf("%d of %d", p, len(queue.Items))
+	}
+
+	short = append(short, markdown(fmt.Sprintf("*Merge Queue*\n%s", position)))
+
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, truncate(title, max_header_text), true, false)),
+		slack.NewSectionBlock(nil, short, nil),
+	}
+
+	if commit := status.LatestCommit; commit != nil && commit.GetMessage() != "" {
+		blocks = append(blocks, slack.NewSectionBlock(markdown(truncate(commit.GetMessage(), max_section_text)), nil, nil))
+	}
+
+	return append(blocks, context(nil))
+}
+
+// items formats the merge queue items as a numbered list. The list is cut at max_queue_items, or earlier to fit in a
+// section, followed by the number of items left out, e.g. "…and 42 more".
+func items(list []*eventsv1.MergeQueue) string {
+	lines := make([]string, 0, min(len(list), max_queue_items)+1)
+	reserve := len(fmt.Sprintf("\n…and %d more", len(list)))
+	size := 0
+
+	for idx, item := range list {
+		line := fmt.Sprintf("%d. #%d %s", idx+1, item.GetNumber(), item.GetBranch())
+
+		if idx == max_queue_items || size+len(line)+1+reserve > max_queue_text {
+			lines = append(lines, fmt.Sprintf("…and %d more", len(list)-idx))
+			break
+		}
+
+		size += len(line) + 1
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,52 @@
+package blocks
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+
+	"go.breu.io/quantm/internal/core/repos"
+	eventsv1 "go.breu.io/quantm/internal/proto/ctrlplane/events/v1"
+)
+
+func TestQueue_Long(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		count  int
+		branch string
+		more   string
+	}{
+		{"short", 3, "feature", ""},
+		{"many items", 120, "feature", "…and 70 more"},
+		{"long branches", 40, strings.Repeat("feature/", 20), "more"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			status := &repos.QueueStatus{}
+			for idx := range tt.count {
+				status.Items = append(status.Items, &eventsv1.MergeQueue{Number: int64(idx + 1), Branch: fmt.Sprintf("%s-%d", tt.branch, idx)})
+			}
+
+			rendered := Queue("quantm", status)
+			assert.LessOrEqual(t, len(rendered), 50)
+
+			section, ok := rendered[1].(*slack.SectionBlock)
+			assert.True(t, ok)
+			assert.LessOrEqual(t, len(section.Text.Text), max_section_text)
+
+			if tt.more == "" {
+				assert.NotContains(t, section.Text.Text, "more")
+			} else {
+				assert.True(t, strings.HasSuffix(section.Text.Text, tt.more), section.Text.Text)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package blocks
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/slack-go/slack"
+
+	"go.breu.io/quantm/internal/core/kernel"
+)
+
+const (
+	footer = "Powered by quantm.io"
+
+	max_section_fields   = 10   // slack allows at most 10 fields per section.
+	max_section_text     = 3000 // slack rejects section text longer than 3000 characters.
+	max_field_text       = 2000 // slack rejects section field text longer than 2000 characters.
+	max_header_text      = 150  // slack rejects header text longer than 150 characters.
+	max_context_elements = 10   // slack allows at most 10 elements per context block.
+)
+
+var (
+	emojis = map[kernel.Severity]string{
+		kernel.SeverityInfo:    ":information_source:",
+		kernel.SeveritySuccess: ":white_check_mark:",
+		kernel.SeverityWarning: ":warning:",
+		kernel.SeverityError:   ":rotating_light:",
+	}
+)
+
+// Notification renders the provider neutral notification to Block Kit.
+//
+// The layout is a header with the title, the status if set, a section with the body, sections for the fields with short fields rendered
+// side by side, a context block with the links and the footer, and an actions block if the notification has actions.
+func Notification(n *kernel.Notification) []slack.Block {
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, truncate(header(n), max_header_text), true, false)),
+	}
+
+	if n.Status != "" {
+		blocks = append(blocks, slack.NewContextBlock("", markdown(fmt.Sprintf("*Status:* %s", n.Status))))
+	}
+
+	if n.Body != "" {
+		blocks = append(blocks, slack.NewSectionBlock(markdown(truncate(n.Body, max_section_text)), nil, nil))
+	}
+
+	blocks = append(blocks, fields(n.Fields)...)
+	blocks = append(blocks, slack.NewDividerBlock())
+	blocks = append(blocks, context(n.Links))
+
+	if len(n.Actions) > 0 {
+		blocks = append(blocks, Actions(n.Actions))
+	}
+
+	return blocks
+}
+
+// Status renders the status update of a thread as a single line, posted as a reply to the root of the thread.
+func Status(n *kernel.Notification) []slack.Block {
+	return []slack.Block{slack.NewContextBlock("", markdown(fmt.Sprintf("%s *Status:* %s", emojis[n.Severity], n.Status)))}
+}
+
+// Dismissed renders the message once dismissed by the user. The actions are dropped, and the user who dismissed the
+// message is shown in their place.
+func Dismissed(original []slack.Block, user string) []slack.Block {
+	blocks := make([]slack.Block, 0, len(original)+1)
+
+	for _, block := range original {
+		if block.BlockType() != slack.MBTAction {
+			blocks = append(blocks, block)
+		}
+	}
+
+	return append(blocks, slack.NewContextBlock("", markdown(fmt.Sprintf(":no_bell: Dismissed by <@%s>", user))))
+}
+
+// Fallback returns the plain text used by slack for notifications and clients unable to render blocks.
+func Fallback(n *kernel.Notification) string {
+	return n.Title
+}
+
+// Actions renders the notification actions as buttons.
+func Actions(actions []kernel.NotificationAction) *slack.ActionBlock {
+	elements := make([]slack.BlockElement, 0, len(actions))
+
+	for _, action := range actions {
+		button := slack.NewButtonBlockElement(action.ID, action.Value, slack.NewTextBlockObject(slack.PlainTextType, action.Text, true, false))
+		button.WithStyle(slack.Style(action.Style))
+
+		elements = append(elements, button)
+	}
+
+	return slack.NewActionBlock("", elements...)
+}
+
+// header prefixes the title with the emoji for the severity.
+func header(n *kernel.Notification) string {
+	if emoji, ok := emojis[n.Severity]; ok {
+		return fmt.Sprintf("%s %s", emoji, n.Title)
+	}
+
+	return n.Title
+}
+
+// fields renders short fields side by side, and each long field as its own section. Fields without values are
+// skipped since slack does not allow empty text, and fields too long for slack are truncated.
+func fields(items []kernel.NotificationField) []slack.Block {
+	blocks := make([]slack.Block, 0)
+	short := make([]*slack.TextBlockObject, 0)
+
+	flush := func() {
+		if len(short) > 0 {
+			blocks = append(blocks, slack.NewSectionBlock(nil, short, nil))
+			short = make([]*slack.TextBlockObject, 0)
+		}
+	}
+
+	for _, field := range items {
+		if field.Value == "" {
+			continue
+		}
+
+		text := fmt.Sprintf("*%s*\n%s", field.Title, field.Value)
+
+		if !field.Short {
+			flush()
+
+			blocks = append(blocks, slack.NewSectionBlock(markdown(truncate(text, max_section_text)), nil, nil))
+
+			continue
+		}
+
+		short = append(short, markdown(truncate(text, max_field_text)))
+
+		if len(short) == max_section_fields {
+			flush()
+		}
+	}
+
+	flush()
+
+	return blocks
+}
+
+// truncate cuts the text to the limit in bytes, which is never less than the characters counted by slack. Whole lines are
+// kept where possible, followed by the number of lines cut, e.g. the files of a large diff end with "…and 42 more".
+func truncate(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+
+	lines := strings.Split(text, "\n")
+	reserve := len(fmt.Sprintf("\n…and %d more", len(lines)))
+	size, kept := 0, 0
+
+	for _, line := range lines {
+		if size+len(line)+1+reserve > limit {
+			break
+		}
+
+		size += len(line) + 1
+		kept++
+	}
+
+	if kept > 0 {
+		return fmt.Sprintf("%s\n…and %d more", strings.Join(lines[:kept], "\n"), len(lines)-kept)
+	}
+
+	// a single line longer than the limit is cut on a rune boundary.
+	end := limit - len("…")
+	for end > 0 && !utf8.RuneStart(text[end]) {
+		end--
+	}
+
+	return text[:end] + "…"
+}
+
+// context renders the links along with the footer. The links that don't fit in the context block along with the footer
+// are left out.
+func context(links []kernel.NotificationLink) *slack.ContextBlock {
+	links = links[:min(len(links), max_context_elements-1)]
+	elements := make([]slack.MixedElement, 0, len(links)+1)
+
+	for _, link := range links {
+		elements = append(elements, markdown(fmt.Sprintf("<%s|%s>", link.URL, link.Text)))
+	}
+
+	elements = append(elements, markdown(footer))
+
+	return slack.NewContextBlock("", elements...)
+}
+
+func markdown(text string) *slack.TextBlockObject {
+	return slack.NewTextBlockObject(slack.MarkdownType, text, false, false)
+}
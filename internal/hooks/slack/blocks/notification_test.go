@@ -0,0 +1,116 @@
+package blocks
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+
+	"go.breu.io/quantm/internal/core/kernel"
+)
+
+func TestTruncate(t *testing.T) {
+	t.Parallel()
+
+	files := make([]string, 500)
+	for idx := range files {
+		files[idx] = fmt.Sprintf("• `internal/core/repos/file_%03d.go`", idx)
+	}
+
+	tests := []struct {
+		name   string
+		text   string
+		limit  int
+		suffix string
+	}{
+		{"short", "a\nb", 10, "a\nb"},
+		{"lines", strings.Join(files, "\n"), max_section_text, "more"},
+		{"single line", strings.Repeat("é", 2000), max_field_text, "…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := truncate(tt.text, tt.limit)
+
+			assert.LessOrEqual(t, len(result), tt.limit)
+			assert.True(t, strings.HasSuffix(result, tt.suffix), "unexpected suffix: %q", result[max(0, len(result)-40):])
+			assert.True(t, strings.HasPrefix(tt.text, strings.TrimSuffix(strings.SplitN(result, "\n…and", 2)[0], "…")))
+		})
+	}
+}
+
+func TestNotification_LongFields(t *testing.T) {
+	t.Parallel()
+
+	files := strings.Repeat("• `internal/core/repos/states/branch.go`\n", 200)
+	n := &kernel.Notification{
+		Title:  "lines exceeded",
+		Body:   files,
+		Fields: []kernel.NotificationField{{Title: "Files", Value: files}, {Title: "Short", Value: files, Short: true}},
+	}
+
+	for _, block := range Notification(n) {
+		section, ok := block.(*slack.SectionBlock)
+		if !ok {
+			continue
+		}
+
+		if section.Text != nil {
+			assert.LessOrEqual(t, len(section.Text.Text), max_section_text)
+		}
+
+		for _, field := range section.Fields {
+			assert.LessOrEqual(t, len(field.Text), max_field_text)
+		}
+	}
+}
+
+func TestDismissed(t *testing.T) {
+	t.Parallel()
+
+	n := kernel.NewNotification(kernel.NotificationKindMergeConflict, kernel.SeverityWarning, kernel.Recipient{}, "title", "body").
+		AddAction("dismiss", "Dismiss", "{}", "")
+
+	dismissed := Dismissed(Notification(n), "U123")
+
+	for _, block := range dismissed {
+		assert.NotEqual(t, slack.MBTAction, block.BlockType(), "actions must be dropped")
+	}
+
+	if last, ok := dismissed[len(dismissed)-1].(*slack.ContextBlock); assert.True(t, ok) {
+		assert.Contains(t, last.ContextElements.Elements[0].(*slack.TextBlockObject).Text, "<@U123>")
+	}
+}
+
+func TestNotification_Limits(t *testing.T) {
+	t.Parallel()
+
+	n := kernel.NewNotification(
+		kernel.NotificationKindMergeConflict, kernel.SeverityWarning, kernel.Recipient{}, strings.Repeat("conflict ", 40), "body",
+	)
+
+	for idx := range 15 {
+		n.AddLink(fmt.Sprintf("link %d", idx), fmt.Sprintf("https://github.com/breuhq/quantm/pull/%d", idx))
+	}
+
+	rendered := Notification(n)
+
+	if header, ok := rendered[0].(*slack.HeaderBlock); assert.True(t, ok) {
+		assert.LessOrEqual(t, utf8.RuneCountInString(header.Text.Text), max_header_text)
+		assert.True(t, strings.HasSuffix(header.Text.Text, "…"))
+	}
+
+	for _, block := range rendered {
+		if ctx, ok := block.(*slack.ContextBlock); ok {
+			assert.LessOrEqual(t, len(ctx.ContextElements.Elements), max_context_elements)
+		}
+	}
+
+	last, ok := rendered[len(rendered)-1].(*slack.ContextBlock)
+	if assert.True(t, ok) {
+		assert.Equal(t, footer, last.ContextElements.Elements[len(last.ContextElements.Elements)-1].(*slack.TextBlockObject).Text)
+	}
+}
@@ -2,10 +2,13 @@ package config
 
 import (
 	"log/slog"
+	"net/http"
 	"sync"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/slack-go/slack"
+
+	pkgerrors "go.breu.io/quantm/internal/hooks/slack/errors"
 )
 
 var (
@@ -16,10 +19,11 @@ var (
 // Config holds the configuration for the Slack client.
 type (
 	Config struct {
-		ClientID     string `koanf:"CLIENT_ID" validate:"required"`
-		ClientSecret string `koanf:"CLIENT_SECRET" validate:"required"`
-		RedirectURL  string `koanf:"REDIRECT_URL" validate:"required"`
-		Debug        bool   `koanf:"DEBUG"`
+		ClientID      string `koanf:"CLIENT_ID" validate:"required"`
+		ClientSecret  string `koanf:"CLIENT_SECRET" validate:"required"`
+		RedirectURL   string `koanf:"REDIRECT_URL" validate:"required"`
+		SigningSecret string `koanf:"SIGNING_SECRET"` // SigningSecret verifies the requests sent by slack.
+		Debug         bool   `koanf:"DEBUG"`
 	}
 
 	ConfigOption func(*Config)
@@ -42,6 +46,25 @@ func GetSlackClient(token string) (*slack.Client, error) {
 	return client, nil
 }
 
+// VerifyRequest verifies the signature of a request sent by slack, i.e. interactions and slash commands, against the
+// signing secret. The timestamp of the request is checked as well, to protect against replay attacks.
+func VerifyRequest(header http.Header, body []byte) error {
+	if _c.SigningSecret == "" {
+		return pkgerrors.ErrSigningSecretEmpty
+	}
+
+	verifier, err := slack.NewSecretsVerifier(header, _c.SigningSecret)
+	if err != nil {
+		return err
+	}
+
+	if _, err := verifier.Write(body); err != nil {
+		return err
+	}
+
+	return verifier.Ensure()
+}
+
 func ClientID() string {
 	return _c.ClientID
 }
@@ -59,6 +82,8 @@ func WithConfig(cfg *Config) ConfigOption {
 		config.ClientID = cfg.ClientID
 		config.ClientSecret = cfg.ClientSecret
 		config.RedirectURL = cfg.RedirectURL
+		config.SigningSecret = cfg.SigningSecret
+		config.Debug = cfg.Debug
 	}
 }
 
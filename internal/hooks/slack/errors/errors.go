@@ -8,4 +8,6 @@ var (
 	ErrCodeEmpty   = errors.New("code is empty")
 	ErrCipherText  = errors.New("ciphertext too short")
 	ErrRecordExist = errors.New("record exist already")
+
+	ErrSigningSecretEmpty = errors.New("signing secret is empty")
 )
@@ -20,3 +20,50 @@ func SendMessage(client *slack.Client, channelID string, attachment slack.Attach
 
 	return nil
 }
+
+// SendBlocks sends a Block Kit message to the channel. The fallback text is shown in notifications and by clients
+// that are unable to render blocks.
+func SendBlocks(client *slack.Client, channelID, fallback string, blocks ...slack.Block) error {
+	_, _, err := PostBlocks(client, channelID, "", fallback, blocks...)
+	return err
+}
+
+// PostBlocks sends a Block Kit message to the channel, replying in the thread if thread_ts is set. It returns the
+// channel and the timestamp of the posted message, which together identify the message for later replies and updates.
+//
+// NOTE: when messaging a user, the returned channel is the ID of the direct message channel and not the user ID.
+func PostBlocks(client *slack.Client, channelID, thread_ts, fallback string, blocks ...slack.Block) (string, string, error) {
+	opts := []slack.MsgOption{
+		slack.MsgOptionText(fallback, false),
+		slack.MsgOptionBlocks(blocks...),
+		slack.MsgOptionAsUser(true),
+	}
+
+	if thread_ts != "" {
+		opts = append(opts, slack.MsgOptionTS(thread_ts))
+	}
+
+	channel, ts, err := client.PostMessage(channelID, opts...)
+	if err != nil {
+		slog.Error("Error sending blocks to channel ", ": ", slog.Any("e", err))
+		return "", "", err
+	}
+
+	return channel, ts, nil
+}
+
+// UpdateBlocks replaces the message identified by the channel and the timestamp.
+func UpdateBlocks(client *slack.Client, channelID, ts, fallback string, blocks ...slack.Block) error {
+	_, _, _, err := client.UpdateMessage(
+		channelID,
+		ts,
+		slack.MsgOptionText(fallback, false),
+		slack.MsgOptionBlocks(blocks...),
+	)
+	if err != nil {
+		slog.Error("Error updating message in channel ", ": ", slog.Any("e", err))
+		return err
+	}
+
+	return nil
+}
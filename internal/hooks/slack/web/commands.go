@@ -0,0 +1,316 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/slack-go/slack"
+	"go.temporal.io/api/serviceerror"
+
+	"go.breu.io/quantm/internal/core/repos"
+	"go.breu.io/quantm/internal/db"
+	"go.breu.io/quantm/internal/db/entities"
+	"go.breu.io/quantm/internal/durable"
+	"go.breu.io/quantm/internal/erratic"
+	"go.breu.io/quantm/internal/hooks/slack/blocks"
+	eventsv1 "go.breu.io/quantm/internal/proto/ctrlplane/events/v1"
+)
+
+type (
+	// CommandHandler handles a subcommand of the slash command. args are the arguments after the subcommand.
+	CommandHandler func(ctx context.Context, cmd *slack.SlashCommand, user *entities.User, args []string) ([]slack.Block, error)
+
+	// CommandHandlers is a map of subcommands to their handlers.
+	CommandHandlers map[string]CommandHandler
+)
+
+const (
+	usage = "*Usage*\n" +
+		"`/quantm queue <repo>` shows the merge queue of the repo.\n" +
+		"`/quantm status <branch> [repo]` shows the status of the branch. The repo defaults to the one linked with the channel.\n" +
+		"`/quantm freeze <repo>` freezes the merge queue of the repo, `/quantm unfreeze <repo>` resumes it.\n" +
+		"`/quantm link` shows the quantm account linked with your slack account."
+
+	unlinked = "Your slack account is not linked with quantm yet. Connect slack from your quantm settings and try again."
+)
+
+// Commands handles the slash commands. Replies are ephemeral, i.e. only visible to the user who ran the command.
+func (h *Webhook) Commands(ctx echo.Context) error {
+	if _, err := h.verify(ctx); err != nil {
+		return err
+	}
+
+	cmd, err := slack.SlashCommandParse(ctx.Request())
+	if err != nil {
+		return erratic.NewBadRequestError(erratic.HooksSlackModule).WithReason("invalid payload").Wrap(err)
+	}
+
+	args := strings.Fields(cmd.Text)
+	if len(args) == 0 {
+		return h.reply(ctx, "usage", blocks.Text(usage))
+	}
+
+	fn, found := h.command(args[0])
+	if !found {
+		return h.reply(ctx, "usage", blocks.Text(usage))
+	}
+
+	user, err := h.user(ctx.Request().Context(), &cmd)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		return h.reply(ctx, unlinked, blocks.Text(unlinked))
+	}
+
+	reply, err := fn(ctx.Request().Context(), &cmd, user, args[1:])
+	if err != nil {
+		return err
+	}
+
+	return h.reply(ctx, cmd.Text, reply)
+}
+
+// command returns the handler for the subcommand.
+func (h *Webhook) command(name string) (CommandHandler, bool) {
+	handlers := CommandHandlers{
+		"queue":    h.queue,
+		"status":   h.status,
+		"freeze":   h.freeze,
+		"unfreeze": h.freeze,
+		"link":     h.link,
+	}
+
+	fn, ok := handlers[name]
+
+	return fn, ok
+}
+
+// queue replies with the merge queue of the repo.
+func (h *Webhook) queue(ctx context.Context, _ *slack.SlashCommand, user *entities.User, args []string) ([]slack.Block, error) {
+	if len(args) != 1 {
+		return blocks.Text(usage), nil
+	}
+
+	repo, err := h.repo_by_name(ctx, user, args[0])
+	if err != nil || repo == nil {
+		return h.not_found(args[0], err)
+	}
+
+	queue, err := h.query_queue(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	return blocks.Queue(repo.Name, queue), nil
+}
+
+// status replies with the status of the branch. The repo is either given, or the one linked with the channel.
+func (h *Webhook) status(ctx context.Context, cmd *slack.SlashCommand, user *entities.User, args []string) ([]slack.Block, error) {
+	if len(args) == 0 || len(args) > 2 {
+		return blocks.Text(usage), nil
+	}
+
+	var (
+		repo *entities.Repo
+		err  error
+	)
+
+	if len(args) == 2 {
+		repo, err = h.repo_by_name(ctx, user, args[1])
+	} else {
+		repo, err = h.repo_by_channel(ctx, user, cmd.ChannelID)
+	}
+
+	if err != nil {
+		return nil, erratic.NewSystemError(erratic.HooksSlackModule).Wrap(err)
+	}
+
+	if repo == nil {
+		return blocks.Text("Unable to find the repo. Pass the repo, or run the command in the channel linked with it."), nil
+	}
+
+	branch := args[0]
+
+	// The repo keeps track of the branches with a trigger. Branches without one don't have a workflow.
+	if _, err := durable.OnCore().QueryWorkflow(ctx, repos.RepoWorkflowOptions(repo), repos.QueryRepoForEventParent, branch); err != nil {
+		return blocks.Text(fmt.Sprintf("quantm is not tracking `%s` on %s.", branch, repo.Name)), nil
+	}
+
+	result, err := durable.OnCore().QueryWorkflow(ctx, repos.BranchWorkflowOptions(repo, branch), repos.QueryBranchForStatus)
+	if err != nil {
+		return nil, erratic.NewSystemError(erratic.HooksSlackModule).Wrap(err)
+	}
+
+	status := &repos.BranchStatus{}
+	if err := result.Get(status); err != nil {
+		return nil, erratic.NewSystemError(erratic.HooksSlackModule).Wrap(err)
+	}
+
+	queue, err := h.query_queue(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	return blocks.BranchStatus(repo.Name, status, queue), nil
+}
+
+// freeze freezes or unfreezes the merge queue of the repo.
+func (h *Webhook) freeze(ctx context.Context, cmd *slack.SlashCommand, user *entities.User, args []string) ([]slack.Block, error) {
+	if len(args) != 1 {
+		return blocks.Text(usage), nil
+	}
+
+	repo, err := h.repo_by_name(ctx, user, args[0])
+	if err != nil || repo == nil {
+		return h.not_found(args[0], err)
+	}
+
+	frozen := strings.Fields(cmd.Text)[0] == "freeze"
+	payload := &repos.QueueFreezePayload{Frozen: frozen, User: cmd.UserID}
+
+	link, err := db.Queries().GetChatLink(ctx, repo.ID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, erratic.NewSystemError(erratic.HooksSlackModule).Wrap(err)
+	}
+
+	_, err = durable.OnCore().SignalWithStartWorkflow(
+		ctx,
+		repos.TrunkWorkflowOptions(repo),
+		repos.SignalQueueFreeze,
+		payload,
+		repos.TrunkWorkflow,
+		repos.NewTrunkWorkflowState(repo, &link),
+	)
+	if err != nil {
+		slog.Error("slack: unable to signal trunk", "repo", repo.ID, "error", err.Error())
+		return nil, erratic.NewSystemError(erratic.HooksSlackModule).Wrap(err)
+	}
+
+	if frozen {
+		return blocks.Text(fmt.Sprintf("The merge queue for %s is frozen.", repo.Name)), nil
+	}
+
+	return blocks.Text(fmt.Sprintf("The merge queue for %s is resumed.", repo.Name)), nil
+}
+
+// link replies with the quantm account linked with the slack user.
+func (h *Webhook) link(_ context.Context, _ *slack.SlashCommand, user *entities.User, _ []string) ([]slack.Block, error) {
+	return blocks.Text(fmt.Sprintf("Your slack account is linked with the quantm account *%s*.", user.Email)), nil
+}
+
+// user maps the slack user to the quantm user with the chat link. Returns nil if the user is not linked.
+func (h *Webhook) user(ctx context.Context, cmd *slack.SlashCommand) (*entities.User, error) {
+	link, err := db.Queries().GetChatLinkByProviderUser(ctx, entities.GetChatLinkByProviderUserParams{
+		Hook:           int32(eventsv1.ChatHook_CHAT_HOOK_SLACK),
+		ProviderUserID: cmd.UserID,
+		ProviderTeamID: cmd.TeamID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, erratic.NewSystemError(erratic.HooksSlackModule).Wrap(err)
+	}
+
+	user, err := db.Queries().GetUserByID(ctx, link.LinkTo)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, erratic.NewSystemError(erratic.HooksSlackModule).Wrap(err)
+	}
+
+	return &user, nil
+}
+
+// repo_by_name returns the repo with the given name in the org of the user. Returns nil if not found.
+func (h *Webhook) repo_by_name(ctx context.Context, user *entities.User, name string) (*entities.Repo, error) {
+	repo, err := db.Queries().GetRepoByOrgIDAndName(ctx, entities.GetRepoByOrgIDAndNameParams{OrgID: user.OrgID, Name: name})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &repo, nil
+}
+
+// repo_by_channel returns the repo linked with the channel, if it belongs to the org of the user. Returns nil if not
+// found.
+func (h *Webhook) repo_by_channel(ctx context.Context, user *entities.User, channel string) (*entities.Repo, error) {
+	link, err := db.Queries().GetChatLinkByChannel(ctx, entities.GetChatLinkByChannelParams{
+		Hook:      int32(eventsv1.ChatHook_CHAT_HOOK_SLACK),
+		ChannelID: channel,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := db.Queries().GetRepo(ctx, link.LinkTo)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && repo.OrgID != user.OrgID) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &repo, nil
+}
+
+// query_queue queries the trunk for the merge queue. A trunk that hasn't started yet has an empty queue.
+func (h *Webhook) query_queue(ctx context.Context, repo *entities.Repo) (*repos.QueueStatus, error) {
+	queue := &repos.QueueStatus{}
+
+	result, err := durable.OnCore().QueryWorkflow(ctx, repos.TrunkWorkflowOptions(repo), repos.QueryTrunkForQueue)
+	if err != nil {
+		var nf *serviceerror.NotFound
+		if errors.As(err, &nf) {
+			return queue, nil
+		}
+
+		return nil, erratic.NewSystemError(erratic.HooksSlackModule).Wrap(err)
+	}
+
+	if err := result.Get(queue); err != nil {
+		return nil, erratic.NewSystemError(erratic.HooksSlackModule).Wrap(err)
+	}
+
+	return queue, nil
+}
+
+// not_found replies that the repo was not found, unless there was an error looking it up.
+func (h *Webhook) not_found(name string, err error) ([]slack.Block, error) {
+	if err != nil {
+		return nil, erratic.NewSystemError(erratic.HooksSlackModule).Wrap(err)
+	}
+
+	return blocks.Text(fmt.Sprintf("Unable to find the repo `%s`.", name)), nil
+}
+
+// reply sends the ephemeral reply to the command.
+func (h *Webhook) reply(ctx echo.Context, fallback string, reply []slack.Block) error {
+	msg := &slack.Msg{
+		ResponseType: slack.ResponseTypeEphemeral,
+		Text:         fallback,
+		Blocks:       slack.Blocks{BlockSet: reply},
+	}
+
+	return ctx.JSON(http.StatusOK, msg)
+}
@@ -0,0 +1,166 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/slack-go/slack"
+
+	"go.breu.io/quantm/internal/core/kernel"
+	"go.breu.io/quantm/internal/core/repos"
+	"go.breu.io/quantm/internal/db"
+	"go.breu.io/quantm/internal/db/entities"
+	"go.breu.io/quantm/internal/durable"
+	"go.breu.io/quantm/internal/erratic"
+	"go.breu.io/quantm/internal/hooks/slack/blocks"
+	"go.breu.io/quantm/internal/hooks/slack/cast"
+	"go.breu.io/quantm/internal/hooks/slack/config"
+	eventsv1 "go.breu.io/quantm/internal/proto/ctrlplane/events/v1"
+)
+
+type (
+	// Webhook receives the requests sent by slack when users interact with quantm messages or run slash commands.
+	//
+	// Interactions are decoded into provider neutral chat actions and signaled to the repo workflow, which routes them
+	// to the branch or the trunk workflow. Commands are answered by querying the repo, branch and trunk workflows.
+	Webhook struct{}
+)
+
+// Interactions handles the interactivity requests, i.e. button clicks on quantm messages. Dismissed messages are
+// replaced without their buttons.
+func (h *Webhook) Interactions(ctx echo.Context) error {
+	body, err := h.verify(ctx)
+	if err != nil {
+		return err
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return erratic.NewBadRequestError(erratic.HooksSlackModule).WithReason("invalid payload").Wrap(err)
+	}
+
+	callback := &slack.InteractionCallback{}
+	if err := json.Unmarshal([]byte(form.Get("payload")), callback); err != nil {
+		return erratic.NewBadRequestError(erratic.HooksSlackModule).WithReason("invalid payload").Wrap(err)
+	}
+
+	// Only block actions are sent by quantm messages, everything else is acknowledged and ignored.
+	if callback.Type != slack.InteractionTypeBlockActions {
+		return ctx.NoContent(http.StatusOK)
+	}
+
+	for _, block := range callback.ActionCallback.BlockActions {
+		action, err := kernel.ParseChatAction(block.ActionID, block.Value)
+		if err != nil {
+			slog.Warn("slack: ignoring unknown action", "action_id", block.ActionID, "error", err.Error())
+			continue
+		}
+
+		action.User = callback.User.ID
+
+		if err := h.signal(ctx.Request().Context(), action, callback.Team.ID); err != nil {
+			return err
+		}
+
+		if action.Kind == kernel.ChatActionDismiss {
+			h.dismiss(ctx.Request().Context(), callback)
+		}
+	}
+
+	return ctx.NoContent(http.StatusOK)
+}
+
+// dismiss replaces the message with its dismissed version, without the actions. The message is kept, as it may be the
+// root of a thread, in which case the thread is marked as dismissed so that the root stays dismissed when its status is
+// updated. Failures are logged, the action is already handled by the workflow.
+func (h *Webhook) dismiss(ctx context.Context, callback *slack.InteractionCallback) {
+	msg := &slack.WebhookMessage{
+		Text:            callback.Message.Text,
+		Blocks:          &slack.Blocks{BlockSet: blocks.Dismissed(callback.Message.Blocks.BlockSet, callback.User.ID)},
+		ReplaceOriginal: true,
+	}
+
+	if err := slack.PostWebhookContext(ctx, callback.ResponseURL, msg); err != nil {
+		slog.Warn("slack: unable to dismiss message", "channel", callback.Channel.ID, "error", err.Error())
+	}
+
+	if err := db.Queries().DismissChatThread(ctx, entities.DismissChatThreadParams{
+		Hook:        int32(eventsv1.ChatHook_CHAT_HOOK_SLACK),
+		ChannelID:   callback.Channel.ID,
+		Ts:          callback.Message.Timestamp,
+		DismissedBy: callback.User.ID,
+	}); err != nil {
+		slog.Warn("slack: unable to dismiss thread", "channel", callback.Channel.ID, "error", err.Error())
+	}
+}
+
+// verify reads the request body, verifies the slack signature and resets the body for subsequent use.
+func (h *Webhook) verify(ctx echo.Context) ([]byte, error) {
+	body, err := io.ReadAll(ctx.Request().Body)
+	if err != nil {
+		return nil, erratic.NewSystemError(erratic.HooksSlackModule).WithReason("failed to read request body").Wrap(err)
+	}
+
+	ctx.Request().Body = io.NopCloser(bytes.NewBuffer(body))
+
+	if err := config.VerifyRequest(ctx.Request().Header, body); err != nil {
+		return nil, erratic.NewAuthzError(erratic.HooksSlackModule).WithReason("invalid request signature").Wrap(err)
+	}
+
+	return body, nil
+}
+
+// signal sends the chat action to the repo workflow, starting it if it doesn't exist. The action must come from the
+// slack workspace the repo is linked with, as the repo ID in the action is only as trusted as the workspace that sent it.
+func (h *Webhook) signal(ctx context.Context, action *kernel.ChatAction, team string) error {
+	repo, err := db.Queries().GetRepo(ctx, action.RepoID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return erratic.NewNotFoundError(erratic.HooksSlackModule).WithReason("repo not found")
+		}
+
+		return erratic.NewSystemError(erratic.HooksSlackModule).Wrap(err)
+	}
+
+	link, err := db.Queries().GetChatLink(ctx, repo.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return erratic.NewAuthzError(erratic.HooksSlackModule).WithReason("repo not linked with slack")
+		}
+
+		return erratic.NewSystemError(erratic.HooksSlackModule).Wrap(err)
+	}
+
+	data, err := cast.ByteToMessageProviderSlackData(link.Data)
+	if err != nil {
+		return erratic.NewSystemError(erratic.HooksSlackModule).Wrap(err)
+	}
+
+	if data.WorkspaceID == "" || data.WorkspaceID != team {
+		slog.Warn("slack: action from another workspace", "repo", repo.ID, "team", team, "action", action.Kind)
+		return erratic.NewAuthzError(erratic.HooksSlackModule).WithReason("workspace not linked with repo")
+	}
+
+	_, err = durable.OnCore().SignalWithStartWorkflow(
+		ctx,
+		repos.RepoWorkflowOptions(&repo),
+		repos.SignalChatAction,
+		action,
+		repos.RepoWorkflow,
+		repos.NewRepoWorkflowState(&repo, &link),
+	)
+	if err != nil {
+		slog.Error("slack: unable to signal repo", "repo", repo.ID, "action", action.Kind, "error", err.Error())
+		return erratic.NewSystemError(erratic.HooksSlackModule).Wrap(err)
+	}
+
+	return nil
+}
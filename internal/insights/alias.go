@@ -0,0 +1,38 @@
+// Package insights provides analytics over the events of an org, e.g. the DORA metrics or the lineage of an event.
+package insights
+
+import (
+	"go.breu.io/quantm/internal/insights/dora"
+	"go.breu.io/quantm/internal/insights/lineage"
+	"go.breu.io/quantm/internal/insights/nomad"
+	"go.breu.io/quantm/internal/insights/queue"
+)
+
+type (
+	// Metrics are the DORA metrics for a time window.
+	Metrics = dora.Metrics
+
+	// Report holds the DORA metrics per window along with the metrics over the whole time range.
+	Report = dora.Report
+
+	// Lineage is the DAG of the ancestors and the descendants of an event.
+	Lineage = lineage.Graph
+
+	// QueueMetrics are the merge queue metrics of a repo.
+	QueueMetrics = queue.Metrics
+)
+
+var (
+	// ComputeDORA computes the DORA metrics from the events of an org.
+	ComputeDORA = dora.Compute
+
+	// GetLineage returns the lineage of an event.
+	GetLineage = lineage.Get
+
+	// ComputeQueue computes the merge queue metrics of a repo.
+	ComputeQueue = queue.Compute
+)
+
+var (
+	NomadHandler = nomad.NewInsightsServiceHandler
+)
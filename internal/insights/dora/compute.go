@@ -0,0 +1,156 @@
+package dora
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"go.breu.io/quantm/internal/core/repos/fns"
+	"go.breu.io/quantm/internal/events"
+	eventsv1 "go.breu.io/quantm/internal/proto/ctrlplane/events/v1"
+	"go.breu.io/quantm/internal/pulse"
+)
+
+type (
+	// deployment is a push to the default branch along with the first line of its commit messages.
+	deployment struct {
+		repo      uuid.UUID
+		timestamp time.Time
+		messages  []string
+	}
+)
+
+const (
+	revert = `Revert "`
+)
+
+var (
+	unmarshal = protojson.UnmarshalOptions{DiscardUnknown: true}
+)
+
+// Compute computes the DORA metrics between start and end, bucketed by interval. The records must be ordered by
+// timestamp, and should go back Lookback before start.
+func Compute(records []pulse.Record, branches Branches, start, end time.Time, interval Interval) *Report {
+	report := NewReport(start, end, interval)
+	index := make(map[uuid.UUID]*pulse.Record, len(records))
+	deployments := make([]*deployment, 0)
+
+	for idx := range records {
+		index[records[idx].ID] = &records[idx]
+	}
+
+	for idx := range records {
+		record := &records[idx]
+
+		branch, ok := branches[record.SubjectID]
+		if !ok {
+			continue
+		}
+
+		switch {
+		case record.Scope == events.ScopePush:
+			push := &eventsv1.Push{}
+			if err := unmarshal.Unmarshal([]byte(record.Payload), push); err != nil || push.GetRef() != fns.BranchNameToRef(branch) {
+				continue
+			}
+
+			current := &deployment{repo: record.SubjectID, timestamp: record.Timestamp, messages: subjects(push)}
+			report.deployment(current.timestamp)
+
+			for _, message := range current.messages {
+				if !strings.HasPrefix(message, revert) {
+					continue
+				}
+
+				reverted := find(deployments, current.repo, strings.TrimSuffix(strings.TrimPrefix(message, revert), `"`))
+				if reverted == nil {
+					report.failure(current.timestamp, 0, false)
+					continue
+				}
+
+				report.failure(reverted.timestamp, current.timestamp.Sub(reverted.timestamp), true)
+			}
+
+			deployments = append(deployments, current)
+
+		case record.Scope == events.ScopePr && record.Action == events.ActionMerged:
+			pr := &eventsv1.PullRequest{}
+			if err := unmarshal.Unmarshal([]byte(record.Payload), pr); err != nil || pr.GetBaseBranch() != branch {
+				continue
+			}
+
+			first, found := first_push(index, record)
+			report.change(record.Timestamp, record.Timestamp.Sub(first), found)
+		}
+	}
+
+	report.finalize()
+
+	return report
+}
+
+// first_push walks up the lineage of the record and returns the timestamp of the earliest push.
+func first_push(index map[uuid.UUID]*pulse.Record, record *pulse.Record) (time.Time, bool) {
+	var (
+		first time.Time
+		found bool
+	)
+
+	visited := map[uuid.UUID]bool{record.ID: true}
+	queue := append([]uuid.UUID{}, record.Parents...)
+
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		if visited[id] {
+			continue
+		}
+
+		visited[id] = true
+
+		parent, ok := index[id]
+		if !ok {
+			continue
+		}
+
+		if parent.Scope == events.ScopePush && (!found || parent.Timestamp.Before(first)) {
+			first, found = parent.Timestamp, true
+		}
+
+		queue = append(queue, parent.Parents...)
+	}
+
+	return first, found
+}
+
+// find returns the latest deployment of the repo with a commit with the given message.
+func find(deployments []*deployment, repo uuid.UUID, message string) *deployment {
+	for idx := len(deployments) - 1; idx >= 0; idx-- {
+		if deployments[idx].repo != repo {
+			continue
+		}
+
+		for _, m := range deployments[idx].messages {
+			if m == message {
+				return deployments[idx]
+			}
+		}
+	}
+
+	return nil
+}
+
+// subjects returns the first line of the commit messages of the push.
+func subjects(push *eventsv1.Push) []string {
+	messages := make([]string, 0, len(push.GetCommits()))
+
+	for _, commit := range push.GetCommits() {
+		line, _, _ := strings.Cut(commit.GetMessage(), "\n")
+		messages = append(messages, strings.TrimSpace(line))
+	}
+
+	return messages
+}
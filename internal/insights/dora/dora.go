@@ -0,0 +1,162 @@
+// Package dora computes the DORA metrics from the events of an org.
+//
+// The events don't carry deployments as such, so the metrics are derived as follows:
+//
+//   - A deployment is a push to the default branch of the repo.
+//   - A change is a pull request merged into the default branch, pull requests closed without merging are left out. The
+//     lead time of a change is the time from the first push on the branch, found by walking up the lineage of the pull
+//     request event, to the change.
+//   - A failure is a deployment that was reverted later on, i.e. a later deployment has a commit with the message
+//     `Revert "<message>"`. The time to restore is the time from the failed deployment to the revert.
+package dora
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"go.breu.io/quantm/internal/insights/stats"
+)
+
+type (
+	// Interval is the length of the windows the metrics are bucketed into.
+	Interval string
+
+	// Metrics are the DORA metrics for a time window.
+	Metrics struct {
+		Start         time.Time     `json:"start"`           // Start is the start of the window, inclusive.
+		End           time.Time     `json:"end"`             // End is the end of the window, exclusive.
+		Deployments   int64         `json:"deployments"`     // Deployments is the number of deployments.
+		Frequency     float64       `json:"frequency"`       // Frequency is the number of deployments per day.
+		Changes       int64         `json:"changes"`         // Changes is the number of changes.
+		LeadTime      time.Duration `json:"lead_time"`       // LeadTime is the median lead time of the changes.
+		LeadTimeP95   time.Duration `json:"lead_time_p95"`   // LeadTimeP95 is the 95th percentile lead time of the changes.
+		Failures      int64         `json:"failures"`        // Failures is the number of reverted deployments.
+		FailureRate   float64       `json:"failure_rate"`    // FailureRate is the ratio of failures to deployments.
+		TimeToRestore time.Duration `json:"time_to_restore"` // TimeToRestore is the median time to revert a failure.
+
+		leads    []time.Duration
+		restores []time.Duration
+	}
+
+	// Report holds the metrics per window along with the metrics over the whole time range.
+	Report struct {
+		Windows []*Metrics `json:"windows"`
+		Total   *Metrics   `json:"total"`
+	}
+
+	// Branches maps the repo ID to its default branch.
+	Branches map[uuid.UUID]string
+)
+
+const (
+	IntervalDay   Interval = "day"   // IntervalDay buckets the metrics per day.
+	IntervalWeek  Interval = "week"  // IntervalWeek buckets the metrics per week.
+	IntervalMonth Interval = "month" // IntervalMonth buckets the metrics per month.
+)
+
+const (
+	// Lookback is how far before the start of the time range the events are read, so that the first push of a change
+	// and the deployment reverted by a failure are found even if they happened before the time range.
+	Lookback = 30 * 24 * time.Hour
+)
+
+// next returns the start of the window after the one starting at t.
+func (i Interval) next(t time.Time) time.Time {
+	switch i {
+	case IntervalDay:
+		return t.AddDate(0, 0, 1)
+	case IntervalMonth:
+		return t.AddDate(0, 1, 0)
+	case IntervalWeek:
+		return t.AddDate(0, 0, 7)
+	}
+
+	return t.AddDate(0, 0, 7)
+}
+
+// NewReport creates an empty report with the windows of the interval between start and end.
+func NewReport(start, end time.Time, interval Interval) *Report {
+	report := &Report{Windows: make([]*Metrics, 0), Total: &Metrics{Start: start, End: end}}
+
+	for from := start; from.Before(end); {
+		to := interval.next(from)
+		if to.After(end) {
+			to = end
+		}
+
+		report.Windows = append(report.Windows, &Metrics{Start: from, End: to})
+		from = to
+	}
+
+	return report
+}
+
+// window returns the metrics of the window containing t, or nil if t is outside the time range.
+func (r *Report) window(t time.Time) *Metrics {
+	idx := sort.Search(len(r.Windows), func(i int) bool { return r.Windows[i].End.After(t) })
+	if idx == len(r.Windows) || t.Before(r.Windows[idx].Start) {
+		return nil
+	}
+
+	return r.Windows[idx]
+}
+
+// deployment records a deployment at t.
+func (r *Report) deployment(t time.Time) {
+	if w := r.window(t); w != nil {
+		w.Deployments++
+		r.Total.Deployments++
+	}
+}
+
+// change records a change at t. lead is the lead time of the change, if known.
+func (r *Report) change(t time.Time, lead time.Duration, known bool) {
+	w := r.window(t)
+	if w == nil {
+		return
+	}
+
+	for _, m := range []*Metrics{w, r.Total} {
+		m.Changes++
+
+		if known {
+			m.leads = append(m.leads, lead)
+		}
+	}
+}
+
+// failure records a failed deployment at t. restore is the time to restore, if known.
+func (r *Report) failure(t time.Time, restore time.Duration, known bool) {
+	w := r.window(t)
+	if w == nil {
+		return
+	}
+
+	for _, m := range []*Metrics{w, r.Total} {
+		m.Failures++
+
+		if known {
+			m.restores = append(m.restores, restore)
+		}
+	}
+}
+
+// finalize computes the derived metrics of the windows and the total.
+func (r *Report) finalize() {
+	for _, m := range append(r.Windows, r.Total) {
+		days := m.End.Sub(m.Start).Hours() / 24
+		if days > 0 {
+			m.Frequency = float64(m.Deployments) / days
+		}
+
+		if m.Deployments > 0 {
+			m.FailureRate = float64(m.Failures) / float64(m.Deployments)
+		}
+
+		m.LeadTime = stats.Percentile(m.leads, 50)
+		m.LeadTimeP95 = stats.Percentile(m.leads, 95)
+		m.TimeToRestore = stats.Percentile(m.restores, 50)
+	}
+}
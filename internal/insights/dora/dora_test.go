@@ -0,0 +1,84 @@
+package dora_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"go.breu.io/quantm/internal/events"
+	"go.breu.io/quantm/internal/insights/dora"
+	"go.breu.io/quantm/internal/pulse"
+)
+
+func TestCompute(t *testing.T) {
+	t.Parallel()
+
+	repo := uuid.New()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 14)
+
+	first := record(repo, events.ScopePush, "", start.Add(time.Hour), `{"ref":"refs/heads/feat"}`)
+	second := record(repo, events.ScopePush, "", start.Add(3*time.Hour), `{"ref":"refs/heads/feat"}`, first.ID)
+	pr := record(repo, events.ScopePr, events.ActionMerged, start.Add(5*time.Hour), `{"base_branch":"main"}`, second.ID)
+	deploy := record(
+		repo, events.ScopePush, "", start.Add(6*time.Hour),
+		`{"ref":"refs/heads/main","commits":[{"message":"add feature\n\nbody"}]}`,
+	)
+	reverted := record(
+		repo, events.ScopePush, "", start.AddDate(0, 0, 8),
+		`{"ref":"refs/heads/main","commits":[{"message":"Revert \"add feature\""}]}`,
+	)
+	ignored := record(uuid.New(), events.ScopePush, "", start.Add(time.Hour), `{"ref":"refs/heads/main"}`)
+
+	records := []pulse.Record{first, ignored, second, pr, deploy, reverted}
+	report := dora.Compute(records, dora.Branches{repo: "main"}, start, end, dora.IntervalWeek)
+
+	if assert.Len(t, report.Windows, 2) {
+		assert.Equal(t, int64(1), report.Windows[0].Deployments)
+		assert.Equal(t, int64(1), report.Windows[0].Changes)
+		assert.Equal(t, 4*time.Hour, report.Windows[0].LeadTime)
+		assert.Equal(t, int64(1), report.Windows[0].Failures)
+		assert.InDelta(t, 1.0, report.Windows[0].FailureRate, 0.0001)
+		assert.Equal(t, 8*24*time.Hour-6*time.Hour, report.Windows[0].TimeToRestore)
+		assert.Equal(t, int64(1), report.Windows[1].Deployments)
+		assert.Equal(t, int64(0), report.Windows[1].Failures)
+	}
+
+	assert.Equal(t, int64(2), report.Total.Deployments)
+	assert.InDelta(t, 2.0/14, report.Total.Frequency, 0.0001)
+	assert.InDelta(t, 0.5, report.Total.FailureRate, 0.0001)
+}
+
+func TestCompute_UnmergedClose(t *testing.T) {
+	t.Parallel()
+
+	repo := uuid.New()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 7)
+
+	push := record(repo, events.ScopePush, "", start.Add(time.Hour), `{"ref":"refs/heads/feat"}`)
+	closed := record(repo, events.ScopePr, events.ActionClosed, start.Add(2*time.Hour), `{"base_branch":"main"}`, push.ID)
+
+	report := dora.Compute([]pulse.Record{push, closed}, dora.Branches{repo: "main"}, start, end, dora.IntervalWeek)
+
+	if assert.Len(t, report.Windows, 1) {
+		assert.Equal(t, int64(0), report.Windows[0].Changes)
+		assert.Equal(t, time.Duration(0), report.Windows[0].LeadTime)
+	}
+
+	assert.Equal(t, int64(0), report.Total.Changes)
+}
+
+func record(repo uuid.UUID, scope events.Scope, action events.Action, ts time.Time, payload string, parents ...uuid.UUID) pulse.Record {
+	return pulse.Record{
+		ID:        uuid.New(),
+		Parents:   parents,
+		Scope:     scope,
+		Action:    action,
+		SubjectID: repo,
+		Timestamp: ts,
+		Payload:   payload,
+	}
+}
@@ -0,0 +1,160 @@
+// Package lineage reads back the chain of events built with the parents of the event context.
+//
+// Every event derived with events.Next carries the ID of the event it was derived from. For example, a push on the
+// default branch leads to a rebase of every branch, a failed rebase to a merge conflict, and the merge conflict to a
+// chat notification. Given any of these events, the lineage is the DAG of all of its ancestors and descendants.
+package lineage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"go.breu.io/quantm/internal/pulse"
+)
+
+type (
+	// Edge links the parent event to the child event.
+	Edge struct {
+		From uuid.UUID `json:"from"`
+		To   uuid.UUID `json:"to"`
+	}
+
+	// Graph is the lineage of the root event.
+	Graph struct {
+		Root  uuid.UUID      `json:"root"`
+		Nodes []pulse.Record `json:"nodes"` // Nodes are the events in the lineage, ordered by timestamp.
+		Edges []Edge         `json:"edges"`
+	}
+
+	// Fetch reads the events of the org matching the filter.
+	Fetch func(ctx context.Context, org uuid.UUID, filter *pulse.Filter) ([]pulse.Record, error)
+)
+
+const (
+	// MaxDepth is the maximum number of generations walked in each direction.
+	MaxDepth = 32
+
+	// MaxNodes is the maximum number of events in the lineage.
+	MaxNodes = 1000
+)
+
+// Get returns the lineage of the event from the events table of the org. Returns nil if the event is not found.
+func Get(ctx context.Context, org, id uuid.UUID) (*Graph, error) {
+	return Walk(ctx, org, id, pulse.Query)
+}
+
+// Walk builds the lineage of the event, reading the events with fetch. The ancestors are walked up with the parents of
+// each generation, the descendants are walked down with the events having any of the generation as parent.
+func Walk(ctx context.Context, org, id uuid.UUID, fetch Fetch) (*Graph, error) {
+	roots, err := fetch(ctx, org, &pulse.Filter{IDs: []uuid.UUID{id}})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(roots) == 0 {
+		return nil, nil
+	}
+
+	nodes := map[uuid.UUID]pulse.Record{id: roots[0]}
+
+	// ancestors
+	generation := roots[0].Parents
+	for depth := 0; depth < MaxDepth && len(generation) > 0 && len(nodes) < MaxNodes; depth++ {
+		records, err := fetch(ctx, org, &pulse.Filter{IDs: generation})
+		if err != nil {
+			return nil, err
+		}
+
+		generation = make([]uuid.UUID, 0)
+
+		for _, record := range records {
+			if _, seen := nodes[record.ID]; seen {
+				continue
+			}
+
+			nodes[record.ID] = record
+			generation = append(generation, record.Parents...)
+		}
+	}
+
+	// descendants
+	generation = []uuid.UUID{id}
+	for depth := 0; depth < MaxDepth && len(generation) > 0 && len(nodes) < MaxNodes; depth++ {
+		records, err := fetch(ctx, org, &pulse.Filter{Parents: generation})
+		if err != nil {
+			return nil, err
+		}
+
+		generation = make([]uuid.UUID, 0)
+
+		for _, record := range records {
+			if _, seen := nodes[record.ID]; seen {
+				continue
+			}
+
+			nodes[record.ID] = record
+			generation = append(generation, record.ID)
+		}
+	}
+
+	return build(id, nodes), nil
+}
+
+// DOT renders the lineage as a graphviz digraph. The root is drawn in bold.
+func (g *Graph) DOT() string {
+	b := &strings.Builder{}
+
+	b.WriteString("digraph lineage {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=rounded, fontname=\"Helvetica\"];\n")
+
+	for _, node := range g.Nodes {
+		label := fmt.Sprintf("%s/%s\\n%s", node.Scope, node.Action, node.Timestamp.UTC().Format("2006-01-02 15:04:05"))
+
+		attrs := fmt.Sprintf("label=\"%s\"", label)
+		if node.ID == g.Root {
+			attrs += ", style=\"rounded,bold\""
+		}
+
+		fmt.Fprintf(b, "  \"%s\" [%s];\n", node.ID, attrs)
+	}
+
+	for _, edge := range g.Edges {
+		fmt.Fprintf(b, "  \"%s\" -> \"%s\";\n", edge.From, edge.To)
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// build orders the nodes by timestamp and links every node to its parents within the lineage.
+func build(root uuid.UUID, nodes map[uuid.UUID]pulse.Record) *Graph {
+	graph := &Graph{Root: root, Nodes: make([]pulse.Record, 0, len(nodes)), Edges: make([]Edge, 0)}
+
+	for _, node := range nodes {
+		graph.Nodes = append(graph.Nodes, node)
+	}
+
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		if graph.Nodes[i].Timestamp.Equal(graph.Nodes[j].Timestamp) {
+			return graph.Nodes[i].ID.String() < graph.Nodes[j].ID.String()
+		}
+
+		return graph.Nodes[i].Timestamp.Before(graph.Nodes[j].Timestamp)
+	})
+
+	for _, node := range graph.Nodes {
+		for _, parent := range node.Parents {
+			if _, ok := nodes[parent]; ok {
+				graph.Edges = append(graph.Edges, Edge{From: parent, To: node.ID})
+			}
+		}
+	}
+
+	return graph
+}
@@ -0,0 +1,80 @@
+package lineage_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"go.breu.io/quantm/internal/events"
+	"go.breu.io/quantm/internal/insights/lineage"
+	"go.breu.io/quantm/internal/pulse"
+)
+
+func TestWalk(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	push := record(events.ScopePush, events.ActionCreated, now)
+	rebase := record(events.ScopeRebase, events.ActionRequested, now.Add(time.Second), push.ID)
+	conflict := record(events.ScopeMerge, events.ActionFailure, now.Add(2*time.Second), rebase.ID)
+	other := record(events.ScopeRebase, events.ActionRequested, now.Add(time.Second), push.ID)
+	unrelated := record(events.ScopePush, events.ActionCreated, now)
+
+	store := []pulse.Record{push, rebase, conflict, other, unrelated}
+
+	graph, err := lineage.Walk(context.Background(), uuid.New(), rebase.ID, fetch(store))
+	if !assert.NoError(t, err) || !assert.NotNil(t, graph) {
+		return
+	}
+
+	ids := make([]uuid.UUID, 0, len(graph.Nodes))
+	for _, node := range graph.Nodes {
+		ids = append(ids, node.ID)
+	}
+
+	assert.Equal(t, []uuid.UUID{push.ID, rebase.ID, conflict.ID}, ids)
+	assert.ElementsMatch(t, []lineage.Edge{{From: push.ID, To: rebase.ID}, {From: rebase.ID, To: conflict.ID}}, graph.Edges)
+
+	dot := graph.DOT()
+	assert.Contains(t, dot, "\""+push.ID.String()+"\" -> \""+rebase.ID.String()+"\";")
+	assert.Contains(t, dot, "label=\"merge/failure\\n2024-01-01 00:00:02\"")
+	assert.NotContains(t, dot, other.ID.String())
+}
+
+func TestWalk_NotFound(t *testing.T) {
+	t.Parallel()
+
+	graph, err := lineage.Walk(context.Background(), uuid.New(), uuid.New(), fetch(nil))
+
+	assert.NoError(t, err)
+	assert.Nil(t, graph)
+}
+
+// fetch returns an in memory fetch over the records, matching the ids and the parents of the filter.
+func fetch(store []pulse.Record) lineage.Fetch {
+	return func(_ context.Context, _ uuid.UUID, filter *pulse.Filter) ([]pulse.Record, error) {
+		result := make([]pulse.Record, 0)
+
+		for _, record := range store {
+			if len(filter.IDs) > 0 && slices.Contains(filter.IDs, record.ID) {
+				result = append(result, record)
+			}
+
+			if len(filter.Parents) > 0 && slices.ContainsFunc(record.Parents, func(id uuid.UUID) bool {
+				return slices.Contains(filter.Parents, id)
+			}) {
+				result = append(result, record)
+			}
+		}
+
+		return result, nil
+	}
+}
+
+func record(scope events.Scope, action events.Action, ts time.Time, parents ...uuid.UUID) pulse.Record {
+	return pulse.Record{ID: uuid.New(), Parents: parents, Scope: scope, Action: action, Timestamp: ts}
+}
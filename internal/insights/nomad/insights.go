@@ -0,0 +1,229 @@
+package nomad
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"go.breu.io/quantm/internal/auth"
+	"go.breu.io/quantm/internal/db"
+	"go.breu.io/quantm/internal/erratic"
+	"go.breu.io/quantm/internal/events"
+	"go.breu.io/quantm/internal/insights/dora"
+	"go.breu.io/quantm/internal/insights/lineage"
+	"go.breu.io/quantm/internal/insights/queue"
+	insightsv1 "go.breu.io/quantm/internal/proto/ctrlplane/insights/v1"
+	"go.breu.io/quantm/internal/proto/ctrlplane/insights/v1/insightsv1connect"
+	"go.breu.io/quantm/internal/pulse"
+)
+
+type (
+	InsightsService struct {
+		insightsv1connect.UnimplementedInsightsServiceHandler
+	}
+)
+
+var (
+	intervals = map[insightsv1.Interval]dora.Interval{
+		insightsv1.Interval_INTERVAL_UNSPECIFIED: dora.IntervalWeek,
+		insightsv1.Interval_INTERVAL_DAY:         dora.IntervalDay,
+		insightsv1.Interval_INTERVAL_WEEK:        dora.IntervalWeek,
+		insightsv1.Interval_INTERVAL_MONTH:       dora.IntervalMonth,
+	}
+)
+
+func (s *InsightsService) GetDORAMetrics(
+	ctx context.Context, req *connect.Request[insightsv1.GetDORAMetricsRequest],
+) (*connect.Response[insightsv1.GetDORAMetricsResponse], error) {
+	_, org_id := auth.NomadAuthContext(ctx)
+
+	start, end, err := timerange(req.Msg.GetStart(), req.Msg.GetEnd())
+	if err != nil {
+		return nil, err
+	}
+
+	interval, ok := intervals[req.Msg.GetInterval()]
+	if !ok {
+		return nil, erratic.NewBadRequestError(erratic.InsightsModule).WithReason("invalid interval")
+	}
+
+	filter := &pulse.Filter{
+		Scopes: []events.Scope{events.ScopePush, events.ScopePr},
+		Start:  start.Add(-dora.Lookback),
+		End:    end,
+	}
+
+	rows, err := db.Queries().ListRepos(ctx, org_id)
+	if err != nil {
+		return nil, erratic.NewDatabaseError(erratic.InsightsModule).Wrap(err)
+	}
+
+	branches := make(dora.Branches, len(rows))
+	for _, row := range rows {
+		branches[row.ID] = row.DefaultBranch
+	}
+
+	switch req.Msg.GetScope() {
+	case insightsv1.Scope_SCOPE_UNSPECIFIED, insightsv1.Scope_SCOPE_ORG:
+	case insightsv1.Scope_SCOPE_TEAM:
+		filter.TeamID, err = uuid.Parse(req.Msg.GetId())
+	case insightsv1.Scope_SCOPE_REPO:
+		filter.SubjectID, err = uuid.Parse(req.Msg.GetId())
+		if _, ok := branches[filter.SubjectID]; err == nil && !ok {
+			return nil, erratic.NewNotFoundError(erratic.InsightsModule, "repo_id", req.Msg.GetId())
+		}
+	}
+
+	if err != nil {
+		return nil, erratic.NewBadRequestError(erratic.InsightsModule, "id", req.Msg.GetId()).WithReason("invalid id").Wrap(err)
+	}
+
+	records, err := pulse.Query(ctx, org_id, filter)
+	if err != nil {
+		return nil, erratic.NewSystemError(erratic.InsightsModule).Wrap(err)
+	}
+
+	report := dora.Compute(records, branches, start, end, interval)
+
+	resp := &insightsv1.GetDORAMetricsResponse{
+		Windows: make([]*insightsv1.DORAMetrics, 0, len(report.Windows)),
+		Total:   metrics(report.Total),
+	}
+
+	for _, window := range report.Windows {
+		resp.Windows = append(resp.Windows, metrics(window))
+	}
+
+	return connect.NewResponse(resp), nil
+}
+
+func (s *InsightsService) GetQueueMetrics(
+	ctx context.Context, req *connect.Request[insightsv1.GetQueueMetricsRequest],
+) (*connect.Response[insightsv1.GetQueueMetricsResponse], error) {
+	_, org_id := auth.NomadAuthContext(ctx)
+
+	start, end, err := timerange(req.Msg.GetStart(), req.Msg.GetEnd())
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := uuid.Parse(req.Msg.GetRepoId())
+	if err != nil {
+		return nil, erratic.NewBadRequestError(erratic.InsightsModule, "repo_id", req.Msg.GetRepoId()).WithReason("invalid id").Wrap(err)
+	}
+
+	repo, err := db.Queries().GetRepo(ctx, id)
+	if err != nil || repo.OrgID != org_id {
+		return nil, erratic.NewNotFoundError(erratic.InsightsModule, "repo_id", req.Msg.GetRepoId())
+	}
+
+	m, err := queue.Compute(ctx, org_id, repo.ID, start, end)
+	if err != nil {
+		return nil, erratic.NewSystemError(erratic.InsightsModule).Wrap(err)
+	}
+
+	return connect.NewResponse(&insightsv1.GetQueueMetricsResponse{
+		Metrics: &insightsv1.QueueMetrics{
+			Start:         timestamppb.New(m.Start),
+			End:           timestamppb.New(m.End),
+			Enqueued:      m.Enqueued,
+			Merged:        m.Merged,
+			Evicted:       m.Evicted,
+			EvictionRate:  m.EvictionRate,
+			MergesPerHour: m.MergesPerHour,
+			WaitTime:      durationpb.New(m.WaitTime),
+			WaitTimeP95:   durationpb.New(m.WaitTimeP95),
+		},
+	}), nil
+}
+
+func (s *InsightsService) GetLineage(
+	ctx context.Context, req *connect.Request[insightsv1.GetLineageRequest],
+) (*connect.Response[insightsv1.GetLineageResponse], error) {
+	_, org_id := auth.NomadAuthContext(ctx)
+
+	id, err := uuid.Parse(req.Msg.GetEventId())
+	if err != nil {
+		return nil, erratic.NewBadRequestError(erratic.InsightsModule, "event_id", req.Msg.GetEventId()).WithReason("invalid id").Wrap(err)
+	}
+
+	graph, err := lineage.Get(ctx, org_id, id)
+	if err != nil {
+		return nil, erratic.NewSystemError(erratic.InsightsModule).Wrap(err)
+	}
+
+	if graph == nil {
+		return nil, erratic.NewNotFoundError(erratic.InsightsModule, "event_id", req.Msg.GetEventId())
+	}
+
+	resp := &insightsv1.GetLineageResponse{
+		Root:  graph.Root.String(),
+		Nodes: make([]*insightsv1.LineageNode, 0, len(graph.Nodes)),
+		Edges: make([]*insightsv1.LineageEdge, 0, len(graph.Edges)),
+		Dot:   graph.DOT(),
+	}
+
+	for _, node := range graph.Nodes {
+		parents := make([]string, 0, len(node.Parents))
+		for _, parent := range node.Parents {
+			parents = append(parents, parent.String())
+		}
+
+		resp.Nodes = append(resp.Nodes, &insightsv1.LineageNode{
+			Id:          node.ID.String(),
+			Parents:     parents,
+			Scope:       node.Scope.String(),
+			Action:      node.Action.String(),
+			SubjectId:   node.SubjectID.String(),
+			SubjectName: node.SubjectName,
+			Timestamp:   timestamppb.New(node.Timestamp),
+			Payload:     node.Payload,
+		})
+	}
+
+	for _, edge := range graph.Edges {
+		resp.Edges = append(resp.Edges, &insightsv1.LineageEdge{From: edge.From.String(), To: edge.To.String()})
+	}
+
+	return connect.NewResponse(resp), nil
+}
+
+func NewInsightsServiceHandler(opts ...connect.HandlerOption) (string, http.Handler) {
+	return insightsv1connect.NewInsightsServiceHandler(&InsightsService{}, opts...)
+}
+
+// timerange validates the time range of the request. The end defaults to now.
+func timerange(from, to *timestamppb.Timestamp) (time.Time, time.Time, error) {
+	start, end := from.AsTime(), time.Now().UTC()
+	if to != nil {
+		end = to.AsTime()
+	}
+
+	if from == nil || !start.Before(end) {
+		return start, end, erratic.NewBadRequestError(erratic.InsightsModule, "start", start.String(), "end", end.String()).
+			WithReason("invalid time range")
+	}
+
+	return start, end, nil
+}
+
+// metrics converts the dora metrics to proto.
+func metrics(m *dora.Metrics) *insightsv1.DORAMetrics {
+	return &insightsv1.DORAMetrics{
+		Start:               timestamppb.New(m.Start),
+		End:                 timestamppb.New(m.End),
+		Deployments:         m.Deployments,
+		DeploymentFrequency: m.Frequency,
+		Changes:             m.Changes,
+		LeadTime:            durationpb.New(m.LeadTime),
+		LeadTimeP95:         durationpb.New(m.LeadTimeP95),
+		Failures:            m.Failures,
+		ChangeFailureRate:   m.FailureRate,
+		TimeToRestore:       durationpb.New(m.TimeToRestore),
+	}
+}
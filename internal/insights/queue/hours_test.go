@@ -0,0 +1,33 @@
+package queue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHours(t *testing.T) {
+	t.Parallel()
+
+	at := func(hour, minute int) time.Time { return time.Date(2024, 1, 1, hour, minute, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+		from, to   time.Time
+	}{
+		{"aligned", at(9, 0), at(11, 0), at(9, 0), at(11, 0)},
+		{"partial", at(9, 30), at(10, 15), at(9, 0), at(11, 0)},
+		{"within an hour", at(9, 10), at(9, 20), at(9, 0), at(10, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to := hours(tt.start, tt.end)
+
+			assert.Equal(t, tt.from, from)
+			assert.Equal(t, tt.to, to)
+		})
+	}
+}
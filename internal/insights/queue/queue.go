@@ -0,0 +1,209 @@
+// Package queue computes the throughput and the wait time of the merge queue from its lifecycle events.
+//
+// The trunk emits a lifecycle event each time a pull request is enqueued, promoted, picked for testing, evicted or
+// merged. On clickhouse, the counts per hour are kept by a materialized view, the wait times are computed from the
+// events. On the other event sinks, the metrics are aggregated from the events.
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"go.breu.io/quantm/internal/events"
+	"go.breu.io/quantm/internal/insights/stats"
+	"go.breu.io/quantm/internal/pulse"
+)
+
+type (
+	// Metrics are the merge queue metrics of a repo for a time range.
+	Metrics struct {
+		Start         time.Time     `json:"start"`           // Start is the start of the time range, inclusive, on the hour.
+		End           time.Time     `json:"end"`             // End is the end of the time range, exclusive, on the hour.
+		Enqueued      int64         `json:"enqueued"`        // Enqueued is the number of pull requests added to the queue.
+		Merged        int64         `json:"merged"`          // Merged is the number of pull requests merged.
+		Evicted       int64         `json:"evicted"`         // Evicted is the number of pull requests evicted.
+		EvictionRate  float64       `json:"eviction_rate"`   // EvictionRate is the ratio of evicted to enqueued.
+		MergesPerHour float64       `json:"merges_per_hour"` // MergesPerHour is the number of merges per hour.
+		WaitTime      time.Duration `json:"wait_time"`       // WaitTime is the median time from enqueued to testing.
+		WaitTimeP95   time.Duration `json:"wait_time_p95"`   // WaitTimeP95 is the 95th percentile wait time.
+	}
+)
+
+const (
+	statement__counts = `
+SELECT action, sum(total)
+FROM %s_queue_hourly
+WHERE subject_id = ? AND hour >= ? AND hour < ?
+GROUP BY action
+`
+
+	// The payload is protojson, where int64 is encoded as string, so the number is compared raw.
+	statement__wait = `
+SELECT
+	quantile(0.5)(wait),
+	quantile(0.95)(wait)
+FROM (
+	SELECT
+		JSONExtractRaw(payload, 'number') AS number,
+		dateDiff('second', minIf(timestamp, has(['enqueued', 'promoted'], action)), minIf(timestamp, action = 'testing')) AS wait
+	FROM %s
+	WHERE subject_id = ? AND scope = 'merge_queue' AND timestamp >= ? AND timestamp < ?
+	GROUP BY number
+	HAVING countIf(has(['enqueued', 'promoted'], action)) > 0 AND countIf(action = 'testing') > 0
+)
+`
+)
+
+// Compute computes the merge queue metrics of the repo between start and end. The counts are kept per hour on
+// clickhouse, so the range is widened to whole hours on every sink, for the metrics not to depend on the sink.
+func Compute(ctx context.Context, org, repo uuid.UUID, start, end time.Time) (*Metrics, error) {
+	start, end = hours(start, end)
+
+	if pulse.Get().Sink != pulse.SinkClickhouse {
+		filter := &pulse.Filter{SubjectID: repo, Scopes: []events.Scope{events.ScopeMergeQueue}, Start: start, End: end}
+
+		records, err := pulse.Query(ctx, org, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		return Aggregate(records, start, end), nil
+	}
+
+	table, err := pulse.EventsTable(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := &Metrics{Start: start, End: end}
+
+	rows, err := pulse.Get().Connection().Query(ctx, fmt.Sprintf(statement__counts, table), repo, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			action string
+			total  uint64
+		)
+
+		if err := rows.Scan(&action, &total); err != nil {
+			return nil, err
+		}
+
+		switch a := events.Action(action); {
+		case a == events.ActionEnqueued || a == events.ActionPromoted:
+			metrics.Enqueued += int64(total) // nolint: gosec
+		case a == events.ActionMerged:
+			metrics.Merged += int64(total) // nolint: gosec
+		case a == events.ActionEvicted:
+			metrics.Evicted += int64(total) // nolint: gosec
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var p50, p95 float64
+	if err := pulse.Get().Connection().QueryRow(ctx, fmt.Sprintf(statement__wait, table), repo, start, end).Scan(&p50, &p95); err != nil {
+		return nil, err
+	}
+
+	// the quantiles are nan if no pull request was picked for testing.
+	if !math.IsNaN(p50) && !math.IsNaN(p95) {
+		metrics.WaitTime = time.Duration(p50 * float64(time.Second))
+		metrics.WaitTimeP95 = time.Duration(p95 * float64(time.Second))
+	}
+
+	metrics.rates()
+
+	return metrics, nil
+}
+
+// Aggregate computes the merge queue metrics from the lifecycle events of the repo between start and end. The wait time
+// of a pull request is the time from its first enqueue to its first test.
+func Aggregate(records []pulse.Record, start, end time.Time) *Metrics {
+	metrics := &Metrics{Start: start, End: end}
+	queued := make(map[string]time.Time)
+	tested := make(map[string]time.Time)
+
+	for _, record := range records {
+		if record.Scope != events.ScopeMergeQueue {
+			continue
+		}
+
+		number := number(record.Payload)
+
+		switch a := record.Action; {
+		case a == events.ActionEnqueued || a == events.ActionPromoted:
+			metrics.Enqueued++
+
+			if _, ok := queued[number]; !ok {
+				queued[number] = record.Timestamp
+			}
+		case a == events.ActionTesting:
+			if _, ok := tested[number]; !ok {
+				tested[number] = record.Timestamp
+			}
+		case a == events.ActionMerged:
+			metrics.Merged++
+		case a == events.ActionEvicted:
+			metrics.Evicted++
+		}
+	}
+
+	waits := make([]time.Duration, 0, len(tested))
+
+	for number, at := range tested {
+		if since, ok := queued[number]; ok {
+			waits = append(waits, at.Sub(since))
+		}
+	}
+
+	metrics.WaitTime = stats.Percentile(waits, 50)
+	metrics.WaitTimeP95 = stats.Percentile(waits, 95)
+	metrics.rates()
+
+	return metrics
+}
+
+// rates computes the eviction rate and the merges per hour from the counts.
+func (m *Metrics) rates() {
+	if m.Enqueued > 0 {
+		m.EvictionRate = float64(m.Evicted) / float64(m.Enqueued)
+	}
+
+	if hours := m.End.Sub(m.Start).Hours(); hours > 0 {
+		m.MergesPerHour = float64(m.Merged) / hours
+	}
+}
+
+// hours widens the range to whole hours, moving the start to the start of its hour and the end to the end of its hour.
+func hours(start, end time.Time) (time.Time, time.Time) {
+	from, to := start.Truncate(time.Hour), end.Truncate(time.Hour)
+	if to.Before(end) {
+		to = to.Add(time.Hour)
+	}
+
+	return from, to
+}
+
+// number returns the raw pull request number of the protojson payload, where int64 is encoded as a string.
+func number(payload string) string {
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal([]byte(payload), &fields); err != nil {
+		return ""
+	}
+
+	return strings.Trim(string(fields["number"]), `"`)
+}
@@ -0,0 +1,43 @@
+package queue_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.breu.io/quantm/internal/events"
+	"go.breu.io/quantm/internal/insights/queue"
+	"go.breu.io/quantm/internal/pulse"
+)
+
+func TestAggregate(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(10 * time.Hour)
+
+	records := []pulse.Record{
+		record(events.ActionEnqueued, `{"number":"1"}`, start),
+		record(events.ActionPromoted, `{"number":"2"}`, start.Add(time.Minute)),
+		record(events.ActionTesting, `{"number":"2"}`, start.Add(3*time.Minute)),
+		record(events.ActionTesting, `{"number":"1"}`, start.Add(10*time.Minute)),
+		record(events.ActionMerged, `{"number":"2"}`, start.Add(20*time.Minute)),
+		record(events.ActionEvicted, `{"number":"1"}`, start.Add(30*time.Minute)),
+		record(events.ActionEnqueued, `{"number":"3"}`, start.Add(40*time.Minute)),
+	}
+
+	metrics := queue.Aggregate(records, start, end)
+
+	assert.Equal(t, int64(3), metrics.Enqueued)
+	assert.Equal(t, int64(1), metrics.Merged)
+	assert.Equal(t, int64(1), metrics.Evicted)
+	assert.InDelta(t, 1.0/3.0, metrics.EvictionRate, 1e-9)
+	assert.InDelta(t, 0.1, metrics.MergesPerHour, 1e-9)
+	assert.Equal(t, 2*time.Minute, metrics.WaitTime)
+	assert.Equal(t, 10*time.Minute, metrics.WaitTimeP95)
+}
+
+func record(action events.Action, payload string, ts time.Time) pulse.Record {
+	return pulse.Record{Scope: events.ScopeMergeQueue, Action: action, Payload: payload, Timestamp: ts}
+}
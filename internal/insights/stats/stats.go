@@ -0,0 +1,25 @@
+// Package stats provides the statistics shared by the insights.
+package stats
+
+import (
+	"slices"
+	"time"
+)
+
+// Percentile returns the nearest rank percentile of the durations, or 0 if there are none. The durations are sorted on
+// a copy, and left as is.
+func Percentile(durations []time.Duration, p int) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
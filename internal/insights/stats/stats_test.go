@@ -0,0 +1,38 @@
+package stats_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.breu.io/quantm/internal/insights/stats"
+)
+
+func TestPercentile(t *testing.T) {
+	t.Parallel()
+
+	durations := []time.Duration{5 * time.Minute, time.Minute, 3 * time.Minute, 2 * time.Minute, 4 * time.Minute}
+
+	tests := []struct {
+		name string
+		in   []time.Duration
+		p    int
+		want time.Duration
+	}{
+		{"empty", nil, 50, 0},
+		{"median", durations, 50, 3 * time.Minute},
+		{"p95", durations, 95, 5 * time.Minute},
+		{"p0", durations, 0, time.Minute},
+		{"single", []time.Duration{time.Second}, 95, time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, stats.Percentile(tt.in, tt.p))
+		})
+	}
+
+	// the durations are left unsorted.
+	assert.Equal(t, 5*time.Minute, durations[0])
+}
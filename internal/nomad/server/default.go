@@ -7,6 +7,7 @@ import (
 	"go.breu.io/quantm/internal/core/repos"
 	"go.breu.io/quantm/internal/hooks/github"
 	"go.breu.io/quantm/internal/hooks/slack"
+	"go.breu.io/quantm/internal/insights"
 	"go.breu.io/quantm/internal/nomad/intercepts"
 )
 
@@ -46,5 +47,8 @@ func DefaultServer(opts ...Option) *Server {
 	// -- hooks/slack --
 	srv.add(slack.NomadHandler(options...))
 
+	// -- insights --
+	srv.add(insights.NomadHandler(options...))
+
 	return srv
 }
@@ -0,0 +1,173 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: ctrlplane/insights/v1/insights.proto
+
+package insightsv1connect
+
+import (
+	connect "connectrpc.com/connect"
+	context "context"
+	errors "errors"
+	v1 "go.breu.io/quantm/internal/proto/ctrlplane/insights/v1"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect.IsAtLeastVersion1_13_0
+
+const (
+	// InsightsServiceName is the fully-qualified name of the InsightsService service.
+	InsightsServiceName = "ctrlplane.insights.v1.InsightsService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// InsightsServiceGetDORAMetricsProcedure is the fully-qualified name of the InsightsService's
+	// GetDORAMetrics RPC.
+	InsightsServiceGetDORAMetricsProcedure = "/ctrlplane.insights.v1.InsightsService/GetDORAMetrics"
+	// InsightsServiceGetQueueMetricsProcedure is the fully-qualified name of the InsightsService's
+	// GetQueueMetrics RPC.
+	InsightsServiceGetQueueMetricsProcedure = "/ctrlplane.insights.v1.InsightsService/GetQueueMetrics"
+	// InsightsServiceGetLineageProcedure is the fully-qualified name of the InsightsService's
+	// GetLineage RPC.
+	InsightsServiceGetLineageProcedure = "/ctrlplane.insights.v1.InsightsService/GetLineage"
+)
+
+// InsightsServiceClient is a client for the ctrlplane.insights.v1.InsightsService service.
+type InsightsServiceClient interface {
+	// Get the DORA metrics for the org, a team or a repo.
+	GetDORAMetrics(context.Context, *connect.Request[v1.GetDORAMetricsRequest]) (*connect.Response[v1.GetDORAMetricsResponse], error)
+	// Get the merge queue throughput and wait time for a repo.
+	GetQueueMetrics(context.Context, *connect.Request[v1.GetQueueMetricsRequest]) (*connect.Response[v1.GetQueueMetricsResponse], error)
+	// Get the ancestors and the descendants of an event.
+	GetLineage(context.Context, *connect.Request[v1.GetLineageRequest]) (*connect.Response[v1.GetLineageResponse], error)
+}
+
+// NewInsightsServiceClient constructs a client for the ctrlplane.insights.v1.InsightsService
+// service. By default, it uses the Connect protocol with the binary Protobuf Codec, asks for
+// gzipped responses, and sends uncompressed requests. To use the gRPC or gRPC-Web protocols, supply
+// the connect.WithGRPC() or connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewInsightsServiceClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) InsightsServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	insightsServiceMethods := v1.File_ctrlplane_insights_v1_insights_proto.Services().ByName("InsightsService").Methods()
+	return &insightsServiceClient{
+		getDORAMetrics: connect.NewClient[v1.GetDORAMetricsRequest, v1.GetDORAMetricsResponse](
+			httpClient,
+			baseURL+InsightsServiceGetDORAMetricsProcedure,
+			connect.WithSchema(insightsServiceMethods.ByName("GetDORAMetrics")),
+			connect.WithClientOptions(opts...),
+		),
+		getQueueMetrics: connect.NewClient[v1.GetQueueMetricsRequest, v1.GetQueueMetricsResponse](
+			httpClient,
+			baseURL+InsightsServiceGetQueueMetricsProcedure,
+			connect.WithSchema(insightsServiceMethods.ByName("GetQueueMetrics")),
+			connect.WithClientOptions(opts...),
+		),
+		getLineage: connect.NewClient[v1.GetLineageRequest, v1.GetLineageResponse](
+			httpClient,
+			baseURL+InsightsServiceGetLineageProcedure,
+			connect.WithSchema(insightsServiceMethods.ByName("GetLineage")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// insightsServiceClient implements InsightsServiceClient.
+type insightsServiceClient struct {
+	getDORAMetrics  *connect.Client[v1.GetDORAMetricsRequest, v1.GetDORAMetricsResponse]
+	getQueueMetrics *connect.Client[v1.GetQueueMetricsRequest, v1.GetQueueMetricsResponse]
+	getLineage      *connect.Client[v1.GetLineageRequest, v1.GetLineageResponse]
+}
+
+// GetDORAMetrics calls ctrlplane.insights.v1.InsightsService.GetDORAMetrics.
+func (c *insightsServiceClient) GetDORAMetrics(ctx context.Context, req *connect.Request[v1.GetDORAMetricsRequest]) (*connect.Response[v1.GetDORAMetricsResponse], error) {
+	return c.getDORAMetrics.CallUnary(ctx, req)
+}
+
+// GetQueueMetrics calls ctrlplane.insights.v1.InsightsService.GetQueueMetrics.
+func (c *insightsServiceClient) GetQueueMetrics(ctx context.Context, req *connect.Request[v1.GetQueueMetricsRequest]) (*connect.Response[v1.GetQueueMetricsResponse], error) {
+	return c.getQueueMetrics.CallUnary(ctx, req)
+}
+
+// GetLineage calls ctrlplane.insights.v1.InsightsService.GetLineage.
+func (c *insightsServiceClient) GetLineage(ctx context.Context, req *connect.Request[v1.GetLineageRequest]) (*connect.Response[v1.GetLineageResponse], error) {
+	return c.getLineage.CallUnary(ctx, req)
+}
+
+// InsightsServiceHandler is an implementation of the ctrlplane.insights.v1.InsightsService service.
+type InsightsServiceHandler interface {
+	// Get the DORA metrics for the org, a team or a repo.
+	GetDORAMetrics(context.Context, *connect.Request[v1.GetDORAMetricsRequest]) (*connect.Response[v1.GetDORAMetricsResponse], error)
+	// Get the merge queue throughput and wait time for a repo.
+	GetQueueMetrics(context.Context, *connect.Request[v1.GetQueueMetricsRequest]) (*connect.Response[v1.GetQueueMetricsResponse], error)
+	// Get the ancestors and the descendants of an event.
+	GetLineage(context.Context, *connect.Request[v1.GetLineageRequest]) (*connect.Response[v1.GetLineageResponse], error)
+}
+
+// NewInsightsServiceHandler builds an HTTP handler from the service implementation. It returns the
+// path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewInsightsServiceHandler(svc InsightsServiceHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	insightsServiceMethods := v1.File_ctrlplane_insights_v1_insights_proto.Services().ByName("InsightsService").Methods()
+	insightsServiceGetDORAMetricsHandler := connect.NewUnaryHandler(
+		InsightsServiceGetDORAMetricsProcedure,
+		svc.GetDORAMetrics,
+		connect.WithSchema(insightsServiceMethods.ByName("GetDORAMetrics")),
+		connect.WithHandlerOptions(opts...),
+	)
+	insightsServiceGetQueueMetricsHandler := connect.NewUnaryHandler(
+		InsightsServiceGetQueueMetricsProcedure,
+		svc.GetQueueMetrics,
+		connect.WithSchema(insightsServiceMethods.ByName("GetQueueMetrics")),
+		connect.WithHandlerOptions(opts...),
+	)
+	insightsServiceGetLineageHandler := connect.NewUnaryHandler(
+		InsightsServiceGetLineageProcedure,
+		svc.GetLineage,
+		connect.WithSchema(insightsServiceMethods.ByName("GetLineage")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/ctrlplane.insights.v1.InsightsService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case InsightsServiceGetDORAMetricsProcedure:
+			insightsServiceGetDORAMetricsHandler.ServeHTTP(w, r)
+		case InsightsServiceGetQueueMetricsProcedure:
+			insightsServiceGetQueueMetricsHandler.ServeHTTP(w, r)
+		case InsightsServiceGetLineageProcedure:
+			insightsServiceGetLineageHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedInsightsServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedInsightsServiceHandler struct{}
+
+func (UnimplementedInsightsServiceHandler) GetDORAMetrics(context.Context, *connect.Request[v1.GetDORAMetricsRequest]) (*connect.Response[v1.GetDORAMetricsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("ctrlplane.insights.v1.InsightsService.GetDORAMetrics is not implemented"))
+}
+
+func (UnimplementedInsightsServiceHandler) GetQueueMetrics(context.Context, *connect.Request[v1.GetQueueMetricsRequest]) (*connect.Response[v1.GetQueueMetricsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("ctrlplane.insights.v1.InsightsService.GetQueueMetrics is not implemented"))
+}
+
+func (UnimplementedInsightsServiceHandler) GetLineage(context.Context, *connect.Request[v1.GetLineageRequest]) (*connect.Response[v1.GetLineageResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("ctrlplane.insights.v1.InsightsService.GetLineage is not implemented"))
+}
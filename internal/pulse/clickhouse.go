@@ -0,0 +1,243 @@
+package pulse
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"go.breu.io/quantm/internal/events"
+)
+
+type (
+	// ClickhouseSink stores the events of each org in its own clickhouse table. The tables are migrated with the embedded
+	// migrations, tracked in the pulse_migrations ledger, and the retention is a TTL on the table.
+	ClickhouseSink struct{}
+)
+
+const (
+	statement__events__batch = `
+INSERT INTO %s (
+	version,
+	id,
+	parents,
+	hook,
+	scope,
+	action,
+	source,
+	subject_id,
+	subject_name,
+	user_id,
+	team_id,
+	org_id,
+	timestamp,
+	payload
+)
+`
+
+	statement__events__select = `
+SELECT
+	version,
+	id,
+	parents,
+	hook,
+	scope,
+	action,
+	source,
+	subject_id,
+	subject_name,
+	user_id,
+	team_id,
+	org_id,
+	timestamp,
+	payload
+FROM %s
+WHERE %s
+ORDER BY timestamp, id
+`
+)
+
+// Migrate applies the pending migrations to the table, creating it if it doesn't exist, and then applies the retention.
+func (s *ClickhouseSink) Migrate(ctx context.Context, _ uuid.UUID, table string, retention int32) error {
+	migrations, err := load()
+	if err != nil {
+		return err
+	}
+
+	if err := Get().Connection().Exec(ctx, statement__ledger__create); err != nil {
+		return err
+	}
+
+	var current uint32
+	if err := Get().Connection().QueryRow(ctx, statement__ledger__version, table).Scan(&current); err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+
+		slog.Info("pulse: applying migration ...", "table", table, "version", m.version, "name", m.name)
+
+		if err := m.apply(ctx, table); err != nil {
+			return fmt.Errorf("pulse: migration %d (%s) failed on %s: %w", m.version, m.name, table, err)
+		}
+	}
+
+	return s.Retain(ctx, table, retention)
+}
+
+// Retain sets the TTL on the table. The TTL is only removed if the table has one, since clickhouse refuses to remove a
+// TTL that doesn't exist.
+func (s *ClickhouseSink) Retain(ctx context.Context, table string, days int32) error {
+	if days > 0 {
+		return Get().Connection().Exec(ctx, fmt.Sprintf(statement__ttl__modify, table, days))
+	}
+
+	var engine string
+	if err := Get().Connection().QueryRow(ctx, statement__ttl__get, table).Scan(&engine); err != nil {
+		return err
+	}
+
+	if !strings.Contains(engine, " TTL ") {
+		return nil
+	}
+
+	return Get().Connection().Exec(ctx, fmt.Sprintf(statement__ttl__remove, table))
+}
+
+// Insert writes the records to the table in a single batch.
+func (s *ClickhouseSink) Insert(ctx context.Context, table string, records []Record) error {
+	batch, err := Get().Connection().PrepareBatch(ctx, fmt.Sprintf(statement__events__batch, table))
+	if err != nil {
+		return err
+	}
+
+	for _, r := range records {
+		err := batch.Append(
+			r.Version.String(),
+			r.ID,
+			r.Parents,
+			r.Hook,
+			r.Scope.String(),
+			r.Action.String(),
+			r.Source,
+			r.SubjectID,
+			r.SubjectName,
+			r.UserID,
+			r.TeamID,
+			r.OrgID,
+			r.Timestamp,
+			r.Payload,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return batch.Send()
+}
+
+// Query reads the records of the org from the table matching the filter, ordered by timestamp.
+func (s *ClickhouseSink) Query(ctx context.Context, table string, org uuid.UUID, filter *Filter) ([]Record, error) {
+	where, args := filter.where(org)
+	stmt := fmt.Sprintf(statement__events__select, table, where)
+
+	rows, err := Get().Connection().Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	records := make([]Record, 0)
+
+	for rows.Next() {
+		var (
+			record        Record
+			version       string
+			scope, action string
+		)
+
+		err := rows.Scan(
+			&version,
+			&record.ID,
+			&record.Parents,
+			&record.Hook,
+			&scope,
+			&action,
+			&record.Source,
+			&record.SubjectID,
+			&record.SubjectName,
+			&record.UserID,
+			&record.TeamID,
+			&record.OrgID,
+			&record.Timestamp,
+			&record.Payload,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		record.Scope = events.Scope(scope)
+		record.Action = events.Action(action)
+
+		record.Version = events.EventVersion(version)
+
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+// where builds the clickhouse where clause along with its arguments.
+func (f *Filter) where(org uuid.UUID) (string, []any) {
+	clauses := []string{"org_id = ?"}
+	args := []any{org}
+
+	// the ids are bound as an array of strings.
+	if len(f.IDs) > 0 {
+		clauses = append(clauses, "has(?, toString(id))")
+		args = append(args, f.IDs)
+	}
+
+	if len(f.Parents) > 0 {
+		clauses = append(clauses, "hasAny(arrayMap(x -> toString(x), parents), ?)")
+		args = append(args, f.Parents)
+	}
+
+	if f.SubjectID != uuid.Nil {
+		clauses = append(clauses, "subject_id = ?")
+		args = append(args, f.SubjectID)
+	}
+
+	if f.TeamID != uuid.Nil {
+		clauses = append(clauses, "team_id = ?")
+		args = append(args, f.TeamID)
+	}
+
+	if len(f.Scopes) > 0 {
+		scopes := make([]string, 0, len(f.Scopes))
+		for _, scope := range f.Scopes {
+			scopes = append(scopes, scope.String())
+		}
+
+		clauses = append(clauses, "has(?, scope)")
+		args = append(args, scopes)
+	}
+
+	if !f.Start.IsZero() {
+		clauses = append(clauses, "timestamp >= ?")
+		args = append(args, f.Start)
+	}
+
+	if !f.End.IsZero() {
+		clauses = append(clauses, "timestamp < ?")
+		args = append(args, f.End)
+	}
+
+	return strings.Join(clauses, " AND "), args
+}
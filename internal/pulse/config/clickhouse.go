@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"sync"
+	"time"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
 	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
@@ -20,6 +21,24 @@ type (
 		Password string `json:"pass" koanf:"PASS" validate:"required"` // Database password.
 		Name     string `json:"name" koanf:"NAME" validate:"required"` // Database name.
 
+		// RetentionDays is the default number of days events are kept for, unless overridden for the org. 0 keeps the
+		// events forever.
+		RetentionDays int32 `json:"retention_days" koanf:"RETENTION_DAYS" validate:"gte=0"`
+
+		// BatchSize is the number of events buffered by the writer before they are flushed.
+		BatchSize int `json:"batch_size" koanf:"BATCH_SIZE" validate:"gte=1"`
+
+		// FlushInterval is the maximum time events are buffered by the writer before they are flushed.
+		FlushInterval time.Duration `json:"flush_interval" koanf:"FLUSH_INTERVAL" validate:"gt=0"`
+
+		// WALDir is the directory of the write ahead log, where the events are spilled when clickhouse is unavailable.
+		// Defaults to the temp directory.
+		WALDir string `json:"wal_dir" koanf:"WAL_DIR"`
+
+		// Sink is the backend the events are stored in, i.e. clickhouse, postgres or memory. The connection settings
+		// above are only used by the clickhouse sink.
+		Sink string `json:"sink" koanf:"SINK" validate:"oneof=clickhouse postgres memory"`
+
 		conn driver.Conn // Established database connection.
 		once *sync.Once  // Ensures single connection initialization.
 	}
@@ -28,6 +47,12 @@ type (
 	Option func(*Config)
 )
 
+const (
+	SinkClickhouse = "clickhouse" // SinkClickhouse stores the events in a table per org in clickhouse.
+	SinkPostgres   = "postgres"   // SinkPostgres stores the events in a partition per org of the postgres events table.
+	SinkMemory     = "memory"     // SinkMemory keeps the events in memory, for tests and local development.
+)
+
 var (
 	// DefaultConfig defines the default configuration for connecting to a ClickHouse database.
 	DefaultConfig = Config{
@@ -37,6 +62,11 @@ var (
 		Password: "ctrlplane", // Default password.
 		Name:     "ctrlplane", // Default database name.
 
+		BatchSize:     500,         // Default batch size.
+		FlushInterval: time.Second, // Default flush interval.
+
+		Sink: SinkClickhouse, // Default sink.
+
 		once: &sync.Once{}, // Guarantees single connection attempt.
 	}
 )
@@ -88,10 +118,14 @@ func (c *Config) Connection() driver.Conn {
 
 // Start initiates a connection to the ClickHouse database.  Uses a sync.Once to ensure the connection is established only
 // once, even with concurrent calls.  The provided context allows for cancellation or timeout during connection establishment.
-// Returns an error from the connect function.
+// Returns an error from the connect function. Nothing is done if the events are not stored in clickhouse.
 func (c *Config) Start(ctx context.Context) error {
 	var err error
 
+	if c.Sink != SinkClickhouse {
+		return nil
+	}
+
 	c.once.Do(func() {
 		err = c.connect(ctx)
 	})
@@ -145,6 +179,41 @@ func WithName(name string) Option {
 	}
 }
 
+// WithRetentionDays sets the default number of days events are kept for.
+func WithRetentionDays(days int32) Option {
+	return func(c *Config) {
+		c.RetentionDays = days
+	}
+}
+
+// WithBatchSize sets the number of events buffered by the writer before they are flushed.
+func WithBatchSize(size int) Option {
+	return func(c *Config) {
+		c.BatchSize = size
+	}
+}
+
+// WithFlushInterval sets the maximum time events are buffered by the writer before they are flushed.
+func WithFlushInterval(interval time.Duration) Option {
+	return func(c *Config) {
+		c.FlushInterval = interval
+	}
+}
+
+// WithWALDir sets the directory of the write ahead log.
+func WithWALDir(dir string) Option {
+	return func(c *Config) {
+		c.WALDir = dir
+	}
+}
+
+// WithSink sets the backend the events are stored in.
+func WithSink(sink string) Option {
+	return func(c *Config) {
+		c.Sink = sink
+	}
+}
+
 // WithConfig applies a given Clickhouse configuration.
 func WithConfig(cfg *Config) Option {
 	return func(c *Config) {
@@ -153,6 +222,11 @@ func WithConfig(cfg *Config) Option {
 		c.User = cfg.User
 		c.Password = cfg.Password
 		c.Name = cfg.Name
+		c.RetentionDays = cfg.RetentionDays
+		c.BatchSize = cfg.BatchSize
+		c.FlushInterval = cfg.FlushInterval
+		c.WALDir = cfg.WALDir
+		c.Sink = cfg.Sink
 	}
 }
 
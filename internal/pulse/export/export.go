@@ -0,0 +1,189 @@
+// Package export exports the quantm events as CloudEvents to the sinks configured per org.
+//
+// A sink is an http endpoint, receiving the event in the structured or the binary mode, with the headers configured on
+// the sink, e.g. for authorization. The events are exported off the persist activity by the pulse exporter, which
+// retries the failed exports.
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"go.breu.io/quantm/internal/db"
+	"go.breu.io/quantm/internal/db/entities"
+	"go.breu.io/quantm/internal/events/cloudevents"
+)
+
+type (
+	// Mode is the http content mode of the CloudEvent.
+	Mode string
+
+	// Sink is the destination of the exported events.
+	Sink interface {
+		Send(ctx context.Context, event *cloudevents.Event) error
+	}
+
+	// HTTPSink posts the events to the url. The headers are set on every request, after the headers of the event.
+	HTTPSink struct {
+		URL     string
+		Mode    Mode
+		Headers map[string]string
+		Client  *http.Client
+	}
+
+	// cached are the sinks of an org, cached for the ttl.
+	cached struct {
+		sinks   []Sink
+		expires time.Time
+	}
+)
+
+const (
+	ModeStructured Mode = "structured" // ModeStructured sends the event as json, with the data as a field.
+	ModeBinary     Mode = "binary"     // ModeBinary sends the attributes as headers, with the data as the body.
+
+	KindHTTP = "http" // KindHTTP is the kind of the http sink.
+)
+
+var (
+	// TTL is the duration the sinks of an org are cached for.
+	TTL = time.Minute
+
+	client = &http.Client{Timeout: 10 * time.Second}
+
+	cache      = make(map[uuid.UUID]cached)
+	cachemutex sync.Mutex
+)
+
+// Export exports the event to every active sink of the org. All the sinks are tried, and the errors are joined.
+func Export(ctx context.Context, org uuid.UUID, event *cloudevents.Event) error {
+	sinks, err := Sinks(ctx, org)
+	if err != nil || len(sinks) == 0 {
+		return err
+	}
+
+	errs := make([]error, 0)
+
+	for _, sink := range sinks {
+		if err := sink.Send(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Sinks returns the active sinks of the org. The sinks are cached for the TTL.
+func Sinks(ctx context.Context, org uuid.UUID) ([]Sink, error) {
+	cachemutex.Lock()
+	entry, ok := cache[org]
+	cachemutex.Unlock()
+
+	if ok && time.Now().Before(entry.expires) {
+		return entry.sinks, nil
+	}
+
+	rows, err := db.Queries().ListEventSinks(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+
+	sinks := make([]Sink, 0, len(rows))
+
+	for _, row := range rows {
+		sink, err := FromEntity(row)
+		if err != nil {
+			slog.Warn("export: skipping invalid sink", "sink_id", row.ID.String(), "error", err.Error())
+			continue
+		}
+
+		sinks = append(sinks, sink)
+	}
+
+	cachemutex.Lock()
+	cache[org] = cached{sinks: sinks, expires: time.Now().Add(TTL)}
+	cachemutex.Unlock()
+
+	return sinks, nil
+}
+
+// FromEntity creates the sink from its database row.
+func FromEntity(row entities.EventSink) (Sink, error) {
+	switch row.Kind {
+	case KindHTTP:
+		headers := make(map[string]string)
+
+		if len(row.Headers) > 0 {
+			if err := json.Unmarshal(row.Headers, &headers); err != nil {
+				return nil, fmt.Errorf("invalid sink headers: %w", err)
+			}
+		}
+
+		return &HTTPSink{URL: row.Target, Mode: Mode(row.Mode), Headers: headers}, nil
+	default:
+		return nil, fmt.Errorf("unknown sink kind: %s", row.Kind)
+	}
+}
+
+// Send posts the event to the url, in the mode of the sink. Any non 2xx response is an error.
+func (s *HTTPSink) Send(ctx context.Context, event *cloudevents.Event) error {
+	var (
+		header http.Header
+		body   []byte
+		err    error
+	)
+
+	switch s.Mode {
+	case ModeBinary:
+		header, body = event.Binary()
+	case ModeStructured:
+		header, body, err = event.Structured()
+	default:
+		header, body, err = event.Structured()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	request.Header = header
+
+	for key, value := range s.Headers {
+		request.Header.Set(key, value)
+	}
+
+	c := s.Client
+	if c == nil {
+		c = client
+	}
+
+	response, err := c.Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("export: %s responded with %d", s.URL, response.StatusCode)
+	}
+
+	return nil
+}
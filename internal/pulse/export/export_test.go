@@ -0,0 +1,112 @@
+package export_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.breu.io/quantm/internal/db/entities"
+	"go.breu.io/quantm/internal/events"
+	"go.breu.io/quantm/internal/events/cloudevents"
+	eventsv1 "go.breu.io/quantm/internal/proto/ctrlplane/events/v1"
+	"go.breu.io/quantm/internal/pulse/export"
+)
+
+func event() *cloudevents.Event {
+	return cloudevents.FromFlat(events.Flat[eventsv1.RepoHook]{
+		ID:      uuid.New(),
+		Scope:   events.ScopePush,
+		Action:  events.ActionCreated,
+		Payload: `{"ref":"refs/heads/main"}`,
+	})
+}
+
+func TestHTTPSink(t *testing.T) {
+	t.Parallel()
+
+	for _, mode := range []export.Mode{export.ModeStructured, export.ModeBinary} {
+		t.Run(string(mode), func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				header http.Header
+				body   []byte
+			)
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				header = r.Header.Clone()
+				body, _ = io.ReadAll(r.Body)
+
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defer server.Close()
+
+			e := event()
+			sink := &export.HTTPSink{URL: server.URL, Mode: mode}
+
+			require.NoError(t, sink.Send(context.Background(), e))
+
+			if mode == export.ModeBinary {
+				assert.Equal(t, e.ID, header.Get("ce-id"))
+				assert.JSONEq(t, `{"ref":"refs/heads/main"}`, string(body))
+			} else {
+				assert.Equal(t, cloudevents.ContentTypeStructured, header.Get("Content-Type"))
+				assert.Contains(t, string(body), `"id":"`+e.ID+`"`)
+			}
+		})
+	}
+}
+
+func TestHTTPSink_Error(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	sink := &export.HTTPSink{URL: server.URL, Mode: export.ModeStructured}
+
+	assert.Error(t, sink.Send(context.Background(), event()))
+}
+
+func TestHTTPSink_Headers(t *testing.T) {
+	t.Parallel()
+
+	var header http.Header
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sink, err := export.FromEntity(entities.EventSink{
+		Kind:    export.KindHTTP,
+		Mode:    string(export.ModeBinary),
+		Target:  server.URL,
+		Headers: []byte(`{"Authorization":"Bearer secret"}`),
+	})
+	require.NoError(t, err)
+
+	e := event()
+
+	require.NoError(t, sink.Send(context.Background(), e))
+	assert.Equal(t, "Bearer secret", header.Get("Authorization"))
+	assert.Equal(t, e.ID, header.Get("ce-id"))
+}
+
+func TestFromEntity_File(t *testing.T) {
+	t.Parallel()
+
+	_, err := export.FromEntity(entities.EventSink{Kind: "file", Target: "/tmp/events.ndjson"})
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,230 @@
+package pulse
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"go.breu.io/quantm/internal/events/cloudevents"
+	"go.breu.io/quantm/internal/pulse/export"
+)
+
+type (
+	// Outbox exports the events to the sinks of their org off the persist activity, so that a slow or failing sink
+	// neither delays nor fails the persist.
+	//
+	// Add only buffers the event, and the buffered events are sent on every flush tick. The events of an org that can't
+	// be sent are spilled to the export write ahead log, which is retried with an exponential backoff, starting at the
+	// flush interval and capped at MaxExportBackoff. The events are sent at least once, i.e. the events of an org are sent
+	// again to every sink of the org if any of them fails, and the sinks are expected to deduplicate on the id of the
+	// CloudEvent. Events older than ExportRetention are dropped from the log.
+	Outbox struct {
+		interval time.Duration
+		wal      *wal[*cloudevents.Event]
+		send     func(ctx context.Context, org uuid.UUID, event *cloudevents.Event) error
+
+		mutex   sync.Mutex
+		pending map[uuid.UUID][]*cloudevents.Event // pending holds the buffered events per org.
+		running bool
+		backoff time.Duration // backoff is the current delay between the retries of the write ahead log.
+		retry   time.Time     // retry is when the write ahead log is retried next.
+		stop    chan struct{}
+		done    chan struct{}
+	}
+)
+
+var (
+	// ExportRetention is the age after which the events that can't be exported are dropped.
+	ExportRetention = 24 * time.Hour
+
+	// MaxExportBackoff is the maximum delay between the retries of the events that can't be exported.
+	MaxExportBackoff = 5 * time.Minute
+)
+
+var (
+	_o     *Outbox
+	oonce  sync.Once
+	oerror error
+)
+
+// Exporter returns the singleton outbox exporting the events, configured with the flush interval and the write ahead log
+// directory of the pulse configuration.
+func Exporter() *Outbox {
+	oonce.Do(func() {
+		var log *wal[*cloudevents.Event]
+
+		log, oerror = newwal[*cloudevents.Event](Get().WALDir, export_wal_file)
+
+		_o = &Outbox{
+			interval: Get().FlushInterval,
+			wal:      log,
+			send:     export.Export,
+			pending:  make(map[uuid.UUID][]*cloudevents.Event),
+		}
+	})
+
+	return _o
+}
+
+// Start starts the export loop. The write ahead log left over from a previous run is retried on the first tick.
+func (o *Outbox) Start(_ context.Context) error {
+	if oerror != nil {
+		return oerror
+	}
+
+	o.mutex.Lock()
+	o.running = true
+	o.backoff = o.interval
+	o.retry = time.Time{}
+	o.stop = make(chan struct{})
+	o.done = make(chan struct{})
+	o.mutex.Unlock()
+
+	go o.loop()
+
+	slog.Info("pulse: exporter started", "interval", o.interval)
+
+	return nil
+}
+
+// Stop sends the buffered events and stops the export loop.
+func (o *Outbox) Stop(ctx context.Context) error {
+	o.mutex.Lock()
+	if !o.running {
+		o.mutex.Unlock()
+		return nil
+	}
+
+	o.running = false
+	o.mutex.Unlock()
+
+	close(o.stop)
+
+	select {
+	case <-o.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	slog.Info("pulse: exporter stopped")
+
+	return nil
+}
+
+// Add buffers the event of the org, to be sent on the next tick. If the exporter is not running, the event is sent
+// right away.
+func (o *Outbox) Add(ctx context.Context, org uuid.UUID, event *cloudevents.Event) error {
+	o.mutex.Lock()
+
+	if !o.running {
+		o.mutex.Unlock()
+
+		return o.send(ctx, org, event)
+	}
+
+	o.pending[org] = append(o.pending[org], event)
+	o.mutex.Unlock()
+
+	return nil
+}
+
+// loop sends the buffered events, and retries the write ahead log when due, on every tick.
+func (o *Outbox) loop() {
+	ticker := time.NewTicker(o.interval)
+
+	defer ticker.Stop()
+	defer close(o.done)
+
+	for {
+		select {
+		case <-ticker.C:
+			o.flush()
+			o.replay(time.Now())
+		case <-o.stop:
+			o.flush()
+			return
+		}
+	}
+}
+
+// flush sends the buffered events org by org. The events of an org that fail are spilled to the write ahead log.
+func (o *Outbox) flush() {
+	o.mutex.Lock()
+	batches := o.pending
+	o.pending = make(map[uuid.UUID][]*cloudevents.Event)
+	o.mutex.Unlock()
+
+	ctx := context.Background()
+
+	for org, batch := range batches {
+		if err := o.export(ctx, org, batch); err != nil {
+			slog.Warn("pulse: unable to export events, spilling to wal", "org_id", org.String(), "count", len(batch), "error", err.Error())
+
+			if err := o.wal.append(org.String(), batch); err != nil {
+				slog.Error("pulse: unable to spill events to wal", "org_id", org.String(), "count", len(batch), "error", err.Error())
+			}
+		}
+	}
+}
+
+// replay retries the events in the write ahead log, if the retry is due. The backoff is doubled while the retries fail,
+// and reset once they succeed.
+func (o *Outbox) replay(now time.Time) {
+	if o.wal.size() == 0 || now.Before(o.retry) {
+		return
+	}
+
+	ctx := context.Background()
+
+	err := o.wal.replay(func(key string, batch []*cloudevents.Event) error {
+		org, err := uuid.Parse(key)
+		if err != nil {
+			return nil // a key that is not an org can never be exported.
+		}
+
+		return o.export(ctx, org, o.expire(org, batch, now))
+	})
+
+	if err == nil {
+		o.backoff = o.interval
+		o.retry = time.Time{}
+
+		return
+	}
+
+	o.backoff = min(2*o.backoff, MaxExportBackoff)
+	o.retry = now.Add(o.backoff)
+
+	slog.Warn("pulse: unable to export wal, retrying later", "retry", o.retry, "error", err.Error())
+}
+
+// export sends the events of the org one by one, stopping at the first failure.
+func (o *Outbox) export(ctx context.Context, org uuid.UUID, batch []*cloudevents.Event) error {
+	for _, event := range batch {
+		if err := o.send(ctx, org, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// expire drops the events of the org older than the export retention.
+func (o *Outbox) expire(org uuid.UUID, batch []*cloudevents.Event, now time.Time) []*cloudevents.Event {
+	kept := make([]*cloudevents.Event, 0, len(batch))
+
+	for _, event := range batch {
+		if now.Sub(event.Time) < ExportRetention {
+			kept = append(kept, event)
+		}
+	}
+
+	if dropped := len(batch) - len(kept); dropped > 0 {
+		slog.Warn("pulse: dropping expired events", "org_id", org.String(), "count", dropped)
+	}
+
+	return kept
+}
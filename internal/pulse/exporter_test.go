@@ -0,0 +1,144 @@
+package pulse
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.breu.io/quantm/internal/events/cloudevents"
+)
+
+type (
+	// receiver is a fake export sink, failing the sends while down.
+	receiver struct {
+		mutex sync.Mutex
+		down  bool
+		sent  map[uuid.UUID][]string
+	}
+)
+
+func (r *receiver) send(_ context.Context, org uuid.UUID, event *cloudevents.Event) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.down {
+		return errors.New("connection refused")
+	}
+
+	r.sent[org] = append(r.sent[org], event.ID)
+
+	return nil
+}
+
+func (r *receiver) set(down bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.down = down
+}
+
+func (r *receiver) count(org uuid.UUID) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return len(r.sent[org])
+}
+
+// outbox returns a running outbox without its loop, so that the tests flush and replay it by hand.
+func outbox(t *testing.T, r *receiver) *Outbox {
+	t.Helper()
+
+	log, err := newwal[*cloudevents.Event](t.TempDir(), export_wal_file)
+	require.NoError(t, err)
+
+	return &Outbox{
+		interval: time.Second,
+		wal:      log,
+		send:     r.send,
+		pending:  make(map[uuid.UUID][]*cloudevents.Event),
+		running:  true,
+		backoff:  time.Second,
+	}
+}
+
+func ce(ts time.Time) *cloudevents.Event {
+	return &cloudevents.Event{ID: uuid.NewString(), Time: ts, Extensions: map[string]string{}}
+}
+
+func TestOutbox_AddDoesNotSend(t *testing.T) {
+	t.Parallel()
+
+	r := &receiver{sent: make(map[uuid.UUID][]string), down: true}
+	o := outbox(t, r)
+	org := uuid.New()
+
+	// the add neither waits for nor fails on the sink.
+	require.NoError(t, o.Add(context.Background(), org, ce(time.Now())))
+	assert.Zero(t, r.count(org))
+
+	r.set(false)
+	o.flush()
+
+	assert.Equal(t, 1, r.count(org))
+}
+
+func TestOutbox_SpillAndRetry(t *testing.T) {
+	t.Parallel()
+
+	r := &receiver{sent: make(map[uuid.UUID][]string), down: true}
+	o := outbox(t, r)
+	org := uuid.New()
+	now := time.Now()
+
+	require.NoError(t, o.Add(context.Background(), org, ce(now)))
+	require.NoError(t, o.Add(context.Background(), org, ce(now)))
+
+	o.flush()
+
+	assert.Zero(t, r.count(org))
+	assert.Positive(t, o.wal.size())
+
+	// the retries back off while the sink is down.
+	o.replay(now)
+	assert.Equal(t, 2*time.Second, o.backoff)
+	assert.Equal(t, now.Add(2*time.Second), o.retry)
+
+	o.replay(now.Add(2 * time.Second))
+	assert.Equal(t, 4*time.Second, o.backoff)
+
+	// the log is not retried before the backoff elapses, even if the sink is back.
+	r.set(false)
+
+	o.replay(now.Add(3 * time.Second))
+	assert.Zero(t, r.count(org))
+
+	o.replay(now.Add(6 * time.Second))
+	assert.Equal(t, 2, r.count(org))
+	assert.Zero(t, o.wal.size())
+	assert.Equal(t, time.Second, o.backoff)
+}
+
+func TestOutbox_DropExpired(t *testing.T) {
+	t.Parallel()
+
+	r := &receiver{sent: make(map[uuid.UUID][]string), down: true}
+	o := outbox(t, r)
+	org := uuid.New()
+	now := time.Now()
+
+	require.NoError(t, o.Add(context.Background(), org, ce(now.Add(-2*ExportRetention))))
+	require.NoError(t, o.Add(context.Background(), org, ce(now)))
+
+	o.flush()
+	r.set(false)
+	o.replay(now)
+
+	assert.Equal(t, 1, r.count(org))
+	assert.Zero(t, o.wal.size())
+}
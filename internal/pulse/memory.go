@@ -0,0 +1,107 @@
+package pulse
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+type (
+	// MemorySink keeps the events in memory, for tests and local development. The events are lost on restart, and the
+	// retention is not applied.
+	MemorySink struct {
+		mutex  sync.RWMutex
+		tables map[string][]Record
+	}
+)
+
+// NewMemorySink creates an empty memory sink.
+func NewMemorySink() *MemorySink {
+	return &MemorySink{tables: make(map[string][]Record)}
+}
+
+// Migrate creates the table if it doesn't exist.
+func (s *MemorySink) Migrate(_ context.Context, _ uuid.UUID, table string, _ int32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.tables[table]; !ok {
+		s.tables[table] = make([]Record, 0)
+	}
+
+	return nil
+}
+
+// Retain is a no-op, the events are kept until the process exits.
+func (s *MemorySink) Retain(_ context.Context, _ string, _ int32) error {
+	return nil
+}
+
+// Insert appends the records to the table. The records already written are skipped.
+func (s *MemorySink) Insert(_ context.Context, table string, records []Record) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, record := range records {
+		if slices.ContainsFunc(s.tables[table], func(r Record) bool { return r.ID == record.ID }) {
+			continue
+		}
+
+		s.tables[table] = append(s.tables[table], record)
+	}
+
+	return nil
+}
+
+// Query reads the records of the org from the table matching the filter, ordered by timestamp.
+func (s *MemorySink) Query(_ context.Context, table string, org uuid.UUID, filter *Filter) ([]Record, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	records := make([]Record, 0)
+
+	for _, record := range s.tables[table] {
+		if filter.match(org, record) {
+			records = append(records, record)
+		}
+	}
+
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Timestamp.Before(records[j].Timestamp) })
+
+	return records, nil
+}
+
+// Records returns all the records of the table, in the order they were inserted.
+func (s *MemorySink) Records(table string) []Record {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return slices.Clone(s.tables[table])
+}
+
+// match returns true if the record of the org matches the filter.
+func (f *Filter) match(org uuid.UUID, record Record) bool {
+	switch {
+	case record.OrgID != org:
+		return false
+	case len(f.IDs) > 0 && !slices.Contains(f.IDs, record.ID):
+		return false
+	case len(f.Parents) > 0 && !slices.ContainsFunc(record.Parents, func(id uuid.UUID) bool { return slices.Contains(f.Parents, id) }):
+		return false
+	case f.SubjectID != uuid.Nil && record.SubjectID != f.SubjectID:
+		return false
+	case f.TeamID != uuid.Nil && record.TeamID != f.TeamID:
+		return false
+	case len(f.Scopes) > 0 && !slices.Contains(f.Scopes, record.Scope):
+		return false
+	case !f.Start.IsZero() && record.Timestamp.Before(f.Start):
+		return false
+	case !f.End.IsZero() && !record.Timestamp.Before(f.End):
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,56 @@
+package pulse
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.breu.io/quantm/internal/events"
+)
+
+func TestMemorySink(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	sink := NewMemorySink()
+	org, repo := uuid.New(), uuid.New()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	push := Record{ID: uuid.New(), OrgID: org, SubjectID: repo, Scope: events.ScopePush, Timestamp: now.Add(time.Minute)}
+	rebase := Record{ID: uuid.New(), OrgID: org, SubjectID: repo, Scope: events.ScopeRebase, Timestamp: now, Parents: []uuid.UUID{push.ID}}
+	other := Record{ID: uuid.New(), OrgID: uuid.New(), SubjectID: repo, Scope: events.ScopePush, Timestamp: now}
+
+	require.NoError(t, sink.Migrate(ctx, org, "events_acme", 30))
+	require.NoError(t, sink.Insert(ctx, "events_acme", []Record{push, rebase, other}))
+	require.NoError(t, sink.Insert(ctx, "events_acme", []Record{push}))
+
+	assert.Len(t, sink.Records("events_acme"), 3)
+
+	records, err := sink.Query(ctx, "events_acme", org, &Filter{SubjectID: repo})
+	require.NoError(t, err)
+	assert.Equal(t, []Record{rebase, push}, records)
+
+	records, err = sink.Query(ctx, "events_acme", org, &Filter{Parents: []uuid.UUID{push.ID}})
+	require.NoError(t, err)
+	assert.Equal(t, []Record{rebase}, records)
+
+	records, err = sink.Query(ctx, "events_acme", org, &Filter{Scopes: []events.Scope{events.ScopePush}, Start: now, End: now.Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Equal(t, []Record{push}, records)
+}
+
+func TestFilter_WherePG(t *testing.T) {
+	t.Parallel()
+
+	org, repo := uuid.New(), uuid.New()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	where, args := (&Filter{SubjectID: repo, Scopes: []events.Scope{events.ScopePush}, Start: start}).where_pg(org)
+
+	assert.Equal(t, "org_id = $1 AND subject_id = $2 AND scope = ANY($3) AND timestamp >= $4", where)
+	assert.Equal(t, []any{org, repo, []string{"push"}, start}, args)
+}
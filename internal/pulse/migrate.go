@@ -0,0 +1,214 @@
+package pulse
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/google/uuid"
+
+	"go.breu.io/quantm/internal/db"
+	"go.breu.io/quantm/internal/db/entities"
+)
+
+type (
+	// migration is a versioned set of clickhouse statements separated by semicolons, applied to each org table. The
+	// statements are a template with the table name as {{ .Table }}.
+	migration struct {
+		version uint32
+		name    string
+		stmt    *template.Template
+	}
+)
+
+const (
+	statement__ledger__create = `
+CREATE TABLE IF NOT EXISTS pulse_migrations (
+  table_name String,
+  version UInt32,
+  name String,
+  applied_at DateTime DEFAULT now()
+)
+ENGINE = ReplacingMergeTree(applied_at)
+ORDER BY (table_name, version);
+`
+
+	statement__ledger__version = `SELECT max(version) FROM pulse_migrations WHERE table_name = ?`
+
+	statement__ledger__insert = `INSERT INTO pulse_migrations (table_name, version, name) VALUES (?, ?, ?)`
+
+	statement__ttl__get = `SELECT engine_full FROM system.tables WHERE database = currentDatabase() AND name = ?`
+
+	statement__ttl__modify = `ALTER TABLE %s MODIFY TTL timestamp + INTERVAL %d DAY`
+
+	statement__ttl__remove = `ALTER TABLE %s REMOVE TTL`
+)
+
+var (
+	//go:embed migrations/*.sql
+	sql embed.FS
+)
+
+// Migrate creates the events table of the org with the given slug in the sink, or migrates it to the latest schema, and
+// then applies the retention.
+//
+// retention is the number of days the events are kept for, 0 keeps them forever.
+func Migrate(ctx context.Context, org uuid.UUID, slug string, retention int32) error {
+	sink, err := Sink()
+	if err != nil {
+		return err
+	}
+
+	return sink.Migrate(ctx, org, table_name("events", slug), retention)
+}
+
+// MigrateAll migrates the events tables of all the orgs, applying the retention configured for each org or the
+// default retention if none is configured.
+func MigrateAll(ctx context.Context) error {
+	orgs, err := db.Queries().ListOrgsWithRetention(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, org := range orgs {
+		if err := Migrate(ctx, org.ID, org.Slug, retention(org.Days)); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("pulse: migrations done successfully", "orgs", len(orgs))
+
+	return nil
+}
+
+// ExpireAll deletes the expired events of all the orgs, applying the retention configured for each org or the default
+// retention if none is configured. It is a no-op for the sinks expiring the events on their own.
+func ExpireAll(ctx context.Context) error {
+	sink, err := Sink()
+	if err != nil {
+		return err
+	}
+
+	expirer, ok := sink.(Expirer)
+	if !ok {
+		return nil
+	}
+
+	orgs, err := db.Queries().ListOrgsWithRetention(ctx)
+	if err != nil {
+		return err
+	}
+
+	errs := make([]error, 0)
+
+	for _, org := range orgs {
+		if err := expirer.Expire(ctx, table_name("events", org.Slug), retention(org.Days)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// SetRetention saves the retention for the org and applies it to the events table right away. 0 keeps the events
+// forever.
+func SetRetention(ctx context.Context, org uuid.UUID, days int32) error {
+	if days < 0 {
+		return fmt.Errorf("pulse: invalid retention %d", days)
+	}
+
+	slug, err := db.Queries().GetOrgSlugByID(ctx, org)
+	if err != nil {
+		return err
+	}
+
+	if err := db.Queries().SetEventRetention(ctx, entities.SetEventRetentionParams{OrgID: org, Days: days}); err != nil {
+		return err
+	}
+
+	sink, err := Sink()
+	if err != nil {
+		return err
+	}
+
+	return sink.Retain(ctx, table_name("events", slug), days)
+}
+
+// apply executes the migration against the table and records it in the ledger.
+func (m *migration) apply(ctx context.Context, table string) error {
+	buf := &bytes.Buffer{}
+	if err := m.stmt.Execute(buf, struct{ Table string }{Table: table}); err != nil {
+		return err
+	}
+
+	// clickhouse executes a single statement per query.
+	for _, stmt := range strings.Split(buf.String(), ";") {
+		if strings.TrimSpace(stmt) == "" {
+			continue
+		}
+
+		if err := Get().Connection().Exec(ctx, stmt); err != nil {
+			return err
+		}
+	}
+
+	return Get().Connection().Exec(ctx, statement__ledger__insert, table, m.version, m.name)
+}
+
+// retention returns the retention for the org, falling back to the configured default.
+func retention(days int32) int32 {
+	if days > 0 {
+		return days
+	}
+
+	return Get().RetentionDays
+}
+
+// load reads the embedded migrations, named as {version}_{name}.sql, ordered by version.
+func load() ([]*migration, error) {
+	entries, err := fs.ReadDir(sql, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]*migration, 0, len(entries))
+
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".sql")
+
+		prefix, suffix, found := strings.Cut(name, "_")
+		if !found {
+			return nil, fmt.Errorf("pulse: invalid migration name %s", entry.Name())
+		}
+
+		version, err := strconv.ParseUint(prefix, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("pulse: invalid migration version %s: %w", entry.Name(), err)
+		}
+
+		contents, err := sql.ReadFile(path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		stmt, err := template.New(name).Parse(string(contents))
+		if err != nil {
+			return nil, err
+		}
+
+		migrations = append(migrations, &migration{version: uint32(version), name: suffix, stmt: stmt})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+
+	return migrations, nil
+}
@@ -0,0 +1 @@
+ALTER TABLE {{ .Table }} ADD COLUMN IF NOT EXISTS payload String DEFAULT '{}' CODEC(ZSTD(3)) AFTER timestamp;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS {{ .Table }}_queue_hourly (
+  subject_id UUID,
+  hour DateTime,
+  action LowCardinality(String),
+  total UInt64
+)
+ENGINE = SummingMergeTree(total)
+PARTITION BY toYYYYMM(hour)
+ORDER BY (subject_id, hour, action);
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS {{ .Table }}_queue_hourly_mv TO {{ .Table }}_queue_hourly AS
+SELECT
+  subject_id,
+  toStartOfHour(timestamp) AS hour,
+  action,
+  count() AS total
+FROM {{ .Table }}
+WHERE scope = 'merge_queue' AND has(['enqueued', 'promoted', 'testing', 'evicted', 'merged'], action)
+GROUP BY subject_id, hour, action;
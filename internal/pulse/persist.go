@@ -2,36 +2,17 @@ package pulse
 
 import (
 	"context"
-	"fmt"
+	"log/slog"
 
 	"go.breu.io/durex/dispatch"
 	"go.temporal.io/sdk/workflow"
 
 	"go.breu.io/quantm/internal/db"
 	"go.breu.io/quantm/internal/events"
+	"go.breu.io/quantm/internal/events/cloudevents"
 	eventsv1 "go.breu.io/quantm/internal/proto/ctrlplane/events/v1"
 )
 
-const (
-	statement__events__persist = `
-INSERT INTO %s (
-	version,
-	id,
-	parents,
-	hook,
-	scope,
-	action,
-	source,
-	subject_id,
-	subject_name,
-	user_id,
-	team_id,
-	org_id,
-	timestamp
-) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-`
-)
-
 // Persist persists an event to clickhouse, routing it to the appropriate activity handler based on the
 // event's associated hook.  It's a workflow-scoped function, mandating execution immediately post-event creation.
 func Persist[H events.Hook, P events.Payload](ctx workflow.Context, event *events.Event[H, P]) error {
@@ -50,64 +31,53 @@ func Persist[H events.Hook, P events.Payload](ctx workflow.Context, event *event
 	return future.Get(ctx, nil)
 }
 
-// PersistRepoEvent persists a repo event to the database.
+// PersistRepoEvent persists a repo event to the database. The event is written in a batch by the batch writer.
 func PersistRepoEvent(ctx context.Context, flat events.Flat[eventsv1.RepoHook]) error {
+	return persist(ctx, flat)
+}
+
+// PersistChatEvent persists a chat event to the database. The event is written in a batch by the batch writer.
+func PersistChatEvent(ctx context.Context, flat events.Flat[eventsv1.ChatHook]) error {
+	return persist(ctx, flat)
+}
+
+// persist hands the flat event to the batch writer, routed to the events table of the org.
+func persist[H events.Hook](ctx context.Context, flat events.Flat[H]) error {
 	slug, err := db.Queries().GetOrgSlugByID(ctx, flat.OrgID)
 	if err != nil {
 		return nil
 	}
 
-	table := table_name("events", slug)
-	stmt := fmt.Sprintf(statement__events__persist, table)
+	record := NewRecord(flat)
 
-	return Get().
-		Connection().
-		Exec(
-			ctx,
-			stmt,
-			flat.Version,
-			flat.ID,
-			flat.Parents,
-			flat.Hook.Number(),
-			flat.Scope,
-			flat.Action,
-			flat.Source,
-			flat.SubjectID,
-			flat.SubjectName,
-			flat.UserID,
-			flat.TeamID,
-			flat.OrgID,
-			flat.Timestamp,
-		)
-}
+	if err := BatchWriter().Add(ctx, table_name("events", slug), record); err != nil {
+		return err
+	}
 
-// PersistChatEvent persists a chat event to the database.
-func PersistChatEvent(ctx context.Context, flat events.Flat[eventsv1.ChatHook]) error {
-	slug, err := db.Queries().GetOrgSlugByID(ctx, flat.OrgID)
-	if err != nil {
-		return nil
+	// the export is handed to the exporter, so that a slow or failing sink neither delays nor fails the persist.
+	if err := Exporter().Add(ctx, flat.OrgID, cloudevents.FromFlat(flat)); err != nil {
+		slog.Warn("pulse: unable to export event", "event_id", flat.ID.String(), "error", err.Error())
 	}
 
-	table := table_name("events", slug)
-	stmt := fmt.Sprintf(statement__events__persist, table)
+	return nil
+}
 
-	return Get().
-		Connection().
-		Exec(
-			ctx,
-			stmt,
-			flat.Version,
-			flat.ID,
-			flat.Parents,
-			flat.Hook.Number(),
-			flat.Scope,
-			flat.Action,
-			flat.Source,
-			flat.SubjectID,
-			flat.SubjectName,
-			flat.UserID,
-			flat.TeamID,
-			flat.OrgID,
-			flat.Timestamp,
-		)
+// NewRecord converts the flat event to the record written to the events table.
+func NewRecord[H events.Hook](flat events.Flat[H]) Record {
+	return Record{
+		Version:     flat.Version,
+		ID:          flat.ID,
+		Parents:     flat.Parents,
+		Hook:        int32(flat.Hook),
+		Scope:       flat.Scope,
+		Action:      flat.Action,
+		Source:      flat.Source,
+		SubjectID:   flat.SubjectID,
+		SubjectName: flat.SubjectName,
+		UserID:      flat.UserID,
+		TeamID:      flat.TeamID,
+		OrgID:       flat.OrgID,
+		Timestamp:   flat.Timestamp,
+		Payload:     flat.Payload,
+	}
 }
@@ -0,0 +1,50 @@
+package pulse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"go.breu.io/quantm/internal/events"
+	eventsv1 "go.breu.io/quantm/internal/proto/ctrlplane/events/v1"
+)
+
+func TestNewRecord(t *testing.T) {
+	t.Parallel()
+
+	flat := events.Flat[eventsv1.RepoHook]{
+		Version:     events.EventVersionDefault,
+		ID:          uuid.New(),
+		Parents:     []uuid.UUID{uuid.New()},
+		Hook:        eventsv1.RepoHook_REPO_HOOK_GITHUB,
+		Scope:       events.ScopePush,
+		Action:      events.ActionCreated,
+		Source:      "https://github.com/breuhq/quantm",
+		SubjectID:   uuid.New(),
+		SubjectName: events.SubjectNameRepos,
+		UserID:      uuid.New(),
+		TeamID:      uuid.New(),
+		OrgID:       uuid.New(),
+		Timestamp:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Payload:     `{"ref":"refs/heads/main"}`,
+	}
+
+	assert.Equal(t, Record{
+		Version:     flat.Version,
+		ID:          flat.ID,
+		Parents:     flat.Parents,
+		Hook:        int32(eventsv1.RepoHook_REPO_HOOK_GITHUB),
+		Scope:       flat.Scope,
+		Action:      flat.Action,
+		Source:      flat.Source,
+		SubjectID:   flat.SubjectID,
+		SubjectName: flat.SubjectName,
+		UserID:      flat.UserID,
+		TeamID:      flat.TeamID,
+		OrgID:       flat.OrgID,
+		Timestamp:   flat.Timestamp,
+		Payload:     flat.Payload,
+	}, NewRecord(flat))
+}
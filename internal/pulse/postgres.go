@@ -0,0 +1,229 @@
+package pulse
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"go.breu.io/quantm/internal/db"
+	"go.breu.io/quantm/internal/events"
+)
+
+type (
+	// PostgresSink stores the events in the events table of postgres, partitioned by org. The partition of each org is
+	// named after the table of the org, e.g. events_acme, and is created when the org is migrated. The retention is
+	// applied by deleting the expired events when the org is migrated, and then every ExpireInterval by the batch writer.
+	PostgresSink struct{}
+)
+
+const (
+	statement__pg__partition = `CREATE TABLE IF NOT EXISTS %s PARTITION OF events FOR VALUES IN ('%s')`
+
+	statement__pg__retain = `DELETE FROM %s WHERE timestamp < now() - make_interval(days => $1)`
+
+	statement__pg__insert = `
+INSERT INTO events (
+	version,
+	id,
+	parents,
+	hook,
+	scope,
+	action,
+	source,
+	subject_id,
+	subject_name,
+	user_id,
+	team_id,
+	org_id,
+	timestamp,
+	payload
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+ON CONFLICT (org_id, id) DO NOTHING
+`
+
+	statement__pg__select = `
+SELECT
+	version,
+	id,
+	parents,
+	hook,
+	scope,
+	action,
+	source,
+	subject_id,
+	subject_name,
+	user_id,
+	team_id,
+	org_id,
+	timestamp,
+	payload::text
+FROM %s
+WHERE %s
+ORDER BY timestamp, id
+`
+)
+
+// Migrate creates the partition of the org, and applies the retention.
+func (s *PostgresSink) Migrate(ctx context.Context, org uuid.UUID, table string, retention int32) error {
+	ident := pgx.Identifier{table}.Sanitize()
+
+	if _, err := db.Get().Pool().Exec(ctx, fmt.Sprintf(statement__pg__partition, ident, org.String())); err != nil {
+		return err
+	}
+
+	return s.Retain(ctx, table, retention)
+}
+
+// Retain deletes the events of the partition older than the days right away. 0 keeps the events forever.
+func (s *PostgresSink) Retain(ctx context.Context, table string, days int32) error {
+	return s.Expire(ctx, table, days)
+}
+
+// Expire deletes the events of the partition older than the days. 0 keeps the events forever.
+func (s *PostgresSink) Expire(ctx context.Context, table string, days int32) error {
+	if days <= 0 {
+		return nil
+	}
+
+	_, err := db.Get().Pool().Exec(ctx, fmt.Sprintf(statement__pg__retain, pgx.Identifier{table}.Sanitize()), days)
+
+	return err
+}
+
+// Insert writes the records in a single round trip. The records already written are skipped.
+func (s *PostgresSink) Insert(ctx context.Context, _ string, records []Record) error {
+	batch := &pgx.Batch{}
+
+	for _, r := range records {
+		parents := r.Parents
+		if parents == nil {
+			parents = make([]uuid.UUID, 0)
+		}
+
+		payload := r.Payload
+		if payload == "" {
+			payload = "{}"
+		}
+
+		batch.Queue(
+			statement__pg__insert,
+			r.Version.String(),
+			r.ID,
+			parents,
+			r.Hook,
+			r.Scope.String(),
+			r.Action.String(),
+			r.Source,
+			r.SubjectID,
+			r.SubjectName,
+			r.UserID,
+			r.TeamID,
+			r.OrgID,
+			r.Timestamp,
+			payload,
+		)
+	}
+
+	return db.Get().Pool().SendBatch(ctx, batch).Close()
+}
+
+// Query reads the records of the org from its partition matching the filter, ordered by timestamp.
+func (s *PostgresSink) Query(ctx context.Context, table string, org uuid.UUID, filter *Filter) ([]Record, error) {
+	where, args := filter.where_pg(org)
+	stmt := fmt.Sprintf(statement__pg__select, pgx.Identifier{table}.Sanitize(), where)
+
+	rows, err := db.Get().Pool().Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	records := make([]Record, 0)
+
+	for rows.Next() {
+		var (
+			record                 Record
+			version, scope, action string
+		)
+
+		err := rows.Scan(
+			&version,
+			&record.ID,
+			&record.Parents,
+			&record.Hook,
+			&scope,
+			&action,
+			&record.Source,
+			&record.SubjectID,
+			&record.SubjectName,
+			&record.UserID,
+			&record.TeamID,
+			&record.OrgID,
+			&record.Timestamp,
+			&record.Payload,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		record.Version = events.EventVersion(version)
+		record.Scope = events.Scope(scope)
+		record.Action = events.Action(action)
+		record.Timestamp = record.Timestamp.UTC()
+
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+// where_pg builds the postgres where clause along with its arguments.
+func (f *Filter) where_pg(org uuid.UUID) (string, []any) {
+	clauses := []string{"org_id = $1"}
+	args := []any{org}
+
+	add := func(clause string, arg any) {
+		args = append(args, arg)
+		clauses = append(clauses, fmt.Sprintf(clause, len(args)))
+	}
+
+	if len(f.IDs) > 0 {
+		add("id = ANY($%d)", f.IDs)
+	}
+
+	if len(f.Parents) > 0 {
+		add("parents && $%d", f.Parents)
+	}
+
+	if f.SubjectID != uuid.Nil {
+		add("subject_id = $%d", f.SubjectID)
+	}
+
+	if f.TeamID != uuid.Nil {
+		add("team_id = $%d", f.TeamID)
+	}
+
+	if len(f.Scopes) > 0 {
+		scopes := make([]string, 0, len(f.Scopes))
+		for _, scope := range f.Scopes {
+			scopes = append(scopes, scope.String())
+		}
+
+		add("scope = ANY($%d)", scopes)
+	}
+
+	if !f.Start.IsZero() {
+		add("timestamp >= $%d", f.Start)
+	}
+
+	if !f.End.IsZero() {
+		add("timestamp < $%d", f.End)
+	}
+
+	return strings.Join(clauses, " AND "), args
+}
@@ -0,0 +1,82 @@
+package pulse
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"go.breu.io/quantm/internal/db"
+	"go.breu.io/quantm/internal/events"
+)
+
+type (
+	// Record is an event read back from the events table of an org. The table holds both the repo and the chat events,
+	// so the hook is kept as its number.
+	Record struct {
+		Version     events.EventVersion `json:"version"`
+		ID          uuid.UUID           `json:"id"`
+		Parents     []uuid.UUID         `json:"parents"`
+		Hook        int32               `json:"hook"`
+		Scope       events.Scope        `json:"scope"`
+		Action      events.Action       `json:"action"`
+		Source      string              `json:"source"`
+		SubjectID   uuid.UUID           `json:"subject_id"`
+		SubjectName string              `json:"subject_name"`
+		UserID      uuid.UUID           `json:"user_id"`
+		TeamID      uuid.UUID           `json:"team_id"`
+		OrgID       uuid.UUID           `json:"org_id"`
+		Timestamp   time.Time           `json:"timestamp"`
+		Payload     string              `json:"payload"`
+	}
+
+	// Filter narrows down the events read from the events table. Zero values are ignored.
+	Filter struct {
+		IDs       []uuid.UUID    // IDs are the IDs of the events.
+		Parents   []uuid.UUID    // Parents are the IDs of the parents, matching events with any of them as parent.
+		SubjectID uuid.UUID      // SubjectID is the ID of the subject, e.g. the repo.
+		TeamID    uuid.UUID      // TeamID is the ID of the team.
+		Scopes    []events.Scope // Scopes are the scopes of the events.
+		Start     time.Time      // Start is the start of the time range, inclusive.
+		End       time.Time      // End is the end of the time range, exclusive.
+	}
+)
+
+// Query reads the events of the org matching the filter from the sink, ordered by timestamp. The payloads are upcast
+// to the current version.
+func Query(ctx context.Context, org uuid.UUID, filter *Filter) ([]Record, error) {
+	table, err := EventsTable(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+
+	sink, err := Sink()
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := sink.Query(ctx, table, org, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// the events written before a payload change are upcast to the current shape.
+	for i := range records {
+		records[i].Version, records[i].Payload, err = events.UpcastPayload(records[i].Scope, records[i].Version, records[i].Payload)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return records, nil
+}
+
+// EventsTable returns the name of the events table of the org.
+func EventsTable(ctx context.Context, org uuid.UUID) (string, error) {
+	slug, err := db.Queries().GetOrgSlugByID(ctx, org)
+	if err != nil {
+		return "", err
+	}
+
+	return table_name("events", slug), nil
+}
@@ -0,0 +1,88 @@
+package pulse
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"go.breu.io/quantm/internal/pulse/config"
+)
+
+type (
+	// EventSink is the backend the events of the orgs are stored in. The events of each org are kept in their own table,
+	// named after the slug of the org, e.g. events_acme.
+	EventSink interface {
+		// Migrate creates the table of the org, or migrates it to the latest schema, and applies the retention.
+		Migrate(ctx context.Context, org uuid.UUID, table string, retention int32) error
+
+		// Retain sets the number of days the events of the table are kept for. 0 keeps them forever.
+		Retain(ctx context.Context, table string, days int32) error
+
+		// Insert writes the records to the table. The records may be written again, e.g. on a replay of the write ahead
+		// log.
+		Insert(ctx context.Context, table string, records []Record) error
+
+		// Query reads the records of the org from the table matching the filter, ordered by timestamp.
+		Query(ctx context.Context, table string, org uuid.UUID, filter *Filter) ([]Record, error)
+	}
+
+	// Expirer is implemented by the sinks that don't expire the events on their own, unlike clickhouse with the TTL of the
+	// table. The batch writer expires the events of every org on a schedule, see ExpireAll.
+	Expirer interface {
+		// Expire deletes the events of the table older than the days. 0 keeps the events forever.
+		Expire(ctx context.Context, table string, days int32) error
+	}
+)
+
+const (
+	SinkClickhouse = config.SinkClickhouse
+	SinkPostgres   = config.SinkPostgres
+	SinkMemory     = config.SinkMemory
+)
+
+var (
+	_sink     EventSink
+	sinkmutex sync.Mutex
+)
+
+// Sink returns the event sink chosen in the configuration. Returns an error if the configured sink is unknown, which
+// config.Config.Validate rejects beforehand.
+func Sink() (EventSink, error) {
+	sinkmutex.Lock()
+	defer sinkmutex.Unlock()
+
+	if _sink == nil {
+		sink, err := NewSink(Get().Sink)
+		if err != nil {
+			return nil, err
+		}
+
+		_sink = sink
+	}
+
+	return _sink, nil
+}
+
+// SetSink overrides the event sink, e.g. with the memory sink in tests.
+func SetSink(sink EventSink) {
+	sinkmutex.Lock()
+	defer sinkmutex.Unlock()
+
+	_sink = sink
+}
+
+// NewSink creates the event sink of the kind.
+func NewSink(kind string) (EventSink, error) {
+	switch kind {
+	case SinkClickhouse:
+		return &ClickhouseSink{}, nil
+	case SinkPostgres:
+		return &PostgresSink{}, nil
+	case SinkMemory:
+		return NewMemorySink(), nil
+	default:
+		return nil, fmt.Errorf("pulse: unknown sink %s", kind)
+	}
+}
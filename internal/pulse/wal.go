@@ -0,0 +1,165 @@
+package pulse
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+type (
+	// wal is the append-only write ahead log, where the events are spilled when they can't be written to the event sink,
+	// or sent to the export sinks. Each line is an entry with the key, i.e. the table or the org, and the event, encoded
+	// as json.
+	wal[T any] struct {
+		path  string
+		mutex sync.Mutex
+	}
+
+	// entry is a line of the write ahead log.
+	entry[T any] struct {
+		Table  string `json:"table"`
+		Record T      `json:"record"`
+	}
+)
+
+const (
+	wal_file        = "pulse.wal"  // wal_file is the log of the batch writer.
+	export_wal_file = "export.wal" // export_wal_file is the log of the exporter.
+)
+
+// newwal creates the write ahead log with the file name in the directory, defaulting to the temp directory.
+func newwal[T any](dir, name string) (*wal[T], error) {
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "pulse")
+	}
+
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+
+	return &wal[T]{path: filepath.Join(dir, name)}, nil
+}
+
+// append appends the records of the table to the log, syncing the file before returning.
+func (w *wal[T]) append(table string, records []T) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return w.write(table, records)
+}
+
+// replay reads the log and hands the records of each table to fn. The tables that fail are kept in the log, the rest are
+// removed from it.
+func (w *wal[T]) replay(fn func(table string, records []T) error) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	tables, order, err := w.read()
+	if err != nil || len(order) == 0 {
+		return err
+	}
+
+	failed := make(map[string][]T)
+
+	var errs []error
+
+	for _, table := range order {
+		if err := fn(table, tables[table]); err != nil {
+			failed[table] = tables[table]
+			errs = append(errs, err)
+		}
+	}
+
+	if err := os.Remove(w.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	for _, table := range order {
+		if records, ok := failed[table]; ok {
+			if err := w.write(table, records); err != nil {
+				return err
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// size returns the size of the log in bytes.
+func (w *wal[T]) size() int64 {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return 0
+	}
+
+	return info.Size()
+}
+
+// read reads the entries of the log, grouped by table in the order they were first seen.
+func (w *wal[T]) read() (map[string][]T, []string, error) {
+	file, err := os.Open(w.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, nil
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer file.Close()
+
+	tables := make(map[string][]T)
+	order := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		e := entry[T]{}
+
+		// a torn write at the end of the log, e.g. on a crash, is skipped.
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+
+		if _, ok := tables[e.Table]; !ok {
+			order = append(order, e.Table)
+		}
+
+		tables[e.Table] = append(tables[e.Table], e.Record)
+	}
+
+	return tables, order, scanner.Err()
+}
+
+// write appends the records of the table to the log.
+func (w *wal[T]) write(table string, records []T) error {
+	file, err := os.OpenFile(w.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+
+	buf := bufio.NewWriter(file)
+	encoder := json.NewEncoder(buf)
+
+	for _, record := range records {
+		if err := encoder.Encode(entry[T]{Table: table, Record: record}); err != nil {
+			_ = file.Close()
+			return err
+		}
+	}
+
+	if err := buf.Flush(); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,258 @@
+package pulse
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type (
+	// Writer buffers the events on the worker and writes them to the event sink in batches, flushing when the batch size
+	// is reached or the flush interval has elapsed, whichever comes first.
+	//
+	// Add blocks until the batch holding the event is flushed, so that the persist activity only completes once the event
+	// is durable. If the batch can't be written to the sink, it is spilled to the local write ahead log, and the
+	// activity still completes. The log is replayed after the next successful write, and on every flush tick while it is
+	// not empty, so that it drains once the sink is back even if no events are written.
+	//
+	// The writer also expires the events of the sinks that don't expire them on their own, every expiry.
+	Writer struct {
+		size     int
+		interval time.Duration
+		expiry   time.Duration
+		wal      *wal[Record]
+		insert   func(ctx context.Context, table string, records []Record) error
+		expire   func(ctx context.Context) error
+
+		mutex   sync.Mutex
+		pending map[string][]*pending // pending holds the buffered events per table.
+		count   int
+		running bool
+		kick    chan struct{}
+		stop    chan struct{}
+		done    chan struct{}
+	}
+
+	// pending is a buffered event waiting for its batch to be flushed.
+	pending struct {
+		record Record
+		result chan error
+	}
+)
+
+var (
+	// ExpireInterval is the interval the batch writer expires the events at.
+	ExpireInterval = time.Hour
+)
+
+var (
+	_w     *Writer
+	wonce  sync.Once
+	werror error
+)
+
+// BatchWriter returns the singleton batch writer, configured with the batch size, the flush interval and the write
+// ahead log directory of the pulse configuration.
+func BatchWriter() *Writer {
+	wonce.Do(func() {
+		var log *wal[Record]
+
+		log, werror = newwal[Record](Get().WALDir, wal_file)
+
+		_w = &Writer{
+			size:     Get().BatchSize,
+			interval: Get().FlushInterval,
+			expiry:   ExpireInterval,
+			wal:      log,
+			insert:   insert,
+			expire:   ExpireAll,
+			pending:  make(map[string][]*pending),
+		}
+	})
+
+	return _w
+}
+
+// Start starts the flush loop, after replaying the write ahead log left over from a previous run.
+func (w *Writer) Start(ctx context.Context) error {
+	if werror != nil {
+		return werror
+	}
+
+	w.mutex.Lock()
+	w.running = true
+	w.kick = make(chan struct{}, 1)
+	w.stop = make(chan struct{})
+	w.done = make(chan struct{})
+	w.mutex.Unlock()
+
+	w.replay(ctx)
+
+	go w.loop()
+
+	slog.Info("pulse: batch writer started", "size", w.size, "interval", w.interval)
+
+	return nil
+}
+
+// Stop flushes the buffered events and stops the flush loop.
+func (w *Writer) Stop(ctx context.Context) error {
+	w.mutex.Lock()
+	if !w.running {
+		w.mutex.Unlock()
+		return nil
+	}
+
+	w.running = false
+	w.mutex.Unlock()
+
+	close(w.stop)
+
+	select {
+	case <-w.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	slog.Info("pulse: batch writer stopped")
+
+	return nil
+}
+
+// Add buffers the event of the table, and waits until its batch is flushed. If the writer is not running, the event
+// is written right away.
+func (w *Writer) Add(ctx context.Context, table string, record Record) error {
+	w.mutex.Lock()
+
+	if !w.running {
+		w.mutex.Unlock()
+
+		return w.insert(ctx, table, []Record{record})
+	}
+
+	p := &pending{record: record, result: make(chan error, 1)}
+	w.pending[table] = append(w.pending[table], p)
+	w.count++
+
+	if w.count >= w.size {
+		select {
+		case w.kick <- struct{}{}:
+		default:
+		}
+	}
+
+	w.mutex.Unlock()
+
+	select {
+	case err := <-p.result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// loop flushes the buffered events on every tick, or when the batch size is reached. The write ahead log is replayed on
+// every tick, if not already replayed by the flush. The events are expired every expiry, if set.
+func (w *Writer) loop() {
+	ticker := time.NewTicker(w.interval)
+
+	defer ticker.Stop()
+	defer close(w.done)
+
+	var expiry <-chan time.Time
+
+	if w.expiry > 0 {
+		expirer := time.NewTicker(w.expiry)
+		defer expirer.Stop()
+
+		expiry = expirer.C
+	}
+
+	for {
+		select {
+		case <-ticker.C:
+			w.flush()
+			w.replay(context.Background())
+		case <-expiry:
+			if err := w.expire(context.Background()); err != nil {
+				slog.Warn("pulse: unable to expire events", "error", err.Error())
+			}
+		case <-w.kick:
+			w.flush()
+		case <-w.stop:
+			w.flush()
+			return
+		}
+	}
+}
+
+// flush writes the buffered events table by table. A table that fails is spilled to the write ahead log. Once a table
+// is written successfully, the write ahead log is replayed.
+func (w *Writer) flush() {
+	w.mutex.Lock()
+	batches := w.pending
+	w.pending = make(map[string][]*pending)
+	w.count = 0
+	w.mutex.Unlock()
+
+	if len(batches) == 0 {
+		return
+	}
+
+	ctx := context.Background()
+	healthy := false
+
+	for table, batch := range batches {
+		records := make([]Record, 0, len(batch))
+		for _, p := range batch {
+			records = append(records, p.record)
+		}
+
+		err := w.insert(ctx, table, records)
+		if err == nil {
+			healthy = true
+		} else {
+			slog.Warn("pulse: unable to write batch, spilling to wal", "table", table, "count", len(records), "error", err.Error())
+
+			err = w.wal.append(table, records)
+			if err != nil {
+				slog.Error("pulse: unable to spill batch to wal", "table", table, "count", len(records), "error", err.Error())
+			}
+		}
+
+		for _, p := range batch {
+			p.result <- err
+		}
+	}
+
+	if healthy && w.wal.size() > 0 {
+		w.replay(ctx)
+	}
+}
+
+// replay writes the events in the write ahead log to the event sink.
+func (w *Writer) replay(ctx context.Context) {
+	if w.wal.size() == 0 {
+		return
+	}
+
+	slog.Info("pulse: replaying wal ...", "bytes", w.wal.size())
+
+	err := w.wal.replay(func(table string, records []Record) error {
+		return w.insert(ctx, table, records)
+	})
+	if err != nil {
+		slog.Warn("pulse: unable to replay wal", "error", err.Error())
+	}
+}
+
+// insert writes the records to the table of the event sink.
+func insert(ctx context.Context, table string, records []Record) error {
+	sink, err := Sink()
+	if err != nil {
+		return err
+	}
+
+	return sink.Insert(ctx, table, records)
+}
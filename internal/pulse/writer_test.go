@@ -0,0 +1,201 @@
+package pulse
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	// sink is a fake clickhouse, failing the inserts while down.
+	sink struct {
+		mutex   sync.Mutex
+		down    bool
+		batches int
+		rows    map[string][]Record
+	}
+)
+
+func (s *sink) insert(_ context.Context, table string, records []Record) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.down {
+		return errors.New("connection refused")
+	}
+
+	s.batches++
+	s.rows[table] = append(s.rows[table], records...)
+
+	return nil
+}
+
+func (s *sink) set(down bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.down = down
+}
+
+func (s *sink) count(table string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return len(s.rows[table])
+}
+
+func writer(t *testing.T, s *sink, size int) *Writer {
+	t.Helper()
+
+	log, err := newwal[Record](t.TempDir(), wal_file)
+	require.NoError(t, err)
+
+	w := &Writer{size: size, interval: time.Hour, wal: log, insert: s.insert, pending: make(map[string][]*pending)}
+	require.NoError(t, w.Start(context.Background()))
+
+	t.Cleanup(func() { _ = w.Stop(context.Background()) })
+
+	return w
+}
+
+func add(t *testing.T, w *Writer, table string, n int) {
+	t.Helper()
+
+	wg := sync.WaitGroup{}
+
+	for range n {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, w.Add(context.Background(), table, Record{ID: uuid.New(), Timestamp: time.Now()}))
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestWriter_FlushOnSize(t *testing.T) {
+	t.Parallel()
+
+	s := &sink{rows: make(map[string][]Record)}
+	w := writer(t, s, 4)
+
+	add(t, w, "events_a", 4)
+
+	assert.Equal(t, 4, s.count("events_a"))
+	assert.Equal(t, 1, s.batches)
+}
+
+func TestWriter_SpillAndReplay(t *testing.T) {
+	t.Parallel()
+
+	s := &sink{rows: make(map[string][]Record), down: true}
+	w := writer(t, s, 3)
+
+	add(t, w, "events_a", 3)
+
+	assert.Equal(t, 0, s.count("events_a"))
+	assert.Positive(t, w.wal.size())
+
+	s.set(false)
+	add(t, w, "events_b", 3)
+
+	assert.Equal(t, 3, s.count("events_a"))
+	assert.Equal(t, 3, s.count("events_b"))
+	assert.Zero(t, w.wal.size())
+}
+
+func TestWriter_ReplayOnTick(t *testing.T) {
+	t.Parallel()
+
+	s := &sink{rows: make(map[string][]Record), down: true}
+
+	log, err := newwal[Record](t.TempDir(), wal_file)
+	require.NoError(t, err)
+
+	w := &Writer{size: 3, interval: 10 * time.Millisecond, wal: log, insert: s.insert, pending: make(map[string][]*pending)}
+	require.NoError(t, w.Start(context.Background()))
+
+	t.Cleanup(func() { _ = w.Stop(context.Background()) })
+
+	add(t, w, "events_a", 3)
+	require.Equal(t, 0, s.count("events_a"))
+
+	// the log drains on a tick once the sink is back, without waiting for another write.
+	s.set(false)
+
+	assert.Eventually(t, func() bool { return s.count("events_a") == 3 }, time.Second, time.Millisecond)
+}
+
+func TestWriter_FlushOnStop(t *testing.T) {
+	t.Parallel()
+
+	s := &sink{rows: make(map[string][]Record)}
+	w := writer(t, s, 100)
+
+	done := make(chan struct{})
+
+	go func() {
+		add(t, w, "events_a", 2)
+		close(done)
+	}()
+
+	// wait for both events to be buffered before stopping.
+	require.Eventually(t, func() bool {
+		w.mutex.Lock()
+		defer w.mutex.Unlock()
+
+		return w.count == 2
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, w.Stop(context.Background()))
+	<-done
+
+	assert.Equal(t, 2, s.count("events_a"))
+}
+
+func TestWriter_Expire(t *testing.T) {
+	t.Parallel()
+
+	s := &sink{rows: make(map[string][]Record)}
+
+	log, err := newwal[Record](t.TempDir(), wal_file)
+	require.NoError(t, err)
+
+	expired := make(chan struct{}, 1)
+	expire := func(context.Context) error {
+		select {
+		case expired <- struct{}{}:
+		default:
+		}
+
+		return nil
+	}
+
+	w := &Writer{
+		size:     3,
+		interval: time.Hour,
+		expiry:   10 * time.Millisecond,
+		wal:      log,
+		insert:   s.insert,
+		expire:   expire,
+		pending:  make(map[string][]*pending),
+	}
+	require.NoError(t, w.Start(context.Background()))
+
+	t.Cleanup(func() { _ = w.Stop(context.Background()) })
+
+	// the events are expired on schedule, without waiting for a migration.
+	select {
+	case <-expired:
+	case <-time.After(time.Second):
+		t.Fatal("events not expired")
+	}
+}